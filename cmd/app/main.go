@@ -10,11 +10,18 @@ import (
 	"time"
 
 	_ "wishlist/docs" // Import swagger docs
+	"wishlist/internal/api"
 	"wishlist/internal/api/handlers"
 	"wishlist/internal/api/middleware"
+	"wishlist/internal/auth"
+	"wishlist/internal/blob"
 	"wishlist/internal/config"
 	"wishlist/internal/domain"
+	"wishlist/internal/linkpreview"
+	"wishlist/internal/mail"
 	"wishlist/internal/observability"
+	"wishlist/internal/oidc"
+	"wishlist/internal/ratelimit"
 	"wishlist/internal/repository"
 	"wishlist/internal/service"
 
@@ -23,7 +30,6 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -43,7 +49,7 @@ func initDB() (*gorm.DB, error) {
 	}
 
 	// Auto-migrate the schema
-	if err := db.AutoMigrate(&domain.User{}, &domain.WishList{}, &domain.WishItem{}); err != nil {
+	if err := db.AutoMigrate(domain.Models()...); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
@@ -62,39 +68,151 @@ func main() {
 	// Initialize metrics
 	metrics := observability.NewMetrics()
 
+	// Initialize configuration
+	cfg := config.New()
+
 	// Initialize database
 	db, err := initDB()
 	if err != nil {
 		logger.Fatal("Failed to initialize database", zap.Error(err))
 	}
 
+	// Initialize token signing
+	jwtManager, err := auth.NewJWTManagerFromConfig(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize JWT signing", zap.Error(err))
+	}
+
+	// Initialize external identity providers
+	oidcProviders, err := oidc.ProvidersFromConfig(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize identity providers", zap.Error(err))
+	}
+
+	// Initialize email delivery
+	mailer, err := mail.NewFromConfig(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize mailer", zap.Error(err))
+	}
+
+	blobStore, err := blob.NewFromConfig(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize blob storage", zap.Error(err))
+	}
+
+	// Initialize rate limiting
+	rateLimitStore, err := ratelimit.StoreFromConfig(cfg, db)
+	if err != nil {
+		logger.Fatal("Failed to initialize rate limiting", zap.Error(err))
+	}
+	apiPolicy, err := ratelimit.ParsePolicy("api", cfg.RateLimitAPI, config.ParseInt(cfg.RateLimitAPIBurst, 0))
+	if err != nil {
+		logger.Fatal("Invalid API rate limit", zap.Error(err))
+	}
+	authPolicy, err := ratelimit.ParsePolicy("auth", cfg.RateLimitAuth, config.ParseInt(cfg.RateLimitAuthBurst, 0))
+	if err != nil {
+		logger.Fatal("Invalid auth rate limit", zap.Error(err))
+	}
+	rateLimits := api.RateLimits{
+		API:  middleware.RateLimit(ratelimit.NewLimiter(rateLimitStore, apiPolicy)),
+		Auth: middleware.RateLimit(ratelimit.NewLimiter(rateLimitStore, authPolicy)),
+	}
+
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	wishListRepo := repository.NewWishListRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
+	collaboratorRepo := repository.NewCollaboratorRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	personalTokenRepo := repository.NewPersonalAccessTokenRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	accountRepo := repository.NewAccountRepository(db)
+	adminRepo := repository.NewAdminRepository(db)
+	priceRepo := repository.NewPriceRepository(db)
+	itemImageRepo := repository.NewItemImageRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	calendarFeedRepo := repository.NewCalendarFeedRepository(db)
+	loginThrottleRepo := repository.NewLoginThrottleRepository(db)
+	securityEventRepo := repository.NewSecurityEventRepository(db)
 
 	// Initialize services
-	userService := service.NewUserService(userRepo)
-	wishListService := service.NewWishListService(wishListRepo)
-
-	// Создаем конфигурацию
-	cfg := &config.Config{
-		JWTSecret: os.Getenv("JWT_SECRET"),
+	tokenService := service.NewTokenService(tokenRepo, jwtManager,
+		config.ParseDuration(cfg.RefreshTokenExpiry, service.DefaultRefreshTokenTTL))
+	reauthenticator := service.NewReauthenticator(identityRepo, tokenService)
+	userService := service.NewUserService(userRepo, reauthenticator)
+	verificationPolicy := service.NewVerificationPolicy(userRepo, cfg.EmailVerificationRequiredFor)
+	accessPolicy := service.NewAccessPolicy(collaboratorRepo, verificationPolicy)
+	wishListService := service.NewWishListService(wishListRepo, accessPolicy)
+	reservationService := service.NewReservationService(reservationRepo, wishListRepo, accessPolicy)
+	collaborationService := service.NewCollaborationService(collaboratorRepo, invitationRepo, wishListRepo, userRepo, accessPolicy)
+	passwordResetService := service.NewPasswordResetService(passwordResetRepo, userRepo, tokenService, personalTokenRepo,
+		mailer, cfg.AppURL, config.ParseDuration(cfg.PasswordResetExpiry, service.DefaultPasswordResetTTL))
+	verificationService := service.NewEmailVerificationService(emailVerificationRepo, userRepo, mailer, cfg.AppURL,
+		config.ParseDuration(cfg.EmailVerificationExpiry, service.DefaultEmailVerificationTTL),
+		config.ParseDuration(cfg.EmailVerificationResendInterval, service.DefaultVerificationResendInterval))
+	lockoutPolicy := service.DefaultLockoutPolicy()
+	lockoutPolicy.LockoutThreshold = config.ParseInt(cfg.LoginLockoutThreshold, lockoutPolicy.LockoutThreshold)
+	lockoutPolicy.LockoutDuration = config.ParseDuration(cfg.LoginLockoutDuration, lockoutPolicy.LockoutDuration)
+	loginGuard := service.NewLoginGuard(loginThrottleRepo, securityEventRepo, userRepo, mailer, cfg.AppURL, lockoutPolicy)
+	mfaService := service.NewMFAService(userRepo, recoveryCodeRepo, tokenService, jwtManager, loginGuard, reauthenticator,
+		cfg.TOTPIssuer, config.ParseDuration(cfg.MFAChallengeDuration, service.DefaultMFAChallengeTTL))
+	personalTokenService := service.NewPersonalAccessTokenService(personalTokenRepo, wishListRepo, accessPolicy)
+	oidcService := service.NewOIDCService(oidcProviders, identityRepo, userRepo, tokenService, personalTokenRepo)
+	accountService := service.NewAccountService(accountRepo, userRepo, reauthenticator, tokenService, personalTokenRepo,
+		mailer, config.ParseDuration(cfg.AccountDeletionGracePeriod, service.DefaultDeletionGracePeriod))
+	adminService := service.NewAdminService(adminRepo, userRepo, tokenService, personalTokenRepo, passwordResetService,
+		securityEventRepo)
+	if err := adminService.EnsureAdmins(cfg.AdminEmails); err != nil {
+		logger.Fatal("Failed to set up administrators", zap.Error(err))
 	}
+	previewer := linkpreview.NewPreviewer(linkpreview.OptionsFromConfig(cfg))
+	priceCheckPolicy := service.DefaultPriceCheckPolicy()
+	priceCheckPolicy.Interval = config.ParseDuration(cfg.PriceCheckInterval, priceCheckPolicy.Interval)
+	priceCheckPolicy.BatchSize = config.ParseInt(cfg.PriceCheckBatchSize, priceCheckPolicy.BatchSize)
+	priceTracker := service.NewPriceTracker(priceRepo, wishListRepo, userRepo, accessPolicy, previewer, mailer, cfg.AppURL,
+		priceCheckPolicy)
+	itemImageService := service.NewItemImageService(itemImageRepo, wishListRepo, accessPolicy, blobStore)
+	tagService := service.NewTagService(tagRepo, wishListRepo, accessPolicy)
+	eventService := service.NewEventService(wishListRepo,
+		config.ParseDuration(cfg.EventArchiveDelay, service.DefaultEventArchiveDelay))
+	calendarService := service.NewCalendarService(calendarFeedRepo, wishListRepo, userRepo, cfg.AppURL)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(userService, cfg)
+	authHandler := handlers.NewAuthHandler(userService, tokenService, verificationService, mfaService, loginGuard)
 	wishListHandler := handlers.NewWishListHandler(wishListService)
+	reservationHandler := handlers.NewReservationHandler(reservationService)
+	collaborationHandler := handlers.NewCollaborationHandler(collaborationService)
+	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService)
+	verificationHandler := handlers.NewEmailVerificationHandler(verificationService)
+	mfaHandler := handlers.NewMFAHandler(mfaService)
+	personalTokenHandler := handlers.NewPersonalAccessTokenHandler(personalTokenService)
+	oidcHandler := handlers.NewOIDCHandler(oidcService, tokenService, mfaService)
+	sessionHandler := handlers.NewSessionHandler(tokenService)
+	profileHandler := handlers.NewProfileHandler(userService, verificationService, tokenService, personalTokenService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	itemPreviewHandler := handlers.NewItemPreviewHandler(previewer)
+	priceHandler := handlers.NewPriceHandler(priceTracker)
+	itemImageHandler := handlers.NewItemImageHandler(itemImageService,
+		int64(config.ParseInt(cfg.ImageUploadMaxBytes, handlers.DefaultImageUploadMaxBytes)))
+	tagHandler := handlers.NewTagHandler(tagService)
+	eventHandler := handlers.NewEventHandler(eventService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService, cfg.APIURL+"/api/v1/calendar")
+	jwksHandler := handlers.NewJWKSHandler(jwtManager)
 	healthHandler := handlers.NewHealthHandler(db)
 
 	// Initialize router
 	r := gin.Default()
-
-	// Создаем ограничитель запросов
-	limiter := rate.NewLimiter(rate.Limit(10), 30) // 10 requests per second, burst of 30
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Fatal("Invalid trusted proxies", zap.Error(err))
+	}
 
 	// Add middleware
 	r.Use(middleware.CORS())
-	r.Use(middleware.RateLimit(limiter))
 	// Используем логгер напрямую
 	r.Use(func(c *gin.Context) {
 		start := time.Now()
@@ -136,29 +254,99 @@ func main() {
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Auth routes
-	authRoutes := r.Group("/api/v1/auth")
-	{
-		authRoutes.POST("/register", authHandler.Register)
-		authRoutes.POST("/login", authHandler.Login)
-	}
-
-	// Protected routes
-	api := r.Group("/api/v1")
-	api.Use(middleware.Auth(os.Getenv("JWT_SECRET")))
-	{
-		// Wishlist routes
-		wishlists := api.Group("/wishlists")
-		{
-			wishlists.POST("", wishListHandler.Create)
-			wishlists.GET("", wishListHandler.List)
-			wishlists.GET("/:id", wishListHandler.Get)
-			wishlists.PUT("/:id", wishListHandler.Update)
-			wishlists.DELETE("/:id", wishListHandler.Delete)
-			wishlists.POST("/:id/items", wishListHandler.AddItem)
-		}
+	// API routes
+	// Public signing keys for other services
+	r.GET("/.well-known/jwks.json", jwksHandler.Keys)
+
+	authMiddleware := middleware.Auth(jwtManager, tokenService, personalTokenService)
+	optionalAuth := middleware.OptionalAuth(jwtManager, tokenService, personalTokenService)
+	api.RegisterRoutes(r.Group("/api/v1"), authMiddleware, optionalAuth, middleware.RequireAdmin(userService), rateLimits, &api.Handlers{
+		Auth:          authHandler,
+		WishList:      wishListHandler,
+		Reservation:   reservationHandler,
+		Collaboration: collaborationHandler,
+		PasswordReset: passwordResetHandler,
+		Verification:  verificationHandler,
+		MFA:           mfaHandler,
+		AccessTokens:  personalTokenHandler,
+		OIDC:          oidcHandler,
+		Sessions:      sessionHandler,
+		Profile:       profileHandler,
+		Account:       accountHandler,
+		Admin:         adminHandler,
+		ItemPreview:   itemPreviewHandler,
+		Prices:        priceHandler,
+		ItemImages:    itemImageHandler,
+		Tags:          tagHandler,
+		Events:        eventHandler,
+		Calendar:      calendarHandler,
+	})
+
+	// Uploaded files kept on disk are served by the API itself
+	if local, ok := blobStore.(*blob.LocalStore); ok {
+		r.StaticFS(local.PublicPath(), gin.Dir(local.Dir(), false))
 	}
 
+	// Erase accounts whose deletion grace period has ended
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			purged, err := accountService.PurgeDue()
+			if err != nil {
+				logger.Error("Failed to purge deleted accounts", zap.Error(err))
+			}
+			if purged > 0 {
+				logger.Info("Purged deleted accounts", zap.Int("count", purged))
+			}
+		}
+	}()
+
+	// Re-read the prices of items with a product URL and send price alerts
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			checked, err := priceTracker.CheckDue(context.Background())
+			if err != nil {
+				logger.Error("Failed to check item prices", zap.Error(err))
+			}
+			if checked > 0 {
+				logger.Info("Checked item prices", zap.Int("count", checked))
+			}
+		}
+	}()
+
+	// Archive wishlists whose event has passed
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			archived, err := eventService.ArchiveEnded()
+			if err != nil {
+				logger.Error("Failed to archive past wishlists", zap.Error(err))
+			}
+			if archived > 0 {
+				logger.Info("Archived past wishlists", zap.Int("count", archived))
+			}
+		}
+	}()
+
+	// Delete the files of removed item images from blob storage
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			deleted, err := itemImageService.DeleteOrphanedBlobs(context.Background())
+			if err != nil {
+				logger.Error("Failed to delete orphaned blobs", zap.Error(err))
+			}
+			if deleted > 0 {
+				logger.Info("Deleted orphaned blobs", zap.Int("count", deleted))
+			}
+		}
+	}()
+
 	// Create server
 	srv := &http.Server{
 		Addr:    ":8080",
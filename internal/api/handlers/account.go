@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/service"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// DeleteAccountRequest confirms a deletion. The password may be omitted
+// right after logging in through an identity provider.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// Export downloads everything stored about the current user as JSON.
+func (h *AccountHandler) Export(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	export, err := h.accountService.Export(userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="wishlist-export-%d.json"`, userID))
+	c.IndentedJSON(http.StatusOK, export)
+}
+
+// Delete schedules the account for deletion after the grace period and
+// logs the user out of every session.
+func (h *AccountHandler) Delete(c *gin.Context) {
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.accountService.RequestDeletion(c.Request.Context(), c.GetUint("user_id"),
+		reauthentication(c, req.Password))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"deletion_due_at": user.DeletionDueAt})
+}
+
+// CancelDeletion keeps an account that was scheduled for deletion.
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	user, err := h.accountService.CancelDeletion(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(user))
+}
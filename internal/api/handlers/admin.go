@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/service"
+)
+
+type AdminHandler struct {
+	adminService *service.AdminService
+}
+
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+type UpdateUserStatusRequest struct {
+	Disabled *bool `json:"disabled" binding:"required"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user admin"`
+}
+
+// ListUsers lists users, optionally filtered by the q query parameter,
+// which matches email addresses and display names.
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultUserPageSize)))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	page, err := h.adminService.ListUsers(c.Query("q"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UserWishLists shows the wishlists of a user to support staff.
+func (h *AdminHandler) UserWishLists(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	wishlists, err := h.adminService.UserWishLists(userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, wishlists)
+}
+
+// UpdateStatus disables or re-enables an account.
+func (h *AdminHandler) UpdateStatus(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.adminService.SetDisabled(adminActor(c), userID, *req.Disabled)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) UpdateRole(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.adminService.SetRole(adminActor(c), userID, req.Role)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ForcePasswordReset logs the user out and emails a password reset link.
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.ForcePasswordReset(c.Request.Context(), adminActor(c), userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AdminHandler) Stats(c *gin.Context) {
+	stats, err := h.adminService.Stats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to collect statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func adminActor(c *gin.Context) service.AdminActor {
+	return service.AdminActor{
+		UserID:    c.GetUint("user_id"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+func parseUserID(c *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return 0, false
+	}
+	return uint(userID), true
+}
@@ -1,26 +1,40 @@
 package handlers
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
 	"golang.org/x/crypto/bcrypt"
-	"wishlist/internal/config"
 	"wishlist/internal/domain"
 	"wishlist/internal/service"
 )
 
 type AuthHandler struct {
-	userService *service.UserService
-	config      *config.Config
+	userService         *service.UserService
+	tokenService        *service.TokenService
+	verificationService *service.EmailVerificationService
+	mfaService          *service.MFAService
+	loginGuard          *service.LoginGuard
 }
 
-func NewAuthHandler(userService *service.UserService, config *config.Config) *AuthHandler {
+func NewAuthHandler(
+	userService *service.UserService,
+	tokenService *service.TokenService,
+	verificationService *service.EmailVerificationService,
+	mfaService *service.MFAService,
+	loginGuard *service.LoginGuard,
+) *AuthHandler {
 	return &AuthHandler{
-		userService: userService,
-		config:      config,
+		userService:         userService,
+		tokenService:        tokenService,
+		verificationService: verificationService,
+		mfaService:          mfaService,
+		loginGuard:          loginGuard,
 	}
 }
 
@@ -34,8 +48,26 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type UnlockRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func newAuthResponse(pair *service.TokenPair) AuthResponse {
+	return AuthResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    pair.ExpiresAt,
+	}
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -62,19 +94,20 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
-	})
+	// The account works without a verified address, and the user can ask
+	// for another link, so a delivery failure must not fail the signup.
+	if err := h.verificationService.SendVerification(c.Request.Context(), user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
 
-	tokenString, err := token.SignedString([]byte(h.config.JWTSecret))
+	// Generate access and refresh tokens
+	pair, err := h.tokenService.Issue(user.ID, clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, AuthResponse{Token: tokenString})
+	c.JSON(http.StatusCreated, newAuthResponse(pair))
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -84,28 +117,148 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	attempt := loginAttempt(c, req.Email)
+	if err := h.loginGuard.Check(attempt); err != nil {
+		setRetryAfter(c, err)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
 	user, err := h.userService.GetByEmail(req.Email)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+	if err != nil && !errors.Is(err, service.ErrUserNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to log in"})
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+		if err := h.loginGuard.RecordFailure(c.Request.Context(), attempt, user); err != nil {
+			log.Printf("Failed to record failed login for %s: %v", attempt.IP, err)
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		return
 	}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
-	})
+	if err := h.loginGuard.RecordSuccess(attempt, user); err != nil {
+		log.Printf("Failed to record login of user %d: %v", user.ID, err)
+	}
+
+	completeLogin(c, user, h.tokenService, h.mfaService)
+}
+
+// Unlock lifts a lockout with the token from the email sent when the
+// account was locked.
+func (h *AuthHandler) Unlock(c *gin.Context) {
+	var req UnlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.loginGuard.Unlock(req.Token, loginAttempt(c, "")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// setRetryAfter tells the client when to try again if a login was
+// throttled.
+func setRetryAfter(c *gin.Context, err error) {
+	var throttled *service.LoginThrottledError
+	if errors.As(err, &throttled) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	}
+}
+
+// reauthentication is how the request confirms a sensitive change: with the
+// password, or with the current session if the password is omitted.
+func reauthentication(c *gin.Context, password string) service.Reauthentication {
+	return service.Reauthentication{SessionID: c.GetString("session_id"), Password: password}
+}
 
-	tokenString, err := token.SignedString([]byte(h.config.JWTSecret))
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+func loginAttempt(c *gin.Context, email string) service.LoginAttempt {
+	return service.LoginAttempt{
+		Email:     email,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// completeLogin responds to a successful first login step. With two-factor
+// authentication it only hands out a challenge that has to be completed
+// with a code; otherwise it starts a session.
+func completeLogin(c *gin.Context, user *domain.User, tokenService *service.TokenService, mfaService *service.MFAService) {
+	if user.Disabled() {
+		c.JSON(http.StatusForbidden, gin.H{"error": service.ErrAccountDisabled.Error()})
+		return
+	}
+
+	if user.TOTPEnabled() {
+		challenge, err := mfaService.Challenge(user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    challenge.Token,
+			ExpiresAt:   challenge.ExpiresAt,
+		})
+		return
+	}
+
+	// Generate access and refresh tokens
+	pair, err := tokenService.Issue(user.ID, clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, AuthResponse{Token: tokenString})
-} 
\ No newline at end of file
+	c.JSON(http.StatusOK, newAuthResponse(pair))
+}
+
+// Refresh rotates the refresh token and issues a new access token.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pair, err := h.tokenService.Refresh(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuthResponse(pair))
+}
+
+// Logout revokes the session of the current access token together with all
+// refresh tokens issued for it.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionID := c.GetString("session_id")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is not bound to a session"})
+		return
+	}
+
+	if err := h.tokenService.Revoke(sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to log out"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
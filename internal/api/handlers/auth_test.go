@@ -7,7 +7,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"wishlist/internal/config"
+	"wishlist/internal/api/middleware"
+	"wishlist/internal/auth"
+	"wishlist/internal/mail"
 	"wishlist/internal/repository"
 	"wishlist/internal/service"
 	"wishlist/internal/testutil"
@@ -22,19 +24,32 @@ func setupAuthTestRouter(t *testing.T) (*gin.Engine, *AuthHandler) {
 	defer testutil.CleanupDB(t, db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	tokenRepo := repository.NewTokenRepository(db)
+	userService := service.NewUserService(userRepo, nil)
+	jwtManager := auth.NewJWTManager("test-secret", auth.DefaultTokenDuration)
+	tokenService := service.NewTokenService(tokenRepo, jwtManager, service.DefaultRefreshTokenTTL)
 
-	// Создаем конфигурацию для JWT
-	cfg := &config.Config{
-		JWTSecret: "test-secret",
-	}
+	verificationService := service.NewEmailVerificationService(repository.NewEmailVerificationRepository(db), userRepo,
+		mail.NewMemoryMailer(), "http://localhost", service.DefaultEmailVerificationTTL, service.DefaultVerificationResendInterval)
+
+	loginGuard := service.NewLoginGuard(repository.NewLoginThrottleRepository(db), repository.NewSecurityEventRepository(db),
+		userRepo, mail.NewMemoryMailer(), "http://localhost", service.DefaultLockoutPolicy())
 
-	authHandler := NewAuthHandler(userService, cfg)
+	mfaService := service.NewMFAService(userRepo, repository.NewRecoveryCodeRepository(db), tokenService, jwtManager,
+		loginGuard, nil, "Wishlist", service.DefaultMFAChallengeTTL)
+
+	authHandler := NewAuthHandler(userService, tokenService, verificationService, mfaService, loginGuard)
 
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
 	r.POST("/auth/register", authHandler.Register)
 	r.POST("/auth/login", authHandler.Login)
+	r.POST("/auth/unlock", authHandler.Unlock)
+	r.POST("/auth/refresh", authHandler.Refresh)
+	r.POST("/auth/logout", middleware.Auth(jwtManager, tokenService, nil), authHandler.Logout)
+	r.GET("/protected", middleware.Auth(jwtManager, tokenService, nil), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
 
 	return r, authHandler
 }
@@ -125,3 +140,79 @@ func TestAuthHandler_Login(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 }
+
+func TestAuthHandler_RefreshAndLogout(t *testing.T) {
+	r, _ := setupAuthTestRouter(t)
+
+	reqBody := map[string]string{
+		"email":    "refresh@example.com",
+		"password": "password123",
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var login AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	require.NotEmpty(t, login.RefreshToken)
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(map[string]string{"refresh_token": token})
+		req := httptest.NewRequest("POST", "/auth/refresh", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("rotation and reuse detection", func(t *testing.T) {
+		w := refresh(login.RefreshToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var rotated AuthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+		assert.NotEqual(t, login.RefreshToken, rotated.RefreshToken)
+
+		// Replaying the consumed token revokes the whole family
+		w = refresh(login.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = refresh(rotated.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		req := httptest.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+rotated.Token)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("logout", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var session AuthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+
+		req = httptest.NewRequest("POST", "/auth/logout", nil)
+		req.Header.Set("Authorization", "Bearer "+session.Token)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		req = httptest.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+session.Token)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = refresh(session.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/ical"
+	"wishlist/internal/service"
+)
+
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+	// feedURL is the public address under which feeds are served, the
+	// token being appended to it.
+	feedURL string
+}
+
+func NewCalendarHandler(calendarService *service.CalendarService, feedURL string) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService, feedURL: strings.TrimRight(feedURL, "/")}
+}
+
+// Feed returns when the calendar feed of the current user was created and
+// last fetched. Its URL is only shown when it is created.
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	feed, err := h.calendarService.Feed(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// CreateFeed issues a new feed URL for the current user; a previous URL
+// stops working.
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+	feed, token, err := h.calendarService.CreateFeed(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"url":        h.feedURL + "/" + token + ".ics",
+		"created_at": feed.CreatedAt,
+	})
+}
+
+func (h *CalendarHandler) RevokeFeed(c *gin.Context) {
+	if err := h.calendarService.RevokeFeed(c.GetUint("user_id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Serve renders the calendar of a feed. The token is the secret part of
+// the URL, optionally followed by .ics. Clients polling with If-None-Match
+// or If-Modified-Since get 304 Not Modified while the calendar is
+// unchanged.
+func (h *CalendarHandler) Serve(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	document, err := h.calendarService.Render(token)
+	if err != nil {
+		if errors.Is(err, service.ErrCalendarFeedNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render calendar"})
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", ical.ContentType)
+	header.Set("ETag", document.ETag)
+	header.Set("Cache-Control", "private, no-cache")
+	http.ServeContent(c.Writer, c.Request, "", document.LastModified, bytes.NewReader(document.Body))
+}
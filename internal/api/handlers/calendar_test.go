@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubCalendarFeeds struct {
+	feeds map[uint]*domain.CalendarFeed
+}
+
+func (r *stubCalendarFeeds) Replace(feed *domain.CalendarFeed) error {
+	r.feeds[feed.UserID] = feed
+	return nil
+}
+
+func (r *stubCalendarFeeds) FindByUserID(userID uint) (*domain.CalendarFeed, error) {
+	return r.feeds[userID], nil
+}
+
+func (r *stubCalendarFeeds) FindByHash(hash string) (*domain.CalendarFeed, error) {
+	for _, feed := range r.feeds {
+		if feed.TokenHash == hash {
+			return feed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *stubCalendarFeeds) Update(feed *domain.CalendarFeed) error {
+	return nil
+}
+
+func (r *stubCalendarFeeds) DeleteByUserID(userID uint) (bool, error) {
+	_, ok := r.feeds[userID]
+	delete(r.feeds, userID)
+	return ok, nil
+}
+
+type stubCalendarEvents struct {
+	wishlists []*domain.EventWishList
+}
+
+func (r *stubCalendarEvents) FindCalendarEventsForUser(userID uint, since domain.Date) ([]*domain.EventWishList, error) {
+	return r.wishlists, nil
+}
+
+type stubUsers struct {
+	user *domain.User
+}
+
+func (r *stubUsers) Create(user *domain.User) error { return nil }
+
+func (r *stubUsers) FindByID(id uint) (*domain.User, error) { return r.user, nil }
+
+func (r *stubUsers) FindByEmail(email string) (*domain.User, error) { return r.user, nil }
+
+func (r *stubUsers) Update(user *domain.User) error { return nil }
+
+func TestCalendarHandler_Serve(t *testing.T) {
+	eventDate := domain.Date{Year: 1990, Month: time.May, Day: 4}
+	events := &stubCalendarEvents{wishlists: []*domain.EventWishList{{
+		WishList: domain.WishList{
+			ID: 7, UserID: 2, Name: "Birthday", Occasion: domain.OccasionBirthday,
+			EventDate: &eventDate, Recurrence: domain.RecurrenceYearly, UpdatedAt: time.Now(),
+		},
+		OwnerName: "Sam",
+	}}}
+	calendarService := service.NewCalendarService(
+		&stubCalendarFeeds{feeds: map[uint]*domain.CalendarFeed{}}, events, &stubUsers{user: &domain.User{ID: 1}}, "https://app.example.com")
+	_, token, err := calendarService.CreateFeed(1)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewCalendarHandler(calendarService, "https://api.example.com/calendar")
+	r.GET("/calendar/:token", h.Serve)
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/calendar/"+token+".ics", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "SUMMARY:Birthday (Sam)\r\n")
+	assert.Contains(t, w.Body.String(), "RRULE:FREQ=YEARLY\r\n")
+	etag := w.Header().Get("ETag")
+	lastModified := w.Header().Get("Last-Modified")
+	require.NotEmpty(t, etag)
+	require.NotEmpty(t, lastModified)
+
+	t.Run("not modified", func(t *testing.T) {
+		w := get("/calendar/"+token, http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+
+		w = get("/calendar/"+token, http.Header{"If-Modified-Since": {lastModified}})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		events.wishlists[0].Name = "30th birthday"
+		w := get("/calendar/"+token, http.Header{"If-None-Match": {etag}})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+		assert.True(t, strings.Contains(w.Body.String(), "SUMMARY:30th birthday (Sam)"))
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		require.NoError(t, calendarService.RevokeFeed(1))
+		assert.Equal(t, http.StatusNotFound, get("/calendar/"+token, nil).Code)
+	})
+}
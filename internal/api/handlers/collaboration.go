@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/domain"
+	"wishlist/internal/service"
+)
+
+type CollaborationHandler struct {
+	service *service.CollaborationService
+}
+
+func NewCollaborationHandler(service *service.CollaborationService) *CollaborationHandler {
+	return &CollaborationHandler{service: service}
+}
+
+type InviteRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// CollaboratorResponse is a collaborator as shown to the other members of
+// the list. It only names the user, unlike the full profile.
+type CollaboratorResponse struct {
+	ID         uint                      `json:"id"`
+	WishListID uint                      `json:"wishlist_id"`
+	UserID     uint                      `json:"user_id"`
+	Role       string                    `json:"role"`
+	User       *CollaboratorUserResponse `json:"user,omitempty"`
+	CreatedAt  time.Time                 `json:"created_at"`
+}
+
+type CollaboratorUserResponse struct {
+	ID          uint   `json:"id"`
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email"`
+}
+
+func newCollaboratorResponse(collaborator *domain.Collaborator) CollaboratorResponse {
+	response := CollaboratorResponse{
+		ID:         collaborator.ID,
+		WishListID: collaborator.WishListID,
+		UserID:     collaborator.UserID,
+		Role:       collaborator.Role,
+		CreatedAt:  collaborator.CreatedAt,
+	}
+	if user := collaborator.User; user != nil {
+		response.User = &CollaboratorUserResponse{ID: user.ID, DisplayName: user.DisplayName, Email: user.Email}
+	}
+	return response
+}
+
+func (h *CollaborationHandler) ListCollaborators(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	collaborators, err := h.service.ListCollaborators(uint(wishlistID), userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]CollaboratorResponse, 0, len(collaborators))
+	for _, collaborator := range collaborators {
+		response = append(response, newCollaboratorResponse(collaborator))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CollaborationHandler) UpdateRole(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return
+	}
+
+	collaboratorID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	collaborator, err := h.service.UpdateRole(uint(wishlistID), uint(collaboratorID), req.Role, userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCollaboratorResponse(collaborator))
+}
+
+func (h *CollaborationHandler) RemoveCollaborator(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return
+	}
+
+	collaboratorID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service.RemoveCollaborator(uint(wishlistID), uint(collaboratorID), userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CollaborationHandler) Invite(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return
+	}
+
+	var req InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	invitation, err := h.service.Invite(uint(wishlistID), userID, req.Email, req.Role)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+func (h *CollaborationHandler) ListInvitations(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	invitations, err := h.service.ListInvitations(uint(wishlistID), userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+func (h *CollaborationHandler) RevokeInvitation(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return
+	}
+
+	invitationID, err := strconv.ParseUint(c.Param("invitationId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation id"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service.RevokeInvitation(uint(wishlistID), uint(invitationID), userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MyInvitations lists pending invitations addressed to the current user.
+func (h *CollaborationHandler) MyInvitations(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	invitations, err := h.service.PendingInvitations(userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+func (h *CollaborationHandler) AcceptInvitation(c *gin.Context) {
+	invitationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation id"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	collaborator, err := h.service.AcceptInvitation(uint(invitationID), userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCollaboratorResponse(collaborator))
+}
+
+func (h *CollaborationHandler) DeclineInvitation(c *gin.Context) {
+	invitationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation id"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service.DeclineInvitation(uint(invitationID), userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
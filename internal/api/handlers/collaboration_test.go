@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCollaboratorResponse(t *testing.T) {
+	now := time.Now()
+	pending := "new@example.com"
+	collaborator := &domain.Collaborator{
+		ID:         3,
+		WishListID: 7,
+		UserID:     5,
+		Role:       domain.RoleEditor,
+		User: &domain.User{
+			ID:            5,
+			Email:         "partner@example.com",
+			PendingEmail:  &pending,
+			DisplayName:   "Partner",
+			Birthday:      &now,
+			Role:          domain.UserRoleAdmin,
+			TOTPEnabledAt: &now,
+			DisabledAt:    &now,
+			DeletionDueAt: &now,
+		},
+	}
+
+	body, err := json.Marshal(newCollaboratorResponse(collaborator))
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &response))
+	assert.Equal(t, domain.RoleEditor, response["role"])
+	assert.Equal(t, map[string]interface{}{
+		"id":           float64(5),
+		"display_name": "Partner",
+		"email":        "partner@example.com",
+	}, response["user"])
+}
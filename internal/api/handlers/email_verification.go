@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/service"
+)
+
+type EmailVerificationHandler struct {
+	verificationService *service.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(verificationService *service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{verificationService: verificationService}
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Verify confirms the email address using the token from the verification
+// link. It does not require authentication so the link works on any device.
+func (h *EmailVerificationHandler) Verify(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.verificationService.Verify(req.Token)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"email":             user.Email,
+		"email_verified_at": user.EmailVerifiedAt,
+	})
+}
+
+// Resend emails a new verification link to the current user.
+func (h *EmailVerificationHandler) Resend(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if err := h.verificationService.Resend(c.Request.Context(), userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "verification email sent"})
+}
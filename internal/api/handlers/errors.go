@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/imaging"
+	"wishlist/internal/linkpreview"
+	"wishlist/internal/service"
+
+	"gorm.io/gorm"
+)
+
+// errorStatus maps service errors to HTTP status codes. Records that
+// repositories could not find are reported as not found.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound),
+		errors.Is(err, service.ErrWishListNotFound),
+		errors.Is(err, service.ErrItemNotFound),
+		errors.Is(err, service.ErrItemNotReserved),
+		errors.Is(err, service.ErrInvitationNotFound),
+		errors.Is(err, service.ErrCollaboratorNotFound),
+		errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrAccessTokenNotFound),
+		errors.Is(err, service.ErrUnknownProvider),
+		errors.Is(err, service.ErrSessionNotFound),
+		errors.Is(err, service.ErrImageNotFound),
+		errors.Is(err, service.ErrTagNotFound),
+		errors.Is(err, service.ErrCalendarFeedNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrItemAlreadyReserved),
+		errors.Is(err, service.ErrInvitationExists),
+		errors.Is(err, service.ErrInvitationNotPending),
+		errors.Is(err, service.ErrAlreadyCollaborator),
+		errors.Is(err, service.ErrEmailAlreadyVerified),
+		errors.Is(err, service.ErrMFAAlreadyEnabled),
+		errors.Is(err, service.ErrEmailTaken),
+		errors.Is(err, service.ErrDeletionScheduled),
+		errors.Is(err, service.ErrTooManyImages),
+		errors.Is(err, service.ErrTagExists):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrClaimantRequired),
+		errors.Is(err, service.ErrInvalidRole),
+		errors.Is(err, service.ErrInvalidResetToken),
+		errors.Is(err, service.ErrInvalidVerificationToken),
+		errors.Is(err, service.ErrMFANotEnabled),
+		errors.Is(err, service.ErrMFASetupRequired),
+		errors.Is(err, service.ErrInvalidScope),
+		errors.Is(err, service.ErrInvalidExpiry),
+		errors.Is(err, service.ErrInvalidOIDCState),
+		errors.Is(err, service.ErrInvalidUnlockToken),
+		errors.Is(err, service.ErrInvalidProfile),
+		errors.Is(err, service.ErrEmailUnchanged),
+		errors.Is(err, service.ErrDeletionNotScheduled),
+		errors.Is(err, service.ErrCannotModifySelf),
+		errors.Is(err, service.ErrInvalidItemOrder),
+		errors.Is(err, service.ErrInvalidTagName),
+		errors.Is(err, service.ErrInvalidTagMerge),
+		errors.Is(err, service.ErrTooManyTags),
+		errors.Is(err, service.ErrInvalidEvent),
+		errors.Is(err, service.ErrInvalidSort),
+		errors.Is(err, domain.ErrInvalidCursor),
+		errors.Is(err, linkpreview.ErrInvalidURL),
+		errors.Is(err, linkpreview.ErrBlockedAddress),
+		errors.Is(err, imaging.ErrInvalidImage),
+		errors.Is(err, imaging.ErrTooLarge):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrInvalidCredentials),
+		errors.Is(err, service.ErrInvalidMFACode),
+		errors.Is(err, service.ErrInvalidMFAChallenge),
+		errors.Is(err, service.ErrRecentLoginRequired),
+		errors.Is(err, service.ErrOIDCLoginFailed):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrAccessDenied),
+		errors.Is(err, service.ErrEmailNotVerified),
+		errors.Is(err, service.ErrOIDCEmailNotVerified),
+		errors.Is(err, service.ErrAccountDisabled):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrVerificationThrottled),
+		errors.Is(err, service.ErrTooManyLoginAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, service.ErrAccountLocked):
+		return http.StatusLocked
+	case errors.Is(err, imaging.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, linkpreview.ErrUnsupportedContent),
+		errors.Is(err, linkpreview.ErrFetchFailed):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/service"
+)
+
+// DefaultImageUploadMaxBytes is the default size limit of an uploaded image.
+const DefaultImageUploadMaxBytes = 10 << 20
+
+// multipartOverhead allows for the multipart headers and boundaries around
+// an uploaded file.
+const multipartOverhead = 64 << 10
+
+type ItemImageHandler struct {
+	images   *service.ItemImageService
+	maxBytes int64
+}
+
+func NewItemImageHandler(images *service.ItemImageService, maxBytes int64) *ItemImageHandler {
+	return &ItemImageHandler{images: images, maxBytes: maxBytes}
+}
+
+// Upload adds a picture, sent as the "image" field of a multipart form, to
+// an item.
+func (h *ItemImageHandler) Upload(c *gin.Context) {
+	wishlistID, itemID, ok := itemParams(c)
+	if !ok {
+		return
+	}
+
+	tooLarge := gin.H{"error": fmt.Sprintf("image must be at most %d bytes", h.maxBytes)}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes+multipartOverhead)
+	header, err := c.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, tooLarge)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image file is required"})
+		return
+	}
+	if header.Size > h.maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, tooLarge)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read image"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read image"})
+		return
+	}
+
+	image, err := h.images.Upload(c.Request.Context(), wishlistID, itemID, data, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, image)
+}
+
+// Delete removes an image from an item.
+func (h *ItemImageHandler) Delete(c *gin.Context) {
+	wishlistID, itemID, ok := itemParams(c)
+	if !ok {
+		return
+	}
+
+	imageID, err := strconv.ParseUint(c.Param("imageId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid image id"})
+		return
+	}
+
+	err = h.images.Delete(c.Request.Context(), wishlistID, itemID, uint(imageID), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// itemParams reads the wishlist and item IDs from the path, responding
+// with 400 when they are malformed.
+func itemParams(c *gin.Context) (uint, uint, bool) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return 0, 0, false
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("itemId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item id"})
+		return 0, 0, false
+	}
+
+	return uint(wishlistID), uint(itemID), true
+}
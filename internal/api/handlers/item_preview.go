@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/linkpreview"
+)
+
+type ItemPreviewHandler struct {
+	previewer *linkpreview.Previewer
+}
+
+func NewItemPreviewHandler(previewer *linkpreview.Previewer) *ItemPreviewHandler {
+	return &ItemPreviewHandler{previewer: previewer}
+}
+
+type itemPreviewRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// Preview fetches a product page and returns the item details found on it,
+// for the client to pre-fill a new item with.
+func (h *ItemPreviewHandler) Preview(c *gin.Context) {
+	var req itemPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	meta, err := h.previewer.Preview(c.Request.Context(), req.URL)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, meta)
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/auth"
+)
+
+type JWKSHandler struct {
+	jwtManager *auth.JWTManager
+}
+
+func NewJWKSHandler(jwtManager *auth.JWTManager) *JWKSHandler {
+	return &JWKSHandler{jwtManager: jwtManager}
+}
+
+// Keys serves the public signing keys so that other services can verify our
+// access tokens. The set is empty when tokens are signed with a shared secret.
+func (h *JWKSHandler) Keys(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtManager.JWKS())
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/service"
+)
+
+type MFAHandler struct {
+	mfaService *service.MFAService
+}
+
+func NewMFAHandler(mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type EnableMFARequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableMFARequest and RegenerateRecoveryCodesRequest confirm the change
+// with the password, which may be omitted right after logging in through
+// an identity provider.
+type DisableMFARequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type RegenerateRecoveryCodesRequest struct {
+	Password string `json:"password"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Login completes the second step of a login with a TOTP or recovery code.
+func (h *MFAHandler) Login(c *gin.Context) {
+	var req MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pair, err := h.mfaService.CompleteLogin(c.Request.Context(), req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		setRetryAfter(c, err)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newAuthResponse(pair))
+}
+
+// Setup starts TOTP enrollment and returns the secret and the URI to render
+// as a QR code.
+func (h *MFAHandler) Setup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	setup, err := h.mfaService.BeginSetup(userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// Enable confirms enrollment with a first code and returns recovery codes.
+func (h *MFAHandler) Enable(c *gin.Context) {
+	var req EnableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	codes, err := h.mfaService.Enable(userID, req.Code)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var req DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.mfaService.Disable(userID, reauthentication(c, req.Password), req.Code); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req RegenerateRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	codes, err := h.mfaService.RegenerateRecoveryCodes(userID, reauthentication(c, req.Password))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
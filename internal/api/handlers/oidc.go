@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/service"
+)
+
+type OIDCHandler struct {
+	oidcService  *service.OIDCService
+	tokenService *service.TokenService
+	mfaService   *service.MFAService
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService, tokenService *service.TokenService, mfaService *service.MFAService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:  oidcService,
+		tokenService: tokenService,
+		mfaService:   mfaService,
+	}
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// Providers lists the identity providers available for login.
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.oidcService.Providers()})
+}
+
+// Authorize starts a login and returns the provider URL to redirect to.
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	authorization, err := h.oidcService.Authorize(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, authorization)
+}
+
+// Callback completes the login with the code the provider redirected back
+// with. The response is the same as for a password login.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.oidcService.Callback(c.Request.Context(), c.Param("provider"), req.Code, req.State)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	completeLogin(c, user, h.tokenService, h.mfaService)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/domain"
+)
+
+// pageQuery reads the pagination parameters of a listing: sort, a sort
+// name optionally prefixed with "-" for descending order; limit; cursor,
+// as found in the Link header of the previous response; and count=true to
+// have the total number of rows returned in X-Total-Count. It responds
+// with 400 on an invalid cursor.
+func pageQuery(c *gin.Context) (domain.PageQuery, bool) {
+	var page domain.PageQuery
+	page.Sort = c.Query("sort")
+	if strings.HasPrefix(page.Sort, "-") {
+		page.Sort = page.Sort[1:]
+		page.Desc = true
+	}
+	page.Limit, _ = strconv.Atoi(c.Query("limit"))
+	page.Count, _ = strconv.ParseBool(c.Query("count"))
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := domain.ParseCursor(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return page, false
+		}
+		page.Cursor = cursor
+	}
+	return page, true
+}
+
+// setPageHeaders links the adjacent pages of a listing in the Link header
+// and sets X-Total-Count if the total was counted.
+func setPageHeaders(c *gin.Context, next, prev *domain.Cursor, total *int64) {
+	var links []string
+	for _, link := range []struct {
+		rel    string
+		cursor *domain.Cursor
+	}{{"next", next}, {"prev", prev}} {
+		if link.cursor == nil {
+			continue
+		}
+		u := *c.Request.URL
+		query := u.Query()
+		query.Set("cursor", link.cursor.Encode())
+		u.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), link.rel))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+	if total != nil {
+		c.Header("X-Total-Count", strconv.FormatInt(*total, 10))
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"wishlist/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	next := &domain.Cursor{Sort: "-name", Key: "Books", ID: 3}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/wishlists?sort=-name&limit=10&count=true&cursor="+next.Encode(), nil)
+
+	page, ok := pageQuery(c)
+	require.True(t, ok)
+	assert.Equal(t, domain.SortName, page.Sort)
+	assert.True(t, page.Desc)
+	assert.Equal(t, 10, page.Limit)
+	assert.True(t, page.Count)
+	assert.Equal(t, next, page.Cursor)
+
+	total := int64(12)
+	setPageHeaders(c, next, &domain.Cursor{Sort: "-name", Key: "Music", ID: 9, Before: true}, &total)
+	assert.Equal(t, "12", w.Header().Get("X-Total-Count"))
+	links := strings.Split(w.Header().Get("Link"), ", ")
+	require.Len(t, links, 2)
+	assert.True(t, strings.HasSuffix(links[0], `>; rel="next"`))
+	assert.True(t, strings.HasSuffix(links[1], `>; rel="prev"`))
+
+	link, err := url.Parse(strings.TrimPrefix(strings.SplitN(links[0], ">", 2)[0], "<"))
+	require.NoError(t, err)
+	assert.Equal(t, "/wishlists", link.Path)
+	assert.Equal(t, "-name", link.Query().Get("sort"))
+	assert.Equal(t, next.Encode(), link.Query().Get("cursor"))
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/wishlists?cursor=bogus", nil)
+		_, ok := pageQuery(c)
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
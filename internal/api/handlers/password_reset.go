@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/service"
+)
+
+type PasswordResetHandler struct {
+	resetService *service.PasswordResetService
+}
+
+func NewPasswordResetHandler(resetService *service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{resetService: resetService}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// Forgot sends a reset link. The response is the same whether or not the
+// address belongs to an account.
+func (h *PasswordResetHandler) Forgot(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.resetService.RequestReset(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send reset email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the address is registered, a reset link has been sent"})
+}
+
+// Reset sets a new password using the token from the reset link.
+func (h *PasswordResetHandler) Reset(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.resetService.ResetPassword(req.Token, req.Password); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/domain"
+	"wishlist/internal/service"
+)
+
+type PersonalAccessTokenHandler struct {
+	tokenService *service.PersonalAccessTokenService
+}
+
+func NewPersonalAccessTokenHandler(tokenService *service.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{tokenService: tokenService}
+}
+
+type CreatePersonalAccessTokenRequest struct {
+	Name       string     `json:"name" binding:"required,max=100"`
+	Scope      string     `json:"scope" binding:"required,oneof=read write"`
+	WishListID *uint      `json:"wishlist_id"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// CreatePersonalAccessTokenResponse carries the token secret, which is only
+// ever returned once.
+type CreatePersonalAccessTokenResponse struct {
+	*domain.PersonalAccessToken
+	Token string `json:"token"`
+}
+
+func (h *PersonalAccessTokenHandler) Create(c *gin.Context) {
+	var req CreatePersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	token, plain, err := h.tokenService.Create(userID, service.NewPersonalAccessToken{
+		Name:       req.Name,
+		Scope:      req.Scope,
+		WishListID: req.WishListID,
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePersonalAccessTokenResponse{
+		PersonalAccessToken: token,
+		Token:               plain,
+	})
+}
+
+func (h *PersonalAccessTokenHandler) List(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	tokens, err := h.tokenService.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *PersonalAccessTokenHandler) Revoke(c *gin.Context) {
+	tokenID, err := strconv.ParseUint(c.Param("tokenId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token ID"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.tokenService.Revoke(userID, uint(tokenID)); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
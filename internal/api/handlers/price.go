@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/service"
+)
+
+type PriceHandler struct {
+	priceTracker *service.PriceTracker
+}
+
+func NewPriceHandler(priceTracker *service.PriceTracker) *PriceHandler {
+	return &PriceHandler{priceTracker: priceTracker}
+}
+
+// History lists the prices recorded for an item, oldest first.
+func (h *PriceHandler) History(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("itemId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item id"})
+		return
+	}
+
+	points, err := h.priceTracker.History(uint(wishlistID), uint(itemID), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, points)
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/domain"
+	"wishlist/internal/service"
+)
+
+type ProfileHandler struct {
+	userService          *service.UserService
+	verificationService  *service.EmailVerificationService
+	tokenService         *service.TokenService
+	personalTokenService *service.PersonalAccessTokenService
+}
+
+func NewProfileHandler(
+	userService *service.UserService,
+	verificationService *service.EmailVerificationService,
+	tokenService *service.TokenService,
+	personalTokenService *service.PersonalAccessTokenService,
+) *ProfileHandler {
+	return &ProfileHandler{
+		userService:          userService,
+		verificationService:  verificationService,
+		tokenService:         tokenService,
+		personalTokenService: personalTokenService,
+	}
+}
+
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,max=500"`
+	// Birthday is formatted as YYYY-MM-DD; an empty string clears it.
+	Birthday *string `json:"birthday"`
+	Locale   *string `json:"locale"`
+	Timezone *string `json:"timezone"`
+}
+
+// ChangeEmailRequest starts an email change. The password may be omitted
+// right after logging in through an identity provider.
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+type ProfileResponse struct {
+	ID               uint       `json:"id"`
+	Email            string     `json:"email"`
+	PendingEmail     *string    `json:"pending_email,omitempty"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	DisplayName      string     `json:"display_name"`
+	AvatarURL        string     `json:"avatar_url"`
+	Birthday         *string    `json:"birthday"`
+	Locale           string     `json:"locale"`
+	Timezone         string     `json:"timezone"`
+	Role             string     `json:"role"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	DeletionDueAt    *time.Time `json:"deletion_due_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func newProfileResponse(user *domain.User) ProfileResponse {
+	response := ProfileResponse{
+		ID:               user.ID,
+		Email:            user.Email,
+		PendingEmail:     user.PendingEmail,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		DisplayName:      user.DisplayName,
+		AvatarURL:        user.AvatarURL,
+		Locale:           user.Locale,
+		Timezone:         user.Timezone,
+		Role:             user.Role,
+		TwoFactorEnabled: user.TOTPEnabled(),
+		DeletionDueAt:    user.DeletionDueAt,
+		CreatedAt:        user.CreatedAt,
+	}
+	if user.Birthday != nil {
+		birthday := user.Birthday.Format(service.BirthdayLayout)
+		response.Birthday = &birthday
+	}
+	return response
+}
+
+func (h *ProfileHandler) Get(c *gin.Context) {
+	user, err := h.userService.GetByID(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(user))
+}
+
+func (h *ProfileHandler) Update(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(c.GetUint("user_id"), service.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarURL,
+		Birthday:    req.Birthday,
+		Locale:      req.Locale,
+		Timezone:    req.Timezone,
+	})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(user))
+}
+
+// ChangeEmail starts an email change. The account keeps its current address
+// until the link sent to the new one is followed.
+func (h *ProfileHandler) ChangeEmail(c *gin.Context) {
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.RequestEmailChange(c.GetUint("user_id"), reauthentication(c, req.Password), req.Email)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.verificationService.SendEmailChange(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send confirmation email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, newProfileResponse(user))
+}
+
+// ChangePassword sets a new password, logs out all other sessions and
+// revokes the personal access tokens of the user, so that whoever knew the
+// old password loses access. The session making the change stays logged
+// in.
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.userService.ChangePassword(userID, req.CurrentPassword, req.NewPassword); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.tokenService.RevokeOtherSessions(userID, c.GetString("session_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+		return
+	}
+
+	if err := h.personalTokenService.RevokeAll(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke access tokens"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/service"
+)
+
+// ReservationTokenHeader carries the secret returned on claim when an
+// anonymous claimant releases a reservation.
+const ReservationTokenHeader = "X-Reservation-Token"
+
+type ReservationHandler struct {
+	service *service.ReservationService
+}
+
+func NewReservationHandler(service *service.ReservationService) *ReservationHandler {
+	return &ReservationHandler{service: service}
+}
+
+type ClaimRequest struct {
+	Name  string `json:"name" binding:"max=255"`
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+type ClaimResponse struct {
+	ID        uint      `json:"id"`
+	ItemID    uint      `json:"item_id"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (h *ReservationHandler) Claim(c *gin.Context) {
+	itemID, err := strconv.ParseUint(c.Param("itemId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item id"})
+		return
+	}
+
+	var req ClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claimant := service.Claimant{
+		UserID: c.GetUint("user_id"),
+		Name:   req.Name,
+		Email:  req.Email,
+	}
+
+	reservation, token, err := h.service.Claim(c.Param("shareCode"), uint(itemID), claimant)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ClaimResponse{
+		ID:        reservation.ID,
+		ItemID:    reservation.WishItemID,
+		Token:     token,
+		CreatedAt: reservation.CreatedAt,
+	})
+}
+
+func (h *ReservationHandler) Unclaim(c *gin.Context) {
+	itemID, err := strconv.ParseUint(c.Param("itemId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item id"})
+		return
+	}
+
+	token := c.GetHeader(ReservationTokenHeader)
+	userID := c.GetUint("user_id")
+
+	if err := h.service.Unclaim(c.Param("shareCode"), uint(itemID), token, userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
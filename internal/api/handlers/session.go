@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/domain"
+	"wishlist/internal/service"
+)
+
+type SessionHandler struct {
+	tokenService *service.TokenService
+}
+
+func NewSessionHandler(tokenService *service.TokenService) *SessionHandler {
+	return &SessionHandler{tokenService: tokenService}
+}
+
+// SessionResponse is a login session, flagged when it is the one making
+// the request.
+type SessionResponse struct {
+	*domain.TokenFamily
+	Current bool `json:"current"`
+}
+
+func (h *SessionHandler) List(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	sessions, err := h.tokenService.Sessions(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list sessions"})
+		return
+	}
+
+	currentID := c.GetString("session_id")
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, SessionResponse{
+			TokenFamily: session,
+			Current:     session.ID == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Revoke logs out one session, which may be the current one.
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if err := h.tokenService.RevokeSession(userID, c.Param("sessionId")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeOthers logs out everywhere except the current session.
+func (h *SessionHandler) RevokeOthers(c *gin.Context) {
+	sessionID := c.GetString("session_id")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is not bound to a session"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.tokenService.RevokeOtherSessions(userID, sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
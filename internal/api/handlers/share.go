@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/domain"
+)
+
+type ShareSettingsRequest struct {
+	IsPublic     *bool `json:"is_public" binding:"required"`
+	SurpriseMode *bool `json:"surprise_mode"`
+}
+
+type ShareCodeResponse struct {
+	ShareCode string `json:"share_code"`
+}
+
+// SharedWishListResponse is the public, read-only view of a wishlist. It
+// deliberately omits the owner and sharing details.
+type SharedWishListResponse struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Status      string               `json:"status"`
+	Items       []SharedItemResponse `json:"items"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// SharedItemResponse carries what friends need to buy the item. Price
+// tracking settings stay private to the list members.
+type SharedItemResponse struct {
+	ID               uint                  `json:"id"`
+	Name             string                `json:"name"`
+	Description      string                `json:"description"`
+	Status           string                `json:"status"`
+	Priority         int                   `json:"priority"`
+	URL              string                `json:"url"`
+	Price            *domain.Decimal       `json:"price"`
+	Currency         string                `json:"currency"`
+	Quantity         int                   `json:"quantity"`
+	ReceivedQuantity int                   `json:"received_quantity"`
+	Images           []SharedImageResponse `json:"images"`
+	Reserved         bool                  `json:"reserved"`
+}
+
+type SharedImageResponse struct {
+	URL        string            `json:"url"`
+	Thumbnails map[string]string `json:"thumbnails,omitempty"`
+}
+
+func newSharedWishListResponse(wishlist *domain.WishList) SharedWishListResponse {
+	items := make([]SharedItemResponse, 0, len(wishlist.Items))
+	for _, item := range wishlist.Items {
+		images := make([]SharedImageResponse, 0, len(item.Images))
+		for _, image := range item.Images {
+			images = append(images, SharedImageResponse{URL: image.URL, Thumbnails: image.Thumbnails})
+		}
+		items = append(items, SharedItemResponse{
+			ID:               item.ID,
+			Name:             item.Name,
+			Description:      item.Description,
+			Status:           item.Status,
+			Priority:         item.Priority,
+			URL:              item.URL,
+			Price:            item.Price,
+			Currency:         item.Currency,
+			Quantity:         item.Quantity,
+			ReceivedQuantity: item.ReceivedQuantity,
+			Images:           images,
+			Reserved:         item.Reserved,
+		})
+	}
+
+	return SharedWishListResponse{
+		Name:        wishlist.Name,
+		Description: wishlist.Description,
+		Status:      wishlist.Status,
+		Items:       items,
+		UpdatedAt:   wishlist.UpdatedAt,
+	}
+}
+
+func (h *WishListHandler) GenerateShareCode(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	code, err := h.service.GenerateShareCode(uint(id), userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ShareCodeResponse{ShareCode: code})
+}
+
+func (h *WishListHandler) RevokeShareCode(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service.RevokeShareCode(uint(id), userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WishListHandler) UpdateShareSettings(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req ShareSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	wishlist, err := h.service.UpdateShareSettings(uint(id), userID, *req.IsPublic, req.SurpriseMode)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, wishlist)
+}
+
+// GetShared serves a public wishlist by share code. It is mounted outside
+// the authenticated route group; a logged-in viewer is optional.
+func (h *WishListHandler) GetShared(c *gin.Context) {
+	wishlist, err := h.service.GetByShareCode(c.Param("shareCode"), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newSharedWishListResponse(wishlist))
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"wishlist/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSharedWishListResponse(t *testing.T) {
+	price := domain.Decimal("149.90")
+	alert := domain.Decimal("120")
+	wishlist := &domain.WishList{
+		Name: "Birthday",
+		Items: []domain.WishItem{{
+			ID:               4,
+			Name:             "Headphones",
+			URL:              "https://shop.example.com/headphones",
+			Price:            &price,
+			Currency:         "EUR",
+			Quantity:         2,
+			ReceivedQuantity: 1,
+			PriceAlertBelow:  &alert,
+			Images: []domain.ItemImage{{
+				URL:        "https://cdn.example.com/front.jpg",
+				BlobKey:    "items/4/front.jpg",
+				Thumbnails: map[string]string{"small": "https://cdn.example.com/front-small.jpg"},
+			}},
+			Reserved: true,
+		}},
+	}
+
+	body, err := json.Marshal(newSharedWishListResponse(wishlist))
+	require.NoError(t, err)
+
+	var response struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(body, &response))
+	require.Len(t, response.Items, 1)
+	item := response.Items[0]
+	assert.Equal(t, "https://shop.example.com/headphones", item["url"])
+	assert.Equal(t, 149.9, item["price"])
+	assert.Equal(t, "EUR", item["currency"])
+	assert.Equal(t, float64(2), item["quantity"])
+	assert.Equal(t, float64(1), item["received_quantity"])
+	assert.Equal(t, true, item["reserved"])
+	assert.NotContains(t, item, "price_alert_below")
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"url":        "https://cdn.example.com/front.jpg",
+		"thumbnails": map[string]interface{}{"small": "https://cdn.example.com/front-small.jpg"},
+	}}, item["images"])
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/domain"
+	"wishlist/internal/service"
+)
+
+type TagHandler struct {
+	tagService *service.TagService
+}
+
+func NewTagHandler(tagService *service.TagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+type tagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type mergeTagRequest struct {
+	IntoID uint `json:"into_id" binding:"required"`
+}
+
+// setTagsRequest names the tags to put on a wishlist or item; an empty list
+// removes all of them.
+type setTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// List returns the tags of the current user with their usage counts.
+func (h *TagHandler) List(c *gin.Context) {
+	tags, err := h.tagService.List(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *TagHandler) Create(c *gin.Context) {
+	var req tagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.Create(req.Name, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// Rename changes the name of a tag.
+func (h *TagHandler) Rename(c *gin.Context) {
+	tagID, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var req tagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.Rename(tagID, req.Name, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Merge replaces the tag with the one given as into_id everywhere and
+// deletes it.
+func (h *TagHandler) Merge(c *gin.Context) {
+	tagID, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	var req mergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.Merge(tagID, req.IntoID, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) Delete(c *gin.Context) {
+	tagID, ok := parseTagID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tagService.Delete(tagID, c.GetUint("user_id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetWishListTags replaces the tags of a wishlist by name, creating tags
+// that do not exist yet.
+func (h *TagHandler) SetWishListTags(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return
+	}
+
+	var req setTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := h.tagService.SetWishListTags(uint(wishlistID), req.Tags, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": nonNilTags(tags)})
+}
+
+// SetItemTags replaces the tags of an item by name, creating tags that do
+// not exist yet.
+func (h *TagHandler) SetItemTags(c *gin.Context) {
+	wishlistID, itemID, ok := itemParams(c)
+	if !ok {
+		return
+	}
+
+	var req setTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := h.tagService.SetItemTags(wishlistID, itemID, req.Tags, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": nonNilTags(tags)})
+}
+
+func parseTagID(c *gin.Context) (uint, bool) {
+	tagID, err := strconv.ParseUint(c.Param("tagId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag id"})
+		return 0, false
+	}
+	return uint(tagID), true
+}
+
+// nonNilTags makes an empty tag list render as [] rather than null.
+func nonNilTags(tags []*domain.Tag) []*domain.Tag {
+	if tags == nil {
+		return []*domain.Tag{}
+	}
+	return tags
+}
+
+// tagFilter reads the tags query parameter, a comma-separated list of tag
+// names that may also be repeated, and tag_match, which is "all" (default)
+// to require every tag or "any" for at least one. It responds with 400 on
+// an unknown match mode.
+func tagFilter(c *gin.Context) (domain.TagFilter, bool) {
+	var filter domain.TagFilter
+	switch c.Query("tag_match") {
+	case "", "all":
+	case "any":
+		filter.MatchAny = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": `tag_match must be "all" or "any"`})
+		return filter, false
+	}
+
+	for _, value := range c.QueryArray("tags") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.Join(strings.Fields(name), " "); name != "" {
+				filter.Names = append(filter.Names, name)
+			}
+		}
+	}
+	return filter, true
+}
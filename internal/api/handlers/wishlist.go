@@ -3,48 +3,129 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"wishlist/internal/domain"
 	"wishlist/internal/service"
+	"wishlist/internal/validation"
 )
 
 type WishListHandler struct {
 	service *service.WishListService
 }
 
+// wishListRequest is the body accepted when creating or replacing a
+// wishlist. Items, tags and share codes have endpoints of their own.
+type wishListRequest struct {
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	Status       string       `json:"status"`
+	IsPublic     bool         `json:"is_public"`
+	SurpriseMode bool         `json:"surprise_mode"`
+	Occasion     string       `json:"occasion"`
+	EventDate    *domain.Date `json:"event_date"`
+	Recurrence   string       `json:"recurrence"`
+	Timezone     string       `json:"timezone"`
+}
+
+func (r *wishListRequest) toWishList() *domain.WishList {
+	return &domain.WishList{
+		Name:         r.Name,
+		Description:  r.Description,
+		Status:       r.Status,
+		IsPublic:     r.IsPublic,
+		SurpriseMode: r.SurpriseMode,
+		Occasion:     r.Occasion,
+		EventDate:    r.EventDate,
+		Recurrence:   r.Recurrence,
+		Timezone:     r.Timezone,
+	}
+}
+
+// itemRequest is the body accepted when adding or replacing a wish item.
+// Title is accepted as an alias of Name for older clients.
+type itemRequest struct {
+	domain.WishItem
+	Title string `json:"title"`
+}
+
+// toItem returns the item described by the request with defaults applied.
+// Images are kept in the order they were sent; details only known for
+// uploaded images are dropped. The price check time is cleared so that an
+// edited item is checked again soon. Positions are only changed through
+// ReorderItems and tags through the tag endpoints; timestamps are never
+// taken from the client.
+func (r *itemRequest) toItem() *domain.WishItem {
+	item := r.WishItem
+	if item.Name == "" {
+		item.Name = strings.TrimSpace(r.Title)
+	}
+	item.URL = strings.TrimSpace(item.URL)
+	item.Currency = strings.ToUpper(strings.TrimSpace(item.Currency))
+	if item.Quantity == 0 {
+		item.Quantity = 1
+	}
+	item.PriceCheckedAt = nil
+	item.Position = 0
+	item.Tags = nil
+	item.CreatedAt = time.Time{}
+	item.UpdatedAt = time.Time{}
+	for i := range item.Images {
+		item.Images[i] = domain.ItemImage{URL: item.Images[i].URL, Position: i}
+	}
+	return &item
+}
+
 func NewWishListHandler(service *service.WishListService) *WishListHandler {
 	return &WishListHandler{service: service}
 }
 
 func (h *WishListHandler) Create(c *gin.Context) {
-	var wishlist domain.WishList
-	if err := c.ShouldBindJSON(&wishlist); err != nil {
+	var req wishListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Get user ID from context (set by auth middleware)
 	userID := c.GetUint("user_id")
+	wishlist := req.toWishList()
 	wishlist.UserID = userID
 
-	if err := h.service.Create(&wishlist); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Create(wishlist); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusCreated, wishlist)
 }
 
+// List returns a page of the wishlists the user can access, optionally
+// only those with the tags or the status given in the query.
 func (h *WishListHandler) List(c *gin.Context) {
+	tags, ok := tagFilter(c)
+	if !ok {
+		return
+	}
+	paging, ok := pageQuery(c)
+	if !ok {
+		return
+	}
+
 	userID := c.GetUint("user_id")
-	wishlists, err := h.service.GetByUserID(userID)
+	page, err := h.service.GetByUserID(userID, domain.WishListQuery{Tags: tags, Status: c.Query("status"), Page: paging})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, wishlists)
+	setPageHeaders(c, page.Next, page.Prev, page.Total)
+	if page.WishLists == nil {
+		page.WishLists = []*domain.WishList{}
+	}
+	c.JSON(http.StatusOK, page.WishLists)
 }
 
 func (h *WishListHandler) Get(c *gin.Context) {
@@ -57,7 +138,7 @@ func (h *WishListHandler) Get(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	wishlist, err := h.service.GetByID(uint(id), userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -71,18 +152,19 @@ func (h *WishListHandler) Update(c *gin.Context) {
 		return
 	}
 
-	var wishlist domain.WishList
-	if err := c.ShouldBindJSON(&wishlist); err != nil {
+	var req wishListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Set the ID from the URL
+	wishlist := req.toWishList()
 	wishlist.ID = uint(id)
 
 	userID := c.GetUint("user_id")
-	if err := h.service.Update(&wishlist, userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Update(wishlist, userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -98,7 +180,7 @@ func (h *WishListHandler) Delete(c *gin.Context) {
 
 	userID := c.GetUint("user_id")
 	if err := h.service.Delete(uint(id), userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -112,8 +194,14 @@ func (h *WishListHandler) AddItem(c *gin.Context) {
 		return
 	}
 
-	var item domain.WishItem
-	if err := c.ShouldBindJSON(&item); err != nil {
+	var req itemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item := req.toItem()
+	if err := validation.ValidateWishItem(item); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -121,8 +209,8 @@ func (h *WishListHandler) AddItem(c *gin.Context) {
 	item.WishListID = uint(wishlistID)
 	userID := c.GetUint("user_id")
 
-	if err := h.service.AddItem(&item, userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.AddItem(item, userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -142,8 +230,14 @@ func (h *WishListHandler) UpdateItem(c *gin.Context) {
 		return
 	}
 
-	var item domain.WishItem
-	if err := c.ShouldBindJSON(&item); err != nil {
+	var req itemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item := req.toItem()
+	if err := validation.ValidateWishItem(item); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -152,14 +246,46 @@ func (h *WishListHandler) UpdateItem(c *gin.Context) {
 	item.WishListID = uint(wishlistID)
 	userID := c.GetUint("user_id")
 
-	if err := h.service.UpdateItem(&item, userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.UpdateItem(item, userID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, item)
 }
 
+// ListItems returns a page of the items of a wishlist, optionally only
+// those with the tags or the status given in the query.
+func (h *WishListHandler) ListItems(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return
+	}
+
+	tags, ok := tagFilter(c)
+	if !ok {
+		return
+	}
+	paging, ok := pageQuery(c)
+	if !ok {
+		return
+	}
+
+	query := domain.ItemQuery{Tags: tags, Status: c.Query("status"), Page: paging}
+	page, err := h.service.ListItems(uint(wishlistID), query, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	setPageHeaders(c, page.Next, page.Prev, page.Total)
+	if page.Items == nil {
+		page.Items = []*domain.WishItem{}
+	}
+	c.JSON(http.StatusOK, page.Items)
+}
+
 func (h *WishListHandler) DeleteItem(c *gin.Context) {
 	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -175,9 +301,48 @@ func (h *WishListHandler) DeleteItem(c *gin.Context) {
 
 	userID := c.GetUint("user_id")
 	if err := h.service.DeleteItem(uint(wishlistID), uint(itemID), userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
-} 
\ No newline at end of file
+}
+
+// reorderItemsRequest moves ItemIDs, in this order, right after AfterID, or
+// to the top of the list when AfterID is omitted.
+type reorderItemsRequest struct {
+	ItemIDs []uint `json:"item_ids" binding:"required,min=1"`
+	AfterID uint   `json:"after_id"`
+}
+
+type itemPosition struct {
+	ID       uint  `json:"id"`
+	Position int64 `json:"position"`
+}
+
+func (h *WishListHandler) ReorderItems(c *gin.Context) {
+	wishlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wishlist id"})
+		return
+	}
+
+	var req reorderItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := h.service.ReorderItems(uint(wishlistID), req.ItemIDs, req.AfterID, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	order := make([]itemPosition, 0, len(items))
+	for _, item := range items {
+		order = append(order, itemPosition{ID: item.ID, Position: item.Position})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": order})
+}
@@ -26,15 +26,17 @@ func setupWishListTestRouter(t *testing.T) (*gin.Engine, *WishListHandler, strin
 	userRepo := repository.NewUserRepository(db)
 	wishListRepo := repository.NewWishListRepository(db)
 
-	userService := service.NewUserService(userRepo)
-	wishListService := service.NewWishListService(wishListRepo)
+	userService := service.NewUserService(userRepo, nil)
+	wishListService := service.NewWishListService(wishListRepo, service.NewAccessPolicy(repository.NewCollaboratorRepository(db), nil))
 	jwtManager := auth.NewJWTManager("test-secret", 24*time.Hour)
 
 	// Register a test user and get token
 	user, err := userService.Register("test@example.com", "password123")
 	require.NoError(t, err)
-	token, err := jwtManager.GenerateToken(user.ID)
+	tokenService := service.NewTokenService(repository.NewTokenRepository(db), jwtManager, service.DefaultRefreshTokenTTL)
+	pair, err := tokenService.Issue(user.ID, service.ClientInfo{})
 	require.NoError(t, err)
+	token := pair.AccessToken
 
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
@@ -153,6 +155,60 @@ func TestWishListHandler(t *testing.T) {
 		assert.Equal(t, "wanted", item["status"])
 	})
 
+	t.Run("add item with product details", func(t *testing.T) {
+		reqBody := map[string]interface{}{
+			"title":    "Headphones",
+			"url":      "https://shop.example.com/headphones",
+			"price":    "149.90",
+			"currency": "eur",
+			"quantity": 2,
+			"images": []map[string]string{
+				{"url": "https://shop.example.com/front.jpg"},
+				{"url": "https://shop.example.com/back.jpg"},
+			},
+		}
+		jsonBody, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest("POST", id+"/items", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var item map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &item)
+		require.NoError(t, err)
+		assert.Equal(t, "Headphones", item["name"])
+		assert.Equal(t, "https://shop.example.com/headphones", item["url"])
+		assert.Equal(t, 149.9, item["price"])
+		assert.Equal(t, "EUR", item["currency"])
+		assert.Equal(t, float64(2), item["quantity"])
+		images := item["images"].([]interface{})
+		require.Len(t, images, 2)
+		assert.Equal(t, float64(1), images[1].(map[string]interface{})["position"])
+	})
+
+	t.Run("reject invalid item", func(t *testing.T) {
+		for _, reqBody := range []map[string]interface{}{
+			{"description": "no name"},
+			{"name": "Book", "price": 12.5},
+			{"name": "Book", "price": 12.5, "currency": "XYZ"},
+			{"name": "Book", "price": 1.5, "currency": "JPY"},
+			{"name": "Book", "url": "javascript:alert(1)"},
+			{"name": "Book", "quantity": -1},
+		} {
+			jsonBody, _ := json.Marshal(reqBody)
+			req := httptest.NewRequest("POST", id+"/items", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, reqBody)
+		}
+	})
+
 	t.Run("delete wishlist", func(t *testing.T) {
 		req := httptest.NewRequest("DELETE", id, nil)
 		req.Header.Set("Authorization", "Bearer "+token)
@@ -170,3 +226,16 @@ func TestWishListHandler(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 }
+
+func TestItemRequestToItem(t *testing.T) {
+	var req itemRequest
+	body := `{"title":"Lamp","position":5,"created_at":"2001-02-03T04:05:06Z","updated_at":"2001-02-03T04:05:06Z"}`
+	require.NoError(t, json.Unmarshal([]byte(body), &req))
+
+	item := req.toItem()
+	assert.Equal(t, "Lamp", item.Name)
+	assert.Zero(t, item.Position)
+	assert.True(t, item.CreatedAt.IsZero())
+	assert.True(t, item.UpdatedAt.IsZero())
+	assert.Equal(t, 1, item.Quantity)
+}
@@ -1,19 +1,32 @@
 package middleware
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
+	"wishlist/internal/auth"
+	"wishlist/internal/domain"
 )
 
-func Auth(jwtSecret string) gin.HandlerFunc {
+// SessionChecker reports whether the session an access token belongs to has
+// been revoked, e.g. by logging out.
+type SessionChecker interface {
+	IsRevoked(sessionID string) (bool, error)
+}
+
+// PersonalTokenAuthenticator resolves personal access tokens.
+type PersonalTokenAuthenticator interface {
+	Authenticate(token string) (*domain.PersonalAccessToken, error)
+}
+
+// Auth accepts either a JWT access token or a personal access token as the
+// bearer token. personalTokens may be nil to accept JWTs only.
+func Auth(jwtManager *auth.JWTManager, sessions SessionChecker, personalTokens PersonalTokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
-		fmt.Println("Auth header:", authHeader)
-		
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
 			c.Abort()
@@ -27,29 +40,137 @@ func Auth(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(parts[1], func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(jwtSecret), nil
-		})
+		if personalTokens != nil && strings.HasPrefix(parts[1], domain.PersonalAccessTokenPrefix) {
+			authenticatePersonalToken(c, personalTokens, parts[1])
+			return
+		}
 
+		claims, err := jwtManager.Parse(parts[1])
 		if err != nil {
-			fmt.Println("JWT parse error:", err)
+			message := "invalid token"
+			if errors.Is(err, auth.ErrExpiredToken) {
+				message = err.Error()
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+
+		// Every access token belongs to a session, which can be revoked
+		if claims.SessionID == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			c.Abort()
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			userID := uint(claims["user_id"].(float64))
-			fmt.Println("Authenticated user ID:", userID)
-			c.Set("user_id", userID)
-			c.Next()
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
+		revoked, err := sessions.IsRevoked(claims.SessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify session"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session has been revoked"})
+			c.Abort()
+			return
+		}
+		c.Set("session_id", claims.SessionID)
+
+		c.Set("user_id", claims.UserID)
+		c.Next()
+	}
+}
+
+// authenticatePersonalToken authenticates the request with a personal
+// access token and enforces its scope: read-only tokens may only use safe
+// methods, and wishlist-scoped tokens only reach routes of that wishlist.
+func authenticatePersonalToken(c *gin.Context, personalTokens PersonalTokenAuthenticator, plain string) {
+	token, err := personalTokens.Authenticate(plain)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		c.Abort()
+		return
+	}
+
+	if !token.AllowsWrite() && !isSafeMethod(c.Request.Method) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "token is read-only"})
+		c.Abort()
+		return
+	}
+
+	if token.WishListID != nil && !targetsWishList(c, *token.WishListID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "token is limited to another wishlist"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", token.UserID)
+	c.Set("personal_token", token)
+	c.Next()
+}
+
+// AdminChecker tells whether a user is an administrator.
+type AdminChecker interface {
+	IsAdmin(userID uint) (bool, error)
+}
+
+// RequireAdmin only lets administrators through. It must run after Auth.
+// The role is looked up on every request so that revoking it takes effect
+// immediately.
+func RequireAdmin(admins AdminChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		isAdmin, err := admins.IsAdmin(c.GetUint("user_id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
 			c.Abort()
 			return
 		}
+
+		if !isAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// SessionOnly rejects requests authenticated with a personal access token.
+// It guards account settings, which scripts must not be able to change.
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("personal_token"); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "this endpoint requires a login session"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// OptionalAuth sets user_id when a valid bearer token is present but lets
+// anonymous requests through. A malformed or invalid token is still rejected
+// so that clients notice expired sessions.
+func OptionalAuth(jwtManager *auth.JWTManager, sessions SessionChecker, personalTokens PersonalTokenAuthenticator) gin.HandlerFunc {
+	authenticate := Auth(jwtManager, sessions, personalTokens)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// targetsWishList reports whether the matched route addresses the wishlist
+// through its :id parameter.
+func targetsWishList(c *gin.Context, wishlistID uint) bool {
+	if !strings.Contains(c.FullPath(), "/wishlists/:id") {
+		return false
 	}
-} 
\ No newline at end of file
+	return c.Param("id") == strconv.FormatUint(uint64(wishlistID), 10)
+}
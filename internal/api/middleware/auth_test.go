@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"wishlist/internal/auth"
+	"wishlist/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSessions struct{}
+
+func (stubSessions) IsRevoked(sessionID string) (bool, error) {
+	return false, nil
+}
+
+type stubPersonalTokens map[string]*domain.PersonalAccessToken
+
+func (s stubPersonalTokens) Authenticate(token string) (*domain.PersonalAccessToken, error) {
+	if pat, ok := s[token]; ok {
+		return pat, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+func TestAuth_PersonalAccessTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	wishlistID := uint(7)
+	tokens := stubPersonalTokens{
+		"wl_pat_read":   {UserID: 1, Scope: domain.ScopeRead},
+		"wl_pat_write":  {UserID: 1, Scope: domain.ScopeWrite},
+		"wl_pat_scoped": {UserID: 1, Scope: domain.ScopeWrite, WishListID: &wishlistID},
+	}
+	jwtManager := auth.NewJWTManager("test-secret", time.Hour)
+
+	r := gin.New()
+	api := r.Group("/api", Auth(jwtManager, stubSessions{}, tokens))
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint("user_id")})
+	}
+	api.GET("/wishlists", ok)
+	api.GET("/wishlists/:id", ok)
+	api.PUT("/wishlists/:id", ok)
+	api.GET("/me/tokens", SessionOnly(), ok)
+
+	jwt, _, err := jwtManager.Issue(1, "session", "")
+	require.NoError(t, err)
+	sessionless, _, err := jwtManager.Issue(1, "", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{"read token reads", http.MethodGet, "/api/wishlists", "wl_pat_read", http.StatusOK},
+		{"read token cannot write", http.MethodPut, "/api/wishlists/7", "wl_pat_read", http.StatusForbidden},
+		{"write token writes", http.MethodPut, "/api/wishlists/7", "wl_pat_write", http.StatusOK},
+		{"scoped token on its wishlist", http.MethodPut, "/api/wishlists/7", "wl_pat_scoped", http.StatusOK},
+		{"scoped token on another wishlist", http.MethodGet, "/api/wishlists/8", "wl_pat_scoped", http.StatusForbidden},
+		{"scoped token cannot list", http.MethodGet, "/api/wishlists", "wl_pat_scoped", http.StatusForbidden},
+		{"unknown token", http.MethodGet, "/api/wishlists", "wl_pat_unknown", http.StatusUnauthorized},
+		{"token cannot manage account", http.MethodGet, "/api/me/tokens", "wl_pat_write", http.StatusForbidden},
+		{"jwt manages account", http.MethodGet, "/api/me/tokens", jwt, http.StatusOK},
+		{"jwt without session", http.MethodGet, "/api/wishlists", sessionless, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+type stubAdmins map[uint]bool
+
+func (s stubAdmins) IsAdmin(userID uint) (bool, error) {
+	return s[userID], nil
+}
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtManager := auth.NewJWTManager("test-secret", time.Hour)
+	r := gin.New()
+	r.GET("/admin", Auth(jwtManager, stubSessions{}, nil), RequireAdmin(stubAdmins{1: true}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for userID, status := range map[uint]int{1: http.StatusOK, 2: http.StatusForbidden} {
+		token, _, err := jwtManager.Issue(userID, "session", "")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, "user %d", userID)
+	}
+}
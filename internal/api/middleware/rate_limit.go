@@ -1,15 +1,39 @@
 package middleware
 
 import (
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
+	"wishlist/internal/domain"
+	"wishlist/internal/ratelimit"
 )
 
-func RateLimit(limiter *rate.Limiter) gin.HandlerFunc {
+// RateLimit limits requests per client. Clients are told apart by personal
+// access token, then by user and otherwise by IP address, so it has to run
+// after the auth middleware for the first two to apply. The client IP
+// honours X-Forwarded-For only from the router's trusted proxies.
+func RateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !limiter.Allow() {
+		result, err := limiter.Allow(c.Request.Context(), rateLimitKey(c))
+		if err != nil {
+			// Keep serving when the store is unavailable rather than
+			// turning every request away.
+			log.Printf("Rate limiter %s failed: %v", limiter.Policy().Name, err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", seconds(result.Reset))
+
+		if !result.Allowed {
+			c.Header("Retry-After", seconds(result.RetryAfter))
 			c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
 			c.Abort()
 			return
@@ -17,3 +41,21 @@ func RateLimit(limiter *rate.Limiter) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func rateLimitKey(c *gin.Context) string {
+	if value, ok := c.Get("personal_token"); ok {
+		if token, ok := value.(*domain.PersonalAccessToken); ok {
+			return fmt.Sprintf("token:%d", token.ID)
+		}
+	}
+	if userID := c.GetUint("user_id"); userID != 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// seconds formats a duration as whole seconds, rounded up so that clients
+// do not retry too early.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(),
+		ratelimit.Policy{Name: "test", Requests: 1, Per: time.Minute, Burst: 2})
+
+	r := gin.New()
+	identify := func(c *gin.Context) {
+		switch c.GetHeader("X-Test-User") {
+		case "1":
+			c.Set("user_id", uint(1))
+		case "token":
+			c.Set("user_id", uint(1))
+			c.Set("personal_token", &domain.PersonalAccessToken{ID: 9, UserID: 1})
+		}
+	}
+	r.GET("/", identify, RateLimit(limiter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(user, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+	assert.Equal(t, http.StatusOK, request("", "10.0.0.1").Code)
+
+	w = request("", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	// Other clients are not affected by the noisy one.
+	assert.Equal(t, http.StatusOK, request("", "10.0.0.2").Code)
+
+	// A user is limited on their own budget, wherever they come from, and
+	// each of their access tokens has another.
+	assert.Equal(t, http.StatusOK, request("1", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusOK, request("1", "10.0.0.3").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("1", "10.0.0.4").Code)
+	assert.Equal(t, http.StatusOK, request("token", "10.0.0.4").Code)
+}
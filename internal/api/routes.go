@@ -0,0 +1,189 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"wishlist/internal/api/handlers"
+	"wishlist/internal/api/middleware"
+)
+
+// Handlers groups the HTTP handlers served by the API.
+type Handlers struct {
+	Auth          *handlers.AuthHandler
+	WishList      *handlers.WishListHandler
+	Reservation   *handlers.ReservationHandler
+	Collaboration *handlers.CollaborationHandler
+	PasswordReset *handlers.PasswordResetHandler
+	Verification  *handlers.EmailVerificationHandler
+	MFA           *handlers.MFAHandler
+	AccessTokens  *handlers.PersonalAccessTokenHandler
+	OIDC          *handlers.OIDCHandler
+	Sessions      *handlers.SessionHandler
+	Profile       *handlers.ProfileHandler
+	Account       *handlers.AccountHandler
+	Admin         *handlers.AdminHandler
+	ItemPreview   *handlers.ItemPreviewHandler
+	Prices        *handlers.PriceHandler
+	ItemImages    *handlers.ItemImageHandler
+	Tags          *handlers.TagHandler
+	Events        *handlers.EventHandler
+	Calendar      *handlers.CalendarHandler
+}
+
+// RateLimits are the rate limiting middlewares of the route groups. Auth
+// applies to the /auth endpoints, which are attractive to brute force, and
+// API to everything else.
+type RateLimits struct {
+	API  gin.HandlerFunc
+	Auth gin.HandlerFunc
+}
+
+// RegisterRoutes mounts the API under the given group so that every
+// entrypoint serves the same set of endpoints. auth guards protected routes;
+// optionalAuth identifies the user on public routes when a token is sent;
+// requireAdmin additionally guards the admin API.
+func RegisterRoutes(base *gin.RouterGroup, auth, optionalAuth, requireAdmin gin.HandlerFunc, limits RateLimits, h *Handlers) {
+	// Auth routes
+	authRoutes := base.Group("/auth")
+	authRoutes.Use(limits.Auth)
+	{
+		authRoutes.POST("/register", h.Auth.Register)
+		authRoutes.POST("/login", h.Auth.Login)
+		authRoutes.POST("/login/mfa", h.MFA.Login)
+		authRoutes.POST("/unlock", h.Auth.Unlock)
+		authRoutes.POST("/refresh", h.Auth.Refresh)
+		authRoutes.POST("/logout", auth, h.Auth.Logout)
+		authRoutes.POST("/password/forgot", h.PasswordReset.Forgot)
+		authRoutes.POST("/password/reset", h.PasswordReset.Reset)
+		authRoutes.POST("/email/verify", h.Verification.Verify)
+		authRoutes.POST("/email/resend", auth, h.Verification.Resend)
+
+		// Login with external identity providers
+		authRoutes.GET("/oidc/providers", h.OIDC.Providers)
+		authRoutes.POST("/oidc/:provider/authorize", h.OIDC.Authorize)
+		authRoutes.POST("/oidc/:provider/callback", h.OIDC.Callback)
+
+		// Two-factor authentication
+		mfa := authRoutes.Group("/2fa")
+		mfa.Use(auth, middleware.SessionOnly())
+		{
+			mfa.POST("/setup", h.MFA.Setup)
+			mfa.POST("/enable", h.MFA.Enable)
+			mfa.POST("/disable", h.MFA.Disable)
+			mfa.POST("/recovery-codes", h.MFA.RegenerateRecoveryCodes)
+		}
+	}
+
+	// Public shared wishlists
+	shared := base.Group("/shared-wishlists")
+	shared.Use(optionalAuth, limits.API)
+	{
+		shared.GET("/:shareCode", h.WishList.GetShared)
+		shared.POST("/:shareCode/items/:itemId/reservation", h.Reservation.Claim)
+		shared.DELETE("/:shareCode/items/:itemId/reservation", h.Reservation.Unclaim)
+	}
+
+	// Calendar feeds, authenticated by the secret token in the URL
+	calendar := base.Group("/calendar")
+	calendar.Use(limits.API)
+	{
+		calendar.GET("/:token", h.Calendar.Serve)
+		calendar.HEAD("/:token", h.Calendar.Serve)
+	}
+
+	// Protected routes
+	protected := base.Group("")
+	protected.Use(auth, limits.API)
+	{
+		// Wishlist routes
+		wishlists := protected.Group("/wishlists")
+		{
+			wishlists.POST("", h.WishList.Create)
+			wishlists.GET("", h.WishList.List)
+			wishlists.GET("/:id", h.WishList.Get)
+			wishlists.PUT("/:id", h.WishList.Update)
+			wishlists.DELETE("/:id", h.WishList.Delete)
+			wishlists.POST("/:id/share-code", h.WishList.GenerateShareCode)
+			wishlists.DELETE("/:id/share-code", h.WishList.RevokeShareCode)
+			wishlists.PUT("/:id/share-settings", h.WishList.UpdateShareSettings)
+			wishlists.PUT("/:id/tags", h.Tags.SetWishListTags)
+
+			// Wishlist items routes
+			wishlists.GET("/:id/items", h.WishList.ListItems)
+			wishlists.POST("/:id/items", h.WishList.AddItem)
+			wishlists.PUT("/:id/items/order", h.WishList.ReorderItems)
+			wishlists.PUT("/:id/items/:itemId", h.WishList.UpdateItem)
+			wishlists.DELETE("/:id/items/:itemId", h.WishList.DeleteItem)
+			wishlists.GET("/:id/items/:itemId/price-history", h.Prices.History)
+			wishlists.POST("/:id/items/:itemId/images", h.ItemImages.Upload)
+			wishlists.DELETE("/:id/items/:itemId/images/:imageId", h.ItemImages.Delete)
+			wishlists.PUT("/:id/items/:itemId/tags", h.Tags.SetItemTags)
+
+			// Collaboration routes
+			wishlists.GET("/:id/collaborators", h.Collaboration.ListCollaborators)
+			wishlists.PUT("/:id/collaborators/:userId", h.Collaboration.UpdateRole)
+			wishlists.DELETE("/:id/collaborators/:userId", h.Collaboration.RemoveCollaborator)
+			wishlists.POST("/:id/invitations", h.Collaboration.Invite)
+			wishlists.GET("/:id/invitations", h.Collaboration.ListInvitations)
+			wishlists.DELETE("/:id/invitations/:invitationId", h.Collaboration.RevokeInvitation)
+		}
+
+		// Tags of the current user
+		tags := protected.Group("/tags")
+		{
+			tags.GET("", h.Tags.List)
+			tags.POST("", h.Tags.Create)
+			tags.PATCH("/:tagId", h.Tags.Rename)
+			tags.POST("/:tagId/merge", h.Tags.Merge)
+			tags.DELETE("/:tagId", h.Tags.Delete)
+		}
+
+		// Events of the wishlists of the current user and their friends
+		protected.GET("/events/upcoming", h.Events.Upcoming)
+
+		// Details of a product page for pre-filling a new item
+		protected.POST("/items/preview", h.ItemPreview.Preview)
+
+		// Account settings of the current user
+		me := protected.Group("/me")
+		me.Use(middleware.SessionOnly())
+		{
+			me.GET("", h.Profile.Get)
+			me.PATCH("", h.Profile.Update)
+			me.POST("/email", h.Profile.ChangeEmail)
+			me.POST("/password", h.Profile.ChangePassword)
+			me.GET("/export", h.Account.Export)
+			me.DELETE("", h.Account.Delete)
+			me.DELETE("/deletion", h.Account.CancelDeletion)
+			me.GET("/tokens", h.AccessTokens.List)
+			me.POST("/tokens", h.AccessTokens.Create)
+			me.DELETE("/tokens/:tokenId", h.AccessTokens.Revoke)
+			me.GET("/sessions", h.Sessions.List)
+			me.DELETE("/sessions", h.Sessions.RevokeOthers)
+			me.DELETE("/sessions/:sessionId", h.Sessions.Revoke)
+			me.GET("/calendar-feed", h.Calendar.Feed)
+			me.POST("/calendar-feed", h.Calendar.CreateFeed)
+			me.DELETE("/calendar-feed", h.Calendar.RevokeFeed)
+		}
+
+		// Administration
+		admin := protected.Group("/admin")
+		admin.Use(middleware.SessionOnly(), requireAdmin)
+		{
+			admin.GET("/stats", h.Admin.Stats)
+			admin.GET("/users", h.Admin.ListUsers)
+			admin.GET("/users/:userId", h.Admin.GetUser)
+			admin.GET("/users/:userId/wishlists", h.Admin.UserWishLists)
+			admin.PUT("/users/:userId/status", h.Admin.UpdateStatus)
+			admin.PUT("/users/:userId/role", h.Admin.UpdateRole)
+			admin.POST("/users/:userId/password-reset", h.Admin.ForcePasswordReset)
+		}
+
+		// Invitations addressed to the current user
+		invitations := protected.Group("/invitations")
+		{
+			invitations.GET("", h.Collaboration.MyInvitations)
+			invitations.POST("/:id/accept", h.Collaboration.AcceptInvitation)
+			invitations.POST("/:id/decline", h.Collaboration.DeclineInvitation)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"wishlist/internal/config"
+)
+
+// DefaultTokenDuration applies when JWT_DURATION is not configured. Access
+// tokens are short-lived and renewed with refresh tokens.
+const DefaultTokenDuration = 15 * time.Minute
+
+// NewJWTManagerFromConfig builds the token manager from the JWT settings.
+func NewJWTManagerFromConfig(cfg *config.Config) (*JWTManager, error) {
+	duration := config.ParseDuration(cfg.JWTExpiry, DefaultTokenDuration)
+
+	var current *SigningKey
+	switch strings.ToUpper(cfg.JWTAlgorithm) {
+	case "", "HS256":
+		if cfg.JWTSecret == "" {
+			return nil, fmt.Errorf("JWT_SECRET is required for HS256")
+		}
+		current = NewHMACKey(cfg.JWTKeyID, cfg.JWTSecret)
+	case "RS256", "EDDSA":
+		if cfg.JWTPrivateKeyFile == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for %s", cfg.JWTAlgorithm)
+		}
+		key, err := LoadKey(cfg.JWTKeyID, cfg.JWTPrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if !key.CanSign() {
+			return nil, fmt.Errorf("%s does not contain a private key", cfg.JWTPrivateKeyFile)
+		}
+		if !strings.EqualFold(key.Method.Alg(), cfg.JWTAlgorithm) {
+			return nil, fmt.Errorf("key in %s does not match algorithm %s", cfg.JWTPrivateKeyFile, cfg.JWTAlgorithm)
+		}
+		current = key
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.JWTAlgorithm)
+	}
+
+	previous, err := parsePreviousKeys(cfg.JWTPreviousKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewJWTManagerWithKeys(current, previous, duration), nil
+}
+
+func parsePreviousKeys(value string) ([]*SigningKey, error) {
+	var keys []*SigningKey
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid JWT_PREVIOUS_KEYS entry %q, expected kid=path", entry)
+		}
+
+		key, err := LoadKey(kid, path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
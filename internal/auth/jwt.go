@@ -1,7 +1,10 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,55 +15,177 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// JWTManager issues and validates access tokens. Tokens are signed with the
+// current key and carry its ID in the kid header; previous keys are kept for
+// verification only, so keys can be rotated without logging everyone out.
 type JWTManager struct {
-	secretKey     []byte
+	current       *SigningKey
+	keys          map[string]*SigningKey
 	tokenDuration time.Duration
 }
 
+// NewJWTManager creates a manager that signs tokens with an HS256 secret.
 func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
+	return NewJWTManagerWithKeys(NewHMACKey("", secretKey), nil, tokenDuration)
+}
+
+// NewJWTManagerWithKeys creates a manager that signs with current and also
+// accepts tokens signed by any of the previous keys.
+func NewJWTManagerWithKeys(current *SigningKey, previous []*SigningKey, tokenDuration time.Duration) *JWTManager {
+	keys := make(map[string]*SigningKey, len(previous)+1)
+	for _, key := range previous {
+		keys[key.ID] = key
+	}
+	keys[current.ID] = current
+
 	return &JWTManager{
-		secretKey:     []byte(secretKey),
+		current:       current,
+		keys:          keys,
 		tokenDuration: tokenDuration,
 	}
 }
 
+// PurposeMFA marks the short-lived token handed out between the password and
+// the second factor of a two-step login.
+const PurposeMFA = "mfa"
+
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"sid,omitempty"`
+	// Purpose is empty for access tokens. Tokens with a purpose are only
+	// accepted by ParsePurpose, never as access tokens.
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func (m *JWTManager) GenerateToken(userID uint) (string, error) {
+// TokenDuration returns how long issued tokens stay valid.
+func (m *JWTManager) TokenDuration() time.Duration {
+	return m.tokenDuration
+}
+
+// Issue signs a token for the user bound to the given session. The token ID
+// (jti) is optional.
+func (m *JWTManager) Issue(userID uint, sessionID, tokenID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.tokenDuration)
+
 	claims := Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.tokenDuration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secretKey)
+	signed, err := m.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
+}
+
+// IssuePurpose signs a token that is only good for the given purpose, such
+// as PurposeMFA, and expires after ttl.
+func (m *JWTManager) IssuePurpose(userID uint, purpose string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	// A random ID tells apart tokens issued within the same second, so that
+	// state kept per token, such as failed attempts, is not shared.
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", time.Time{}, err
+	}
+
+	claims := Claims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(id),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	signed, err := m.sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
+}
+
+func (m *JWTManager) sign(claims Claims) (string, error) {
+	token := jwt.NewWithClaims(m.current.Method, claims)
+	if m.current.ID != "" {
+		token.Header["kid"] = m.current.ID
+	}
+
+	return token.SignedString(m.current.signingKey())
 }
 
 func (m *JWTManager) ValidateToken(tokenString string) (uint, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
-		}
-		return m.secretKey, nil
-	})
+	claims, err := m.Parse(tokenString)
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID, nil
+}
+
+// Parse validates an access token and returns its claims.
+func (m *JWTManager) Parse(tokenString string) (*Claims, error) {
+	return m.ParsePurpose(tokenString, "")
+}
 
+// ParsePurpose validates a token issued for the purpose and returns its
+// claims.
+func (m *JWTManager) ParsePurpose(tokenString, purpose string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.keyFor)
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
-			return 0, ErrExpiredToken
+			return nil, ErrExpiredToken
 		}
-		return 0, ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
-		return 0, ErrInvalidToken
+	if !ok || !token.Valid || claims.Purpose != purpose {
+		return nil, ErrInvalidToken
 	}
 
-	return claims.UserID, nil
-} 
\ No newline at end of file
+	return claims, nil
+}
+
+// JWKS returns the public keys that verify tokens issued by this manager.
+func (m *JWTManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range m.keys {
+		if jwk, ok := key.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].KeyID < set.Keys[j].KeyID
+	})
+	return set
+}
+
+// keyFor picks the verification key by kid and refuses any algorithm other
+// than the one the key was created for.
+func (m *JWTManager) keyFor(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+
+	return key.verificationKey(), nil
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rsaKey(t *testing.T, id string) *SigningKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+
+	key, err := ParseKey(id, pemBytes)
+	require.NoError(t, err)
+	return key
+}
+
+func ed25519Key(t *testing.T, id string) *SigningKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	key, err := ParseKey(id, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	return key
+}
+
+func TestJWTManager_HS256(t *testing.T) {
+	manager := NewJWTManager("secret", time.Hour)
+
+	token, _, err := manager.Issue(42, "session", "")
+	require.NoError(t, err)
+
+	userID, err := manager.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), userID)
+
+	_, err = NewJWTManager("other-secret", time.Hour).ValidateToken(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Shared secrets must never be published
+	assert.Empty(t, manager.JWKS().Keys)
+}
+
+func TestJWTManager_Expired(t *testing.T) {
+	manager := NewJWTManager("secret", -time.Minute)
+
+	token, _, err := manager.Issue(1, "session", "")
+	require.NoError(t, err)
+
+	_, err = manager.ValidateToken(token)
+	assert.ErrorIs(t, err, ErrExpiredToken)
+}
+
+func TestJWTManager_AsymmetricKeys(t *testing.T) {
+	for _, key := range []*SigningKey{rsaKey(t, "rsa-1"), ed25519Key(t, "ed-1")} {
+		t.Run(key.Method.Alg(), func(t *testing.T) {
+			manager := NewJWTManagerWithKeys(key, nil, time.Hour)
+
+			token, _, err := manager.Issue(7, "session", "token-id")
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+
+			claims, err := manager.Parse(token)
+			require.NoError(t, err)
+			assert.Equal(t, uint(7), claims.UserID)
+			assert.Equal(t, "session", claims.SessionID)
+			assert.Equal(t, "token-id", claims.ID)
+
+			jwks := manager.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, key.ID, jwks.Keys[0].KeyID)
+			assert.Equal(t, key.Method.Alg(), jwks.Keys[0].Algorithm)
+		})
+	}
+}
+
+func TestJWTManager_KeyRotation(t *testing.T) {
+	oldKey := rsaKey(t, "2024")
+	newKey := ed25519Key(t, "2025")
+
+	oldManager := NewJWTManagerWithKeys(oldKey, nil, time.Hour)
+	oldToken, _, err := oldManager.Issue(1, "session", "")
+	require.NoError(t, err)
+
+	rotated := NewJWTManagerWithKeys(newKey, []*SigningKey{oldKey}, time.Hour)
+
+	// Tokens signed before the rotation stay valid
+	userID, err := rotated.ValidateToken(oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), userID)
+
+	newToken, _, err := rotated.Issue(2, "session", "")
+	require.NoError(t, err)
+
+	// The old deployment does not know the new key
+	_, err = oldManager.ValidateToken(newToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	assert.Len(t, rotated.JWKS().Keys, 2)
+}
+
+func TestJWTManager_RejectsAlgorithmConfusion(t *testing.T) {
+	key := rsaKey(t, "rsa")
+	manager := NewJWTManagerWithKeys(key, nil, time.Hour)
+
+	// An HS256 token keyed with the public key must not pass as RS256
+	der, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+	require.NoError(t, err)
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: 1})
+	forged.Header["kid"] = key.ID
+	token, err := forged.SignedString(der)
+	require.NoError(t, err)
+
+	_, err = manager.Parse(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWTManager_PurposeTokens(t *testing.T) {
+	manager := NewJWTManager("test-secret", time.Hour)
+
+	challenge, _, err := manager.IssuePurpose(42, PurposeMFA, time.Minute)
+	require.NoError(t, err)
+
+	_, err = manager.Parse(challenge)
+	assert.ErrorIs(t, err, ErrInvalidToken, "challenge must not work as an access token")
+
+	claims, err := manager.ParsePurpose(challenge, PurposeMFA)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), claims.UserID)
+
+	access, _, err := manager.Issue(42, "session", "")
+	require.NoError(t, err)
+	_, err = manager.ParsePurpose(access, PurposeMFA)
+	assert.ErrorIs(t, err, ErrInvalidToken, "access token must not pass as a challenge")
+}
@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrUnsupportedKey = errors.New("unsupported signing key")
+
+// SigningKey is a key used to sign or verify tokens. HMAC keys hold the shared
+// secret in Secret; asymmetric keys hold the key pair.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	Secret     []byte
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// NewHMACKey creates an HS256 key from a shared secret.
+func NewHMACKey(id, secret string) *SigningKey {
+	return &SigningKey{
+		ID:     id,
+		Method: jwt.SigningMethodHS256,
+		Secret: []byte(secret),
+	}
+}
+
+// ParseKey builds a key from a PEM encoded RSA or Ed25519 private key, or
+// from a public key, which can then only verify tokens. The algorithm follows
+// from the key type: RS256 for RSA and EdDSA for Ed25519. An empty id is
+// replaced by the key's thumbprint.
+func ParseKey(id string, pemBytes []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key: %w", err)
+	}
+
+	var key *SigningKey
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key = &SigningKey{Method: jwt.SigningMethodRS256, PrivateKey: k, PublicKey: &k.PublicKey}
+	case *rsa.PublicKey:
+		key = &SigningKey{Method: jwt.SigningMethodRS256, PublicKey: k}
+	case ed25519.PrivateKey:
+		key = &SigningKey{Method: jwt.SigningMethodEdDSA, PrivateKey: k, PublicKey: k.Public()}
+	case ed25519.PublicKey:
+		key = &SigningKey{Method: jwt.SigningMethodEdDSA, PublicKey: k}
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	key.ID = id
+	if key.ID == "" {
+		key.ID = key.thumbprint()
+	}
+
+	return key, nil
+}
+
+// LoadKey reads a PEM encoded key from a file.
+func LoadKey(id, path string) (*SigningKey, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return ParseKey(id, pemBytes)
+}
+
+// CanSign reports whether the key can issue tokens, as opposed to a public
+// key that only verifies them.
+func (k *SigningKey) CanSign() bool {
+	return k.Secret != nil || k.PrivateKey != nil
+}
+
+func (k *SigningKey) signingKey() interface{} {
+	if k.Secret != nil {
+		return k.Secret
+	}
+	return k.PrivateKey
+}
+
+func (k *SigningKey) verificationKey() interface{} {
+	if k.Secret != nil {
+		return k.Secret
+	}
+	return k.PublicKey
+}
+
+// JWK is the public part of a signing key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 and EC
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWKSet is served so that other services can verify our tokens.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public key in JWK format. Shared HMAC secrets are never
+// published, so ok is false for them.
+func (k *SigningKey) JWK() (JWK, bool) {
+	switch pub := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType:   "RSA",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: k.Method.Alg(),
+			N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			KeyType:   "OKP",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: k.Method.Alg(),
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(pub),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
+
+// PublicKey decodes the key published in JWK format, e.g. by an OpenID
+// provider, together with the signing method it is used with.
+func (j JWK) PublicKey() (crypto.PublicKey, jwt.SigningMethod, error) {
+	switch j.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		method := jwt.GetSigningMethod(j.Algorithm)
+		if j.Algorithm == "" {
+			method = jwt.SigningMethodRS256
+		}
+		if _, ok := method.(*jwt.SigningMethodRSA); !ok {
+			return nil, nil, ErrUnsupportedKey
+		}
+		return key, method, nil
+	case "EC":
+		curve, method := ellipticCurve(j.Curve)
+		if curve == nil {
+			return nil, nil, ErrUnsupportedKey
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid EC point: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(j.Y)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid EC point: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, nil, fmt.Errorf("invalid EC point")
+		}
+		return key, method, nil
+	case "OKP":
+		if j.Curve != "Ed25519" {
+			return nil, nil, ErrUnsupportedKey
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, nil, ErrUnsupportedKey
+	}
+}
+
+func ellipticCurve(name string) (elliptic.Curve, jwt.SigningMethod) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), jwt.SigningMethodES256
+	case "P-384":
+		return elliptic.P384(), jwt.SigningMethodES384
+	case "P-521":
+		return elliptic.P521(), jwt.SigningMethodES512
+	default:
+		return nil, nil
+	}
+}
+
+// thumbprint derives a stable key ID from the public key.
+func (k *SigningKey) thumbprint() string {
+	der, err := x509.MarshalPKIXPublicKey(k.PublicKey)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 appendix B test vectors for SHA1.
+func TestHOTP_RFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, v := range vectors {
+		step := TOTPStep(time.Unix(v.unix, 0))
+		assert.Equal(t, v.code, hotp(key, uint64(step), 8), "time %d", v.unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	code, err := TOTPCode(secret, now)
+	require.NoError(t, err)
+	require.Len(t, code, TOTPDigits)
+
+	step, ok := ValidateTOTP(secret, code, now, 0)
+	require.True(t, ok)
+	assert.Equal(t, TOTPStep(now), step)
+
+	_, ok = ValidateTOTP(secret, code, now, step)
+	assert.False(t, ok, "code must not be accepted twice")
+
+	_, ok = ValidateTOTP(secret, code, now.Add(TOTPPeriod), 0)
+	assert.True(t, ok, "previous period is tolerated")
+
+	_, ok = ValidateTOTP(secret, code, now.Add(3*TOTPPeriod), 0)
+	assert.False(t, ok, "old codes expire")
+
+	_, ok = ValidateTOTP(secret, "000000x", now, 0)
+	assert.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Wishlist", "user@example.com", "JBSWY3DPEHPK3PXP")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Wishlist:user@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Wishlist")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
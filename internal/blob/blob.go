@@ -0,0 +1,80 @@
+// Package blob stores uploaded files such as item images.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"wishlist/internal/config"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Store keeps blobs under slash-separated keys such as
+// "items/12/3f9a.jpg". Blobs are public: URL returns the address clients
+// load them from. Implementations must be safe for concurrent use.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns ErrNotFound when there is no blob under the key.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes a blob; deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// NewFromConfig returns the store selected by BLOB_DRIVER: "local"
+// (default) to keep files in BLOB_DIR, served by the API itself at the path
+// of BLOB_PUBLIC_URL (http://localhost:8080/media by default), or "s3"
+// for an S3-compatible object storage such as AWS S3 or MinIO.
+func NewFromConfig(cfg *config.Config) (Store, error) {
+	switch cfg.BlobDriver {
+	case "", "local":
+		baseURL := cfg.BlobPublicURL
+		if baseURL == "" {
+			baseURL = "http://localhost:8080/media"
+		}
+		return NewLocalStore(cfg.BlobDir, baseURL)
+	case "s3":
+		return NewS3Store(S3Options{
+			Endpoint:        cfg.BlobS3Endpoint,
+			Region:          cfg.BlobS3Region,
+			Bucket:          cfg.BlobS3Bucket,
+			AccessKeyID:     cfg.BlobS3AccessKeyID,
+			SecretAccessKey: cfg.BlobS3SecretAccessKey,
+			PublicURL:       cfg.BlobPublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported blob driver %q", cfg.BlobDriver)
+	}
+}
+
+// validateKey rejects keys that are not clean relative paths, so that no
+// key can escape the directory or bucket it is stored in.
+func validateKey(key string) error {
+	if key == "" || len(key) > 1024 || strings.HasPrefix(key, "/") ||
+		strings.ContainsAny(key, "\\\x00") || path.Clean(key) != key {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// publicURL joins the base URL and the escaped key.
+func publicURL(base, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,258 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Options configure an S3-compatible object storage.
+type S3Options struct {
+	// Endpoint is the address of the storage, e.g.
+	// https://s3.eu-central-1.amazonaws.com or http://localhost:9000.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PublicURL is where clients load blobs from, such as a CDN in front
+	// of the bucket. It defaults to the bucket on the endpoint, which must
+	// then allow public reads.
+	PublicURL string
+	// Client defaults to an HTTP client with a 30 second timeout.
+	Client *http.Client
+}
+
+// S3Store keeps blobs in a bucket of an S3-compatible object storage. It
+// uses path-style addressing, which AWS and self-hosted implementations
+// such as MinIO all support, and signs requests with AWS Signature V4.
+type S3Store struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func NewS3Store(opts S3Options) (*S3Store, error) {
+	endpoint, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", opts.Endpoint)
+	}
+	if opts.Bucket == "" || opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		return nil, errors.New("S3 bucket and credentials are required")
+	}
+
+	region := opts.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	public := opts.PublicURL
+	if public == "" {
+		public = endpoint.String() + "/" + opts.Bucket
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &S3Store{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    opts.Bucket,
+		accessKey: opts.AccessKeyID,
+		secretKey: opts.SecretAccessKey,
+		publicURL: public,
+		client:    client,
+		now:       time.Now,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	_, err = s.do(req, data)
+	return err
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.do(req, nil)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.do(req, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *S3Store) URL(key string) string {
+	return publicURL(s.publicURL, key)
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	u := *s.endpoint
+	u.Path = u.Path + "/" + s.bucket + "/" + key
+	u.RawPath = s.endpoint.EscapedPath() + "/" + uriEncode(s.bucket, true) + "/" + uriEncode(key, false)
+
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), r)
+}
+
+// do signs and sends the request, returning the response body of a
+// successful request.
+func (s *S3Store) do(req *http.Request, payload []byte) ([]byte, error) {
+	sum := sha256.Sum256(payload)
+	s.sign(req, hex.EncodeToString(sum[:]))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		if len(body) > 512 {
+			body = body[:512]
+		}
+		return nil, fmt.Errorf("S3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
+
+// sign adds an AWS Signature V4 authorization header covering the host and
+// every header already set on the request.
+func (s *S3Store) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := []string{"host"}
+	for name := range req.Header {
+		signed = append(signed, strings.ToLower(name))
+	}
+	sort.Strings(signed)
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	canonical := canonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.Query(), req.Header, req.URL.Host, signed, payloadHash)
+	signature := hex.EncodeToString(hmacSHA256(
+		signingKey(s.secretKey, date, s.region),
+		"AWS4-HMAC-SHA256\n"+amzDate+"\n"+scope+"\n"+sha256Hex(canonical),
+	))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, strings.Join(signed, ";"), signature))
+}
+
+// canonicalRequest renders a request in the canonical form that Signature
+// V4 signs. signed lists the lowercase names of the signed headers in
+// sorted order.
+func canonicalRequest(method, escapedPath string, query url.Values, header http.Header, host string, signed []string, payloadHash string) string {
+	var b strings.Builder
+	b.WriteString(method + "\n")
+	if escapedPath == "" {
+		escapedPath = "/"
+	}
+	b.WriteString(escapedPath + "\n")
+
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(params)
+	b.WriteString(strings.Join(params, "&") + "\n")
+
+	for _, name := range signed {
+		value := host
+		if name != "host" {
+			value = strings.Join(header.Values(name), ",")
+		}
+		b.WriteString(name + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+	b.WriteString("\n" + strings.Join(signed, ";") + "\n" + payloadHash)
+	return b.String()
+}
+
+func signingKey(secret, date, region string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// uriEncode percent-encodes everything but unreserved characters, as
+// Signature V4 requires; slashes are kept unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,6 +16,111 @@ type Config struct {
 	DBName     string
 	JWTSecret  string
 	JWTExpiry  string
+
+	// JWTAlgorithm is HS256 (default, signed with JWTSecret), RS256 or
+	// EdDSA. Asymmetric algorithms read the key from JWTPrivateKeyFile.
+	JWTAlgorithm      string
+	JWTKeyID          string
+	JWTPrivateKeyFile string
+	// JWTPreviousKeys lists retired keys (private or public PEM files) that
+	// still verify tokens, as comma-separated kid=path pairs.
+	JWTPreviousKeys string
+
+	RefreshTokenExpiry string
+
+	// AppURL is the public address of the frontend, used for links in emails.
+	AppURL string
+	// APIURL is the public address of this server, used for URLs that
+	// clients call directly, such as calendar feeds.
+	APIURL string
+
+	// MailDriver is "smtp", "file" or "memory"; see mail.NewFromConfig.
+	MailDriver   string
+	MailFrom     string
+	MailDir      string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	PasswordResetExpiry string
+
+	EmailVerificationExpiry         string
+	EmailVerificationResendInterval string
+	// EmailVerificationRequiredFor lists the actions that need a verified
+	// email address, e.g. publish_wishlist,invite_collaborator.
+	EmailVerificationRequiredFor []string
+
+	// TOTPIssuer is the account label shown in authenticator apps.
+	TOTPIssuer           string
+	MFAChallengeDuration string
+
+	// LoginLockoutThreshold is the number of failed logins after which an
+	// account is locked for LoginLockoutDuration.
+	LoginLockoutThreshold string
+	LoginLockoutDuration  string
+
+	// AdminEmails lists accounts that are made administrators on startup.
+	AdminEmails []string
+
+	// AccountDeletionGracePeriod is how long a deleted account can still be
+	// restored before it is erased.
+	AccountDeletionGracePeriod string
+
+	// EventArchiveDelay is how long after its event a wishlist is archived.
+	EventArchiveDelay string
+
+	// TrustedProxies lists the proxies (IPs or CIDRs) whose
+	// X-Forwarded-For header is believed when determining the client IP.
+	TrustedProxies []string
+
+	// RateLimitStore is "memory" or "postgres"; see ratelimit.StoreFromConfig.
+	// Rates are written as <requests>/<duration>, e.g. 10/s or 20/1m.
+	RateLimitStore     string
+	RateLimitAPI       string
+	RateLimitAPIBurst  string
+	RateLimitAuth      string
+	RateLimitAuthBurst string
+
+	// LinkPreview* bound fetching product pages to pre-fill items; see
+	// linkpreview.OptionsFromConfig.
+	LinkPreviewTimeout  string
+	LinkPreviewMaxBytes string
+	LinkPreviewCacheTTL string
+
+	// PriceCheckInterval is how often the price of an item with a product
+	// URL is re-read; PriceCheckBatchSize caps the items checked per run.
+	PriceCheckInterval  string
+	PriceCheckBatchSize string
+
+	// BlobDriver is "local" or "s3"; see blob.NewFromConfig. BlobPublicURL
+	// is the address uploaded files are loaded from.
+	BlobDriver            string
+	BlobDir               string
+	BlobPublicURL         string
+	BlobS3Endpoint        string
+	BlobS3Region          string
+	BlobS3Bucket          string
+	BlobS3AccessKeyID     string
+	BlobS3SecretAccessKey string
+
+	// ImageUploadMaxBytes caps the size of an uploaded item image.
+	ImageUploadMaxBytes string
+
+	// OIDCProviders are the OpenID providers users can log in with.
+	OIDCProviders []OIDCProvider
+}
+
+// OIDCProvider configures login with an OpenID provider. Each provider
+// listed in OIDC_PROVIDERS is read from OIDC_<NAME>_* variables.
+type OIDCProvider struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL defaults to APP_URL/oauth/callback/<name>.
+	RedirectURL string
+	Scopes      []string
 }
 
 func New() *Config {
@@ -24,10 +132,139 @@ func New() *Config {
 		DBName:     os.Getenv("DB_NAME"),
 		JWTSecret:  os.Getenv("JWT_SECRET"),
 		JWTExpiry:  os.Getenv("JWT_DURATION"),
+
+		JWTAlgorithm:      os.Getenv("JWT_ALGORITHM"),
+		JWTKeyID:          os.Getenv("JWT_KEY_ID"),
+		JWTPrivateKeyFile: os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		JWTPreviousKeys:   os.Getenv("JWT_PREVIOUS_KEYS"),
+
+		RefreshTokenExpiry: os.Getenv("REFRESH_TOKEN_DURATION"),
+
+		AppURL: getEnvOrDefault("APP_URL", "http://localhost:5173"),
+		APIURL: getEnvOrDefault("API_URL", "http://localhost:8080"),
+
+		MailDriver:   os.Getenv("MAIL_DRIVER"),
+		MailFrom:     getEnvOrDefault("MAIL_FROM", "Wishlist <no-reply@wishlist.local>"),
+		MailDir:      getEnvOrDefault("MAIL_DIR", "tmp/mail"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     getEnvOrDefault("SMTP_PORT", "587"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+
+		PasswordResetExpiry: os.Getenv("PASSWORD_RESET_DURATION"),
+
+		EmailVerificationExpiry:         os.Getenv("EMAIL_VERIFICATION_DURATION"),
+		EmailVerificationResendInterval: os.Getenv("EMAIL_VERIFICATION_RESEND_INTERVAL"),
+		EmailVerificationRequiredFor:    parseList(getEnvOrDefault("EMAIL_VERIFICATION_REQUIRED_FOR", "publish_wishlist")),
+
+		TOTPIssuer:           getEnvOrDefault("TOTP_ISSUER", "Wishlist"),
+		MFAChallengeDuration: os.Getenv("MFA_CHALLENGE_DURATION"),
+
+		LoginLockoutThreshold: os.Getenv("LOGIN_LOCKOUT_THRESHOLD"),
+		LoginLockoutDuration:  os.Getenv("LOGIN_LOCKOUT_DURATION"),
+
+		AdminEmails: parseList(os.Getenv("ADMIN_EMAILS")),
+
+		AccountDeletionGracePeriod: os.Getenv("ACCOUNT_DELETION_GRACE_PERIOD"),
+		EventArchiveDelay:          os.Getenv("EVENT_ARCHIVE_DELAY"),
+
+		TrustedProxies: parseList(os.Getenv("TRUSTED_PROXIES")),
+
+		RateLimitStore:     os.Getenv("RATE_LIMIT_STORE"),
+		RateLimitAPI:       getEnvOrDefault("RATE_LIMIT_API", "10/s"),
+		RateLimitAPIBurst:  getEnvOrDefault("RATE_LIMIT_API_BURST", "30"),
+		RateLimitAuth:      getEnvOrDefault("RATE_LIMIT_AUTH", "20/m"),
+		RateLimitAuthBurst: getEnvOrDefault("RATE_LIMIT_AUTH_BURST", "10"),
+
+		LinkPreviewTimeout:  os.Getenv("LINK_PREVIEW_TIMEOUT"),
+		LinkPreviewMaxBytes: os.Getenv("LINK_PREVIEW_MAX_BYTES"),
+		LinkPreviewCacheTTL: os.Getenv("LINK_PREVIEW_CACHE_TTL"),
+
+		PriceCheckInterval:  os.Getenv("PRICE_CHECK_INTERVAL"),
+		PriceCheckBatchSize: os.Getenv("PRICE_CHECK_BATCH_SIZE"),
+
+		BlobDriver:            os.Getenv("BLOB_DRIVER"),
+		BlobDir:               getEnvOrDefault("BLOB_DIR", "tmp/uploads"),
+		BlobPublicURL:         os.Getenv("BLOB_PUBLIC_URL"),
+		BlobS3Endpoint:        os.Getenv("BLOB_S3_ENDPOINT"),
+		BlobS3Region:          os.Getenv("BLOB_S3_REGION"),
+		BlobS3Bucket:          os.Getenv("BLOB_S3_BUCKET"),
+		BlobS3AccessKeyID:     os.Getenv("BLOB_S3_ACCESS_KEY_ID"),
+		BlobS3SecretAccessKey: os.Getenv("BLOB_S3_SECRET_ACCESS_KEY"),
+
+		ImageUploadMaxBytes: os.Getenv("IMAGE_UPLOAD_MAX_BYTES"),
 	}
 
+	cfg.OIDCProviders = loadOIDCProviders(cfg.AppURL)
+
 	log.Printf("Database configuration: host=%s, port=%s, user=%s, dbname=%s",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBName)
 
 	return cfg
-} 
\ No newline at end of file
+}
+
+// ParseDuration parses a duration setting such as "15m" or "720h", falling
+// back to the default when the value is empty or malformed.
+func ParseDuration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid duration %q, using %s", value, fallback)
+		return fallback
+	}
+
+	return d
+}
+
+// ParseInt parses a positive integer setting, falling back to the default
+// when the value is empty or malformed.
+func ParseInt(value string, fallback int) int {
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid number %q, using %d", value, fallback)
+		return fallback
+	}
+
+	return n
+}
+
+func loadOIDCProviders(appURL string) []OIDCProvider {
+	var providers []OIDCProvider
+	for _, name := range parseList(os.Getenv("OIDC_PROVIDERS")) {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProvider{
+			Name:         name,
+			IssuerURL:    os.Getenv(prefix + "ISSUER_URL"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  getEnvOrDefault(prefix+"REDIRECT_URL", strings.TrimRight(appURL, "/")+"/oauth/callback/"+name),
+			Scopes:       parseList(os.Getenv(prefix + "SCOPES")),
+		})
+	}
+	return providers
+}
+
+// parseList splits a comma-separated setting, dropping empty entries.
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,17 @@
+package domain
+
+// AccountData is everything stored about a user, as handed out for data
+// export requests. Secrets such as password and token hashes are left out
+// by the JSON tags of the models.
+type AccountData struct {
+	User                 *User                  `json:"user"`
+	Reservations         []*Reservation         `json:"reservations"`
+	Collaborations       []*Collaborator        `json:"collaborations"`
+	InvitationsSent      []*Invitation          `json:"invitations_sent"`
+	InvitationsReceived  []*Invitation          `json:"invitations_received"`
+	Sessions             []*TokenFamily         `json:"sessions"`
+	PersonalAccessTokens []*PersonalAccessToken `json:"personal_access_tokens"`
+	Identities           []*UserIdentity        `json:"identities"`
+	SecurityEvents       []*SecurityEvent       `json:"security_events"`
+	Tags                 []*Tag                 `json:"tags"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+// SystemStats summarizes the usage of the service for administrators.
+type SystemStats struct {
+	Users            int64 `json:"users"`
+	VerifiedUsers    int64 `json:"verified_users"`
+	DisabledUsers    int64 `json:"disabled_users"`
+	Admins           int64 `json:"admins"`
+	NewUsersLastWeek int64 `json:"new_users_last_week"`
+	WishLists        int64 `json:"wishlists"`
+	PublicWishLists  int64 `json:"public_wishlists"`
+	Items            int64 `json:"items"`
+	Reservations     int64 `json:"reservations"`
+	ActiveSessions   int64 `json:"active_sessions"`
+}
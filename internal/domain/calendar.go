@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+)
+
+// CalendarFeedPrefix starts every calendar feed token.
+const CalendarFeedPrefix = "wl_cal_"
+
+// CalendarFeed is the secret iCalendar subscription of a user. Only the
+// hash of its token is stored. ContentHash and ChangedAt record when the
+// served calendar last changed, for conditional requests.
+type CalendarFeed struct {
+	ID          uint       `json:"-" gorm:"primaryKey"`
+	UserID      uint       `json:"-" gorm:"not null;uniqueIndex"`
+	TokenHash   string     `json:"-" gorm:"not null;uniqueIndex"`
+	ContentHash string     `json:"-" gorm:"not null;default:''"`
+	ChangedAt   time.Time  `json:"-"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	User        *User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+)
+
+// Roles a user can have on a wishlist. The owner is always the wishlist's
+// UserID; editors and viewers are stored as collaborators.
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+type Collaborator struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WishListID uint      `json:"wishlist_id" gorm:"not null;uniqueIndex:idx_collaborators_wishlist_user"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_collaborators_wishlist_user"`
+	Role       string    `json:"role" gorm:"not null"`
+	User       *User     `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName указывает GORM использовать таблицу wishlist_collaborators
+func (Collaborator) TableName() string {
+	return "wishlist_collaborators"
+}
+
+type Invitation struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	WishListID  uint      `json:"wishlist_id" gorm:"not null;index"`
+	Email       string    `json:"email" gorm:"not null;index"`
+	Role        string    `json:"role" gorm:"not null"`
+	Status      string    `json:"status" gorm:"not null;default:pending"`
+	InvitedByID uint      `json:"invited_by_id" gorm:"not null"`
+	WishList    *WishList `json:"wishlist,omitempty" gorm:"foreignKey:WishListID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName указывает GORM использовать таблицу wishlist_invitations
+func (Invitation) TableName() string {
+	return "wishlist_invitations"
+}
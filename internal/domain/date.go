@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the format dates are exchanged in.
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day without a time or timezone, such as the day of an
+// event. It is stored as DATE and encoded as a "YYYY-MM-DD" JSON string.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// ParseDate parses a date formatted as YYYY-MM-DD.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return DateOf(t), nil
+}
+
+// DateOf returns the day t falls on in its location.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+func (d Date) String() string {
+	return d.In(time.UTC).Format(DateLayout)
+}
+
+// In returns the start of the day in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDays returns the date n days later, or earlier for negative n.
+func (d Date) AddDays(n int) Date {
+	return DateOf(time.Date(d.Year, d.Month, d.Day+n, 0, 0, 0, 0, time.UTC))
+}
+
+// DaysUntil returns the number of days from d to other, negative if other
+// is earlier.
+func (d Date) DaysUntil(other Date) int {
+	return int(other.In(time.UTC).Sub(d.In(time.UTC)).Hours() / 24)
+}
+
+func (d Date) Before(other Date) bool {
+	return d.DaysUntil(other) > 0
+}
+
+// Anniversary returns the first yearly recurrence of d on or after from.
+// In years without February 29, that date recurs on February 28.
+func (d Date) Anniversary(from Date) Date {
+	for year := from.Year; ; year++ {
+		next := Date{Year: year, Month: d.Month, Day: d.Day}
+		if d.Month == time.February && d.Day == 29 && !isLeapYear(year) {
+			next.Day = 28
+		}
+		if !next.Before(from) {
+			return next
+		}
+	}
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	parsed, err := ParseDate(raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d *Date) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = DateOf(v)
+		return nil
+	case string:
+		return d.scanString(v)
+	case []byte:
+		return d.scanString(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+}
+
+func (d *Date) scanString(s string) error {
+	if len(s) > len(DateLayout) {
+		s = s[:len(DateLayout)]
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
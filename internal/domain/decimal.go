@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Decimal is an exact decimal number such as a price. It keeps the textual
+// form so that amounts never pass through float64; it is stored as NUMERIC
+// and encoded as a JSON number.
+type Decimal string
+
+// ParseDecimal parses a plain decimal literal such as "19.99".
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return "", fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal(s), nil
+}
+
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Cmp compares d and other and returns -1, 0 or +1. Both must be valid
+// decimals.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.rat().Cmp(other.rat())
+}
+
+func (d Decimal) rat() *big.Rat {
+	r, ok := new(big.Rat).SetString(string(d))
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// Negative reports whether the number is below zero.
+func (d Decimal) Negative() bool {
+	return strings.HasPrefix(string(d), "-") && strings.Trim(string(d), "-0.") != ""
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int {
+	_, frac, _ := strings.Cut(string(d), ".")
+	return len(frac)
+}
+
+// IntegerDigits returns the number of significant digits before the decimal point.
+func (d Decimal) IntegerDigits() int {
+	integer, _, _ := strings.Cut(strings.TrimPrefix(string(d), "-"), ".")
+	return len(strings.TrimLeft(integer, "0"))
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if d == "" {
+		return []byte("null"), nil
+	}
+	return []byte(d), nil
+}
+
+// UnmarshalJSON accepts both JSON numbers and strings holding a number.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	raw := string(data)
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseDecimal(raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d *Decimal) Scan(src interface{}) error {
+	var raw string
+	switch v := src.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case int64:
+		raw = strconv.FormatInt(v, 10)
+	case float64:
+		raw = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("cannot scan %T into Decimal", src)
+	}
+	parsed, err := ParseDecimal(raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return string(d), nil
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"time"
+)
+
+// EmailVerificationToken is a single-use token emailed to a user to confirm
+// the address. Only its hash is stored.
+type EmailVerificationToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Email     string     `json:"email" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	User      *User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+)
+
+// Statuses of a wishlist. Lists whose one-off event has passed are archived
+// automatically.
+const (
+	WishListActive   = "active"
+	WishListArchived = "archived"
+)
+
+// Occasions a wishlist can be for.
+const (
+	OccasionBirthday     = "birthday"
+	OccasionWedding      = "wedding"
+	OccasionChristmas    = "christmas"
+	OccasionAnniversary  = "anniversary"
+	OccasionBabyShower   = "baby_shower"
+	OccasionGraduation   = "graduation"
+	OccasionHousewarming = "housewarming"
+	OccasionOther        = "other"
+)
+
+// Occasions lists the valid occasion types.
+var Occasions = []string{
+	OccasionBirthday,
+	OccasionWedding,
+	OccasionChristmas,
+	OccasionAnniversary,
+	OccasionBabyShower,
+	OccasionGraduation,
+	OccasionHousewarming,
+	OccasionOther,
+}
+
+// RecurrenceYearly makes the event of a wishlist repeat on the same day
+// every year. An empty recurrence is a one-off event.
+const RecurrenceYearly = "yearly"
+
+// EventWishList is a wishlist with an event date together with the name of
+// its owner, as needed to list upcoming events.
+type EventWishList struct {
+	WishList
+	OwnerName string
+}
+
+// UpcomingEvent is the next occurrence of the event of a wishlist.
+type UpcomingEvent struct {
+	WishListID   uint      `json:"wishlist_id"`
+	WishListName string    `json:"wishlist_name"`
+	OwnerID      uint      `json:"owner_id"`
+	OwnerName    string    `json:"owner_name"`
+	Own          bool      `json:"own"`
+	Occasion     string    `json:"occasion"`
+	Date         Date      `json:"date"`
+	Timezone     string    `json:"timezone"`
+	StartsAt     time.Time `json:"starts_at"`
+	DaysUntil    int       `json:"days_until"`
+	Recurring    bool      `json:"recurring"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+)
+
+// UserIdentity links a user to an account at an external OpenID provider.
+type UserIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"-" gorm:"not null;index"`
+	Provider  string    `json:"provider" gorm:"not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject   string    `json:"-" gorm:"not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email     string    `json:"email"`
+	User      *User     `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OIDCLoginState remembers a login started with an OpenID provider until
+// the user returns with an authorization code. Only the hash of the state
+// parameter is stored.
+type OIDCLoginState struct {
+	ID           uint      `gorm:"primaryKey"`
+	StateHash    string    `gorm:"not null;uniqueIndex"`
+	Provider     string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	Nonce        string    `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	CreatedAt    time.Time
+}
+
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"time"
+)
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the
+// authenticator is lost. Only its bcrypt hash is stored.
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	User      *User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time  `json:"created_at"`
+}
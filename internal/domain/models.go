@@ -0,0 +1,30 @@
+package domain
+
+// Models returns every persisted model in dependency order, so that
+// referenced tables are migrated before the tables that reference them.
+func Models() []interface{} {
+	return []interface{}{
+		&User{},
+		&Tag{},
+		&WishList{},
+		&WishItem{},
+		&ItemImage{},
+		&PricePoint{},
+		&OrphanedBlob{},
+		&Reservation{},
+		&Collaborator{},
+		&Invitation{},
+		&TokenFamily{},
+		&RefreshToken{},
+		&PasswordResetToken{},
+		&EmailVerificationToken{},
+		&RecoveryCode{},
+		&PersonalAccessToken{},
+		&UserIdentity{},
+		&OIDCLoginState{},
+		&SecurityEvent{},
+		&LoginThrottle{},
+		&RateLimitBucket{},
+		&CalendarFeed{},
+	}
+}
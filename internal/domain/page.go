@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Sort orders of wishlists and items. Lists without an event date sort as
+// if their event were in the far future.
+const (
+	SortCreated   = "created"
+	SortUpdated   = "updated"
+	SortName      = "name"
+	SortEventDate = "event_date"
+	SortPosition  = "position"
+	SortPriority  = "priority"
+)
+
+// WishListSorts and ItemSorts list the orders wishlists and items can be
+// listed in.
+var (
+	WishListSorts = []string{SortCreated, SortUpdated, SortName, SortEventDate}
+	ItemSorts     = []string{SortPosition, SortCreated, SortUpdated, SortName, SortPriority}
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a position in a sorted listing: the sort key and ID of the
+// row a page starts after, or ends before if Before is set. Sort is the
+// order the cursor was issued for, so that it is not applied to another.
+type Cursor struct {
+	Sort   string `json:"s"`
+	Key    string `json:"k"`
+	ID     uint   `json:"i"`
+	Before bool   `json:"b,omitempty"`
+}
+
+// Encode returns the cursor as an opaque, URL-safe string.
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor decodes a cursor returned by Encode.
+func ParseCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort == "" || cursor.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// PageQuery selects a page of a sorted listing. Without a cursor it is the
+// first page. With Count the total number of matching rows is returned.
+type PageQuery struct {
+	Sort   string
+	Desc   bool
+	Limit  int
+	Cursor *Cursor
+	Count  bool
+}
+
+// SortParam returns the order as written in requests and cursors, such
+// as "name" or "-updated" for descending.
+func (q PageQuery) SortParam() string {
+	if q.Desc {
+		return "-" + q.Sort
+	}
+	return q.Sort
+}
+
+// WishListQuery selects the wishlists to list.
+type WishListQuery struct {
+	Tags   TagFilter
+	Status string
+	Page   PageQuery
+}
+
+// ItemQuery selects the items of a wishlist to list.
+type ItemQuery struct {
+	Tags   TagFilter
+	Status string
+	Page   PageQuery
+}
+
+// WishListPage is one page of wishlists. Next and Prev lead to the
+// adjacent pages, if any; Total is only set when counting was requested.
+type WishListPage struct {
+	WishLists []*WishList
+	Next      *Cursor
+	Prev      *Cursor
+	Total     *int64
+}
+
+// ItemPage is one page of items, like WishListPage.
+type ItemPage struct {
+	Items []*WishItem
+	Next  *Cursor
+	Prev  *Cursor
+	Total *int64
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, so that
+// tokens are recognizable, e.g. by secret scanners, and distinguishable from
+// JWTs.
+const PersonalAccessTokenPrefix = "wl_pat_"
+
+// Token scopes
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// PersonalAccessToken is a long-lived credential for scripts and
+// integrations. Only its hash is stored; Prefix is the start of the token,
+// kept so that users can tell their tokens apart.
+type PersonalAccessToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"-" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null"`
+	TokenHash  string     `json:"-" gorm:"not null;uniqueIndex"`
+	Scope      string     `json:"scope" gorm:"not null;default:read"`
+	WishListID *uint      `json:"wishlist_id,omitempty" gorm:"index"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"-"`
+	User       *User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	WishList   *WishList  `json:"-" gorm:"foreignKey:WishListID;constraint:OnDelete:CASCADE"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AllowsWrite reports whether the token may change data.
+func (t *PersonalAccessToken) AllowsWrite() bool {
+	return t.Scope == ScopeWrite
+}
+
+// Active reports whether the token can still be used at the given time.
+func (t *PersonalAccessToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
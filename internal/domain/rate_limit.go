@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"time"
+)
+
+// RateLimitBucket holds the rate limit state of one client when limits are
+// shared between replicas. TAT is the time at which the client's burst is
+// fully available again; see the ratelimit package.
+type RateLimitBucket struct {
+	Key string    `gorm:"primaryKey;column:bucket_key"`
+	TAT time.Time `gorm:"column:tat;not null;index"`
+}
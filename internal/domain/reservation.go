@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+)
+
+// Reservation marks a wish item as claimed by a friend who intends to buy it.
+// Claimant details are never shown to the wishlist owner.
+type Reservation struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	WishItemID    uint      `json:"wishitem_id" gorm:"uniqueIndex;not null"`
+	UserID        *uint     `json:"user_id,omitempty"`
+	ClaimantName  string    `json:"claimant_name"`
+	ClaimantEmail string    `json:"claimant_email,omitempty"`
+	TokenHash     string    `json:"-" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+)
+
+// Security event types
+const (
+	SecurityEventLoginSucceeded      = "login_succeeded"
+	SecurityEventLoginFailed         = "login_failed"
+	SecurityEventLoginThrottled      = "login_throttled"
+	SecurityEventMFAFailed           = "mfa_failed"
+	SecurityEventAccountLocked       = "account_locked"
+	SecurityEventAccountUnlocked     = "account_unlocked"
+	SecurityEventAccountDisabled     = "account_disabled"
+	SecurityEventAccountEnabled      = "account_enabled"
+	SecurityEventPasswordResetForced = "password_reset_forced"
+	SecurityEventRoleChanged         = "role_changed"
+)
+
+// SecurityEvent records something security relevant, such as a failed
+// login, for auditing and for spotting credential stuffing. ActorID is set
+// when an administrator acted on the account.
+type SecurityEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    *uint     `json:"user_id,omitempty" gorm:"index"`
+	ActorID   *uint     `json:"actor_id,omitempty"`
+	Type      string    `json:"type" gorm:"not null;index"`
+	Email     string    `json:"email,omitempty" gorm:"index"`
+	IP        string    `json:"ip,omitempty" gorm:"column:ip;index"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	User      *User     `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// LoginThrottle counts recent failed logins for an account or a client IP.
+// Key is "account:<email>" or "ip:<address>".
+type LoginThrottle struct {
+	Key             string    `gorm:"primaryKey;column:throttle_key"`
+	Failures        int       `gorm:"not null;default:0"`
+	LastFailureAt   time.Time `gorm:"not null"`
+	LockedUntil     *time.Time
+	UnlockTokenHash *string `gorm:"uniqueIndex"`
+}
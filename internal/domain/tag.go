@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+)
+
+// MaxTagsPerTarget is the maximum number of tags on one wishlist or item.
+const MaxTagsPerTarget = 20
+
+// Tag is a label a user groups wishlists and items by, such as "books" or
+// "kitchen". Names are unique per user, ignoring case. The tags on a
+// wishlist and its items belong to the owner of the wishlist.
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"-" gorm:"not null;uniqueIndex:idx_tags_user_name"`
+	User      *User     `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Name      string    `json:"name" gorm:"size:50;not null;uniqueIndex:idx_tags_user_name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TagUsage is a tag with the number of wishlists and items it is on.
+type TagUsage struct {
+	Tag
+	WishListCount int64 `json:"wishlist_count"`
+	ItemCount     int64 `json:"item_count"`
+}
+
+// TagFilter selects wishlists or items by their tags. Names match tags
+// ignoring case; with MatchAny one of the tags is enough, otherwise all are
+// required. An empty filter matches everything.
+type TagFilter struct {
+	Names    []string
+	MatchAny bool
+}
+
+func (f TagFilter) Empty() bool {
+	return len(f.Names) == 0
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+)
+
+// TokenFamily groups the refresh tokens issued from a single login. Every
+// rotation stays in the same family, so revoking the family ends the session
+// on all of its tokens at once. It also records the device the session
+// belongs to and when it was last used, so users can review their sessions.
+type TokenFamily struct {
+	ID         string     `json:"id" gorm:"primaryKey;size:64"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip" gorm:"column:ip"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type RefreshToken struct {
+	ID        uint         `json:"id" gorm:"primaryKey"`
+	FamilyID  string       `json:"family_id" gorm:"not null;index;size:64"`
+	UserID    uint         `json:"user_id" gorm:"not null"`
+	TokenHash string       `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    *time.Time   `json:"used_at,omitempty"`
+	Family    *TokenFamily `json:"-" gorm:"foreignKey:FamilyID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time    `json:"created_at"`
+}
@@ -5,14 +5,24 @@ import (
 )
 
 type WishList struct {
-	ID          uint       `json:"id" gorm:"primaryKey"`
-	UserID      uint       `json:"user_id"`
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	Status      string     `json:"status"`
-	Items       []WishItem `json:"items,omitempty" gorm:"foreignKey:WishListID"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Status       string     `json:"status"`
+	IsPublic     bool       `json:"is_public" gorm:"not null;default:false"`
+	ShareCode    *string    `json:"share_code,omitempty" gorm:"uniqueIndex"`
+	SurpriseMode bool       `json:"surprise_mode" gorm:"not null;default:false"`
+	Items        []WishItem `json:"items,omitempty" gorm:"foreignKey:WishListID;constraint:OnDelete:CASCADE"`
+	Tags         []Tag      `json:"tags,omitempty" gorm:"many2many:wishlist_tags;constraint:OnDelete:CASCADE"`
+	// The event the list is for. EventDate is a day in Timezone, an IANA
+	// name; with RecurrenceYearly the event repeats every year.
+	Occasion   string    `json:"occasion" gorm:"size:20;not null;default:''"`
+	EventDate  *Date     `json:"event_date" gorm:"type:date;index"`
+	Recurrence string    `json:"recurrence" gorm:"size:10;not null;default:''"`
+	Timezone   string    `json:"timezone" gorm:"size:64;not null;default:UTC"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
 
 // TableName указывает GORM использовать таблицу wishlists вместо wish_lists
@@ -21,26 +31,156 @@ func (WishList) TableName() string {
 }
 
 type WishItem struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	WishListID  uint      `json:"wishlist_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	Priority    int       `json:"priority"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	WishListID  uint   `json:"wishlist_id" gorm:"column:wishlist_id;index:idx_wishlist_items_wishlist_position,priority:1"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Priority    int    `json:"priority"`
+	// Position orders the items of a list, ascending. Positions are spaced
+	// ItemPositionGap apart so that an item can be moved between two others
+	// by updating only its own position.
+	Position int64 `json:"position" gorm:"not null;default:0;index:idx_wishlist_items_wishlist_position,priority:2"`
+	// Product details. Price is kept exact and is only meaningful with a
+	// Currency, an ISO 4217 code.
+	URL              string      `json:"url" gorm:"size:500"`
+	Price            *Decimal    `json:"price" gorm:"type:numeric(15,3)"`
+	Currency         string      `json:"currency" gorm:"size:3"`
+	Quantity         int         `json:"quantity" gorm:"not null;default:1"`
+	ReceivedQuantity int         `json:"received_quantity" gorm:"not null;default:0"`
+	Images           []ItemImage `json:"images" gorm:"foreignKey:WishItemID;constraint:OnDelete:CASCADE"`
+	// Price tracking. The price is re-read from URL periodically; the owner
+	// is notified when it drops below PriceAlertBelow, and so are those who
+	// reserved the item if PriceAlertReservers is set.
+	PriceAlertBelow     *Decimal     `json:"price_alert_below" gorm:"type:numeric(15,3)"`
+	PriceAlertReservers bool         `json:"price_alert_reservers" gorm:"not null;default:false"`
+	PriceCheckedAt      *time.Time   `json:"price_checked_at" gorm:"index"`
+	PriceHistory        []PricePoint `json:"-" gorm:"foreignKey:WishItemID;constraint:OnDelete:CASCADE"`
+	Tags                []Tag        `json:"tags,omitempty" gorm:"many2many:wishlist_item_tags;constraint:OnDelete:CASCADE"`
+	// Reserved is computed per viewer and hidden from owners in surprise mode
+	Reserved    bool         `json:"reserved" gorm:"-"`
+	Reservation *Reservation `json:"-" gorm:"foreignKey:WishItemID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
 }
 
+// ItemPositionGap is the distance between the positions of consecutive items
+// when a list is (re)numbered.
+const ItemPositionGap int64 = 1024
+
 // TableName указывает GORM использовать таблицу wishlist_items вместо wish_items
 func (WishItem) TableName() string {
 	return "wishlist_items"
 }
 
+// MaxItemImages is the maximum number of images attached to a wish item.
+const MaxItemImages = 10
+
+// ItemImage is a picture of a wish item, shown in Position order. It is
+// either referenced by URL or uploaded, in which case BlobKey is the key of
+// the stored file and Thumbnails holds the URLs of scaled-down copies by
+// size name.
+type ItemImage struct {
+	ID          uint              `json:"id" gorm:"primaryKey"`
+	WishItemID  uint              `json:"-" gorm:"index;not null"`
+	URL         string            `json:"url" gorm:"not null"`
+	BlobKey     string            `json:"-" gorm:"size:255;not null;default:''"`
+	ContentType string            `json:"content_type,omitempty" gorm:"size:50;not null;default:''"`
+	Width       int               `json:"width,omitempty" gorm:"not null;default:0"`
+	Height      int               `json:"height,omitempty" gorm:"not null;default:0"`
+	Thumbnails  map[string]string `json:"thumbnails,omitempty" gorm:"type:jsonb;serializer:json"`
+	Position    int               `json:"position" gorm:"not null;default:0"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+func (ItemImage) TableName() string {
+	return "wishlist_item_images"
+}
+
+// Uploaded reports whether the image file is kept in blob storage.
+func (i ItemImage) Uploaded() bool {
+	return i.BlobKey != ""
+}
+
+// OrphanedBlob is an uploaded file whose record was deleted. Orphans are
+// recorded in the same transaction as the deletion and removed from blob
+// storage afterwards, so that no file outlives its item.
+type OrphanedBlob struct {
+	ID        uint      `gorm:"primaryKey"`
+	BlobKey   string    `gorm:"size:255;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (OrphanedBlob) TableName() string {
+	return "orphaned_blobs"
+}
+
+// PricePoint records a price of a wish item read from its product page.
+// A point is stored whenever the price changes.
+type PricePoint struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WishItemID uint      `json:"-" gorm:"index;not null"`
+	Price      Decimal   `json:"price" gorm:"type:numeric(15,3);not null"`
+	Currency   string    `json:"currency" gorm:"size:3;not null"`
+	CheckedAt  time.Time `json:"checked_at" gorm:"not null"`
+}
+
+func (PricePoint) TableName() string {
+	return "wishlist_item_prices"
+}
+
+// User roles. Admins can manage other accounts through the admin API.
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
 type User struct {
-	ID           uint       `json:"id" gorm:"primaryKey"`
-	Email        string     `json:"email" gorm:"unique;not null"`
-	PasswordHash string     `json:"-" gorm:"column:password_hash;not null"`
-	WishLists    []WishList `json:"wish_lists" gorm:"foreignKey:UserID"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-} 
\ No newline at end of file
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Email           string     `json:"email" gorm:"unique;not null"`
+	PendingEmail    *string    `json:"pending_email,omitempty"`
+	PasswordHash    string     `json:"-" gorm:"column:password_hash;not null"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	DisplayName     string     `json:"display_name"`
+	AvatarURL       string     `json:"avatar_url"`
+	Birthday        *time.Time `json:"birthday" gorm:"type:date"`
+	Locale          string     `json:"locale" gorm:"not null;default:en"`
+	Timezone        string     `json:"timezone" gorm:"not null;default:UTC"`
+	TOTPSecret      string     `json:"-" gorm:"column:totp_secret"`
+	TOTPEnabledAt   *time.Time `json:"totp_enabled_at" gorm:"column:totp_enabled_at"`
+	TOTPLastStep    int64      `json:"-" gorm:"column:totp_last_step;not null;default:0"`
+	DeletionDueAt   *time.Time `json:"deletion_due_at,omitempty" gorm:"index"`
+	Role            string     `json:"role" gorm:"not null;default:user"`
+	DisabledAt      *time.Time `json:"disabled_at,omitempty"`
+	WishLists       []WishList `json:"wish_lists" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TOTPEnabled reports whether logging in requires a TOTP code. TOTPSecret is
+// stored as soon as enrollment starts but only takes effect once confirmed.
+func (u *User) TOTPEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// IsAdmin reports whether the user may use the admin API.
+func (u *User) IsAdmin() bool {
+	return u.Role == UserRoleAdmin
+}
+
+// Disabled reports whether an administrator has disabled the account, which
+// prevents it from logging in.
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
+}
+
+// DeletionScheduled reports whether the user asked for the account to be
+// deleted. It is erased at DeletionDueAt unless the request is cancelled.
+func (u *User) DeletionScheduled() bool {
+	return u.DeletionDueAt != nil
+}
+
+// EmailVerified reports whether the user has confirmed the email address.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
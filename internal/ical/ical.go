@@ -0,0 +1,162 @@
+// Package ical writes iCalendar (RFC 5545) documents for calendar
+// subscriptions.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of iCalendar documents.
+const ContentType = "text/calendar; charset=utf-8"
+
+// maxLineOctets is the longest content line before it must be folded.
+const maxLineOctets = 75
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+)
+
+// Calendar is a published calendar of all-day events.
+type Calendar struct {
+	// ProdID identifies the product that created the calendar, as in
+	// "-//Example//Calendar//EN".
+	ProdID string
+	Name   string
+	// RefreshInterval suggests how often clients poll for changes.
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Event is an all-day event. Only the year, month and day of Date are used,
+// so the event falls on that day wherever the calendar is viewed.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Categories  []string
+	URL         string
+	Date        time.Time
+	// RRule is a recurrence rule such as "FREQ=YEARLY", or empty for a
+	// one-off event.
+	RRule        string
+	LastModified time.Time
+}
+
+// Encode writes the calendar to w.
+func (c *Calendar) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", escapeText(c.ProdID))
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		interval := formatDuration(c.RefreshInterval)
+		writeLine(bw, "REFRESH-INTERVAL;VALUE=DURATION:"+interval)
+		line("X-PUBLISHED-TTL", interval)
+	}
+
+	for _, event := range c.Events {
+		stamp := event.LastModified.UTC().Format(dateTimeLayout)
+		start := time.Date(event.Date.Year(), event.Date.Month(), event.Date.Day(), 0, 0, 0, 0, time.UTC)
+
+		line("BEGIN", "VEVENT")
+		line("UID", escapeText(event.UID))
+		line("DTSTAMP", stamp)
+		line("LAST-MODIFIED", stamp)
+		writeLine(bw, "DTSTART;VALUE=DATE:"+start.Format(dateLayout))
+		writeLine(bw, "DTEND;VALUE=DATE:"+start.AddDate(0, 0, 1).Format(dateLayout))
+		if event.RRule != "" {
+			line("RRULE", event.RRule)
+		}
+		line("SUMMARY", escapeText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escapeText(event.Description))
+		}
+		if len(event.Categories) > 0 {
+			categories := make([]string, len(event.Categories))
+			for i, category := range event.Categories {
+				categories[i] = escapeText(category)
+			}
+			line("CATEGORIES", strings.Join(categories, ","))
+		}
+		if event.URL != "" {
+			writeLine(bw, "URL;VALUE=URI:"+event.URL)
+		}
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// writeLine writes a content line terminated by CRLF, folding it into lines
+// of at most 75 octets without splitting UTF-8 sequences.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// escapeText escapes a TEXT value.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// formatDuration formats d as an RFC 5545 duration in whole minutes, such
+// as PT1H or P1DT30M.
+func formatDuration(d time.Duration) string {
+	minutes := int64(d / time.Minute)
+	days, minutes := minutes/(24*60), minutes%(24*60)
+	hours, minutes := minutes/60, minutes%60
+
+	var b strings.Builder
+	b.WriteString("P")
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if hours > 0 || minutes > 0 || days == 0 {
+		b.WriteString("T")
+		if hours > 0 {
+			fmt.Fprintf(&b, "%dH", hours)
+		}
+		if minutes > 0 || hours == 0 {
+			fmt.Fprintf(&b, "%dM", minutes)
+		}
+	}
+	return b.String()
+}
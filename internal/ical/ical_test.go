@@ -0,0 +1,70 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	calendar := &Calendar{
+		ProdID:          "-//Wishlist//Events//EN",
+		Name:            "Events",
+		RefreshInterval: time.Hour,
+		Events: []Event{
+			{
+				UID:          "wishlist-1@example.com",
+				Summary:      "Birthday; party, with cake",
+				Categories:   []string{"birthday"},
+				URL:          "https://app.example.com/wishlists/1",
+				Date:         time.Date(2000, time.February, 29, 0, 0, 0, 0, time.UTC),
+				RRule:        "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1",
+				LastModified: time.Date(2026, time.January, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, calendar.Encode(&buf))
+	body := buf.String()
+
+	assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(body, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	assert.Contains(t, body, "REFRESH-INTERVAL;VALUE=DURATION:PT1H\r\n")
+	assert.Contains(t, body, "DTSTAMP:20260102T020405Z\r\n")
+	assert.Contains(t, body, "DTSTART;VALUE=DATE:20000229\r\nDTEND;VALUE=DATE:20000301\r\n")
+	assert.Contains(t, body, "RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1\r\n")
+	assert.Contains(t, body, `SUMMARY:Birthday\; party\, with cake`+"\r\n")
+	assert.NotContains(t, strings.ReplaceAll(body, "\r\n", ""), "\n")
+}
+
+func TestWriteLineFolds(t *testing.T) {
+	var buf bytes.Buffer
+	calendar := &Calendar{Events: []Event{{Summary: strings.Repeat("ü", 100), Description: "first\nsecond"}}}
+	require.NoError(t, calendar.Encode(&buf))
+
+	var unfolded strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets, "line %d", i)
+		assert.True(t, utf8.ValidString(line), "line %d splits a character", i)
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+		} else {
+			unfolded.WriteString("\n" + line)
+		}
+	}
+	assert.Contains(t, unfolded.String(), "\nSUMMARY:"+strings.Repeat("ü", 100)+"\n")
+	assert.Contains(t, unfolded.String(), `DESCRIPTION:first\nsecond`)
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "PT1H", formatDuration(time.Hour))
+	assert.Equal(t, "PT30M", formatDuration(30*time.Minute))
+	assert.Equal(t, "P1DT1H30M", formatDuration(25*time.Hour+30*time.Minute))
+	assert.Equal(t, "P2D", formatDuration(48*time.Hour))
+}
@@ -0,0 +1,132 @@
+// Package imaging prepares uploaded pictures for publishing: it checks what
+// the upload really is, drops all metadata such as EXIF (which may include
+// the location a photo was taken at) and renders thumbnails.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type, use JPEG, PNG or GIF")
+	ErrTooLarge        = errors.New("image dimensions are too large")
+	ErrInvalidImage    = errors.New("invalid image")
+)
+
+// DefaultMaxPixels bounds the decoded size of an image, which can be far
+// larger than the upload for highly compressed files.
+const DefaultMaxPixels = 40_000_000
+
+// Size is a thumbnail size; images are scaled down so that neither side
+// exceeds MaxDimension.
+type Size struct {
+	Name         string
+	MaxDimension int
+}
+
+// Sizes are the thumbnails rendered for every image.
+var Sizes = []Size{
+	{Name: "small", MaxDimension: 160},
+	{Name: "medium", MaxDimension: 480},
+	{Name: "large", MaxDimension: 1200},
+}
+
+// Encoded is an image rendered in one size.
+type Encoded struct {
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Processed is an upload ready to be stored: the full-size image without
+// metadata and its thumbnails by size name.
+type Processed struct {
+	ContentType string
+	Extension   string
+	Original    Encoded
+	Thumbnails  map[string]Encoded
+}
+
+// Process validates an uploaded image and re-encodes it. Photos are turned
+// upright according to their EXIF orientation before the metadata is
+// dropped. Animated GIFs keep only their first frame.
+func Process(data []byte, maxPixels int) (*Processed, error) {
+	var format string
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		format = "jpeg"
+	case "image/png":
+		format = "png"
+	case "image/gif":
+		format = "gif"
+	default:
+		return nil, ErrUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > int64(maxPixels) {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	// Photos stay JPEG; everything else becomes PNG to keep transparency
+	encode := encodePNG
+	processed := &Processed{ContentType: "image/png", Extension: "png"}
+	if format == "jpeg" {
+		encode = encodeJPEG
+		processed.ContentType, processed.Extension = "image/jpeg", "jpg"
+	}
+
+	if processed.Original, err = encode(img); err != nil {
+		return nil, err
+	}
+
+	processed.Thumbnails = make(map[string]Encoded, len(Sizes))
+	for _, size := range Sizes {
+		thumb, err := encode(Fit(img, size.MaxDimension))
+		if err != nil {
+			return nil, err
+		}
+		processed.Thumbnails[size.Name] = thumb
+	}
+
+	return processed, nil
+}
+
+func encodeJPEG(img image.Image) (Encoded, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return Encoded{}, err
+	}
+	b := img.Bounds()
+	return Encoded{Data: buf.Bytes(), Width: b.Dx(), Height: b.Dy()}, nil
+}
+
+func encodePNG(img image.Image) (Encoded, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return Encoded{}, err
+	}
+	b := img.Bounds()
+	return Encoded{Data: buf.Bytes(), Width: b.Dx(), Height: b.Dy()}, nil
+}
@@ -0,0 +1,176 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// halves returns an image whose left half is red and right half blue.
+func halves(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF segment with the given orientation
+// right after the start of a JPEG file.
+func withOrientation(t *testing.T, jpg []byte, orientation byte) []byte {
+	t.Helper()
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // big-endian header, IFD0 at offset 8
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, // orientation, SHORT, count 1
+		0, 0, 0, 0, // no next IFD
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, 0, byte(len(payload) + 2)}, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func encode(t *testing.T, img image.Image, enc func(*bytes.Buffer, image.Image) error) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, enc(&buf, img))
+	return buf.Bytes()
+}
+
+func encodeJPEGFile(buf *bytes.Buffer, img image.Image) error {
+	return jpeg.Encode(buf, img, &jpeg.Options{Quality: 95})
+}
+
+func encodePNGFile(buf *bytes.Buffer, img image.Image) error {
+	return png.Encode(buf, img)
+}
+
+func assertMostlyRed(t *testing.T, c color.Color) {
+	t.Helper()
+	r, g, b, _ := c.RGBA()
+	assert.Greater(t, r, uint32(0xC000))
+	assert.Less(t, g, uint32(0x4000))
+	assert.Less(t, b, uint32(0x4000))
+}
+
+func TestProcess_JPEG(t *testing.T) {
+	photo := withOrientation(t, encode(t, halves(2000, 1000), encodeJPEGFile), 6)
+	require.Equal(t, 6, jpegOrientation(photo))
+
+	processed, err := Process(photo, DefaultMaxPixels)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", processed.ContentType)
+	assert.Equal(t, "jpg", processed.Extension)
+
+	// Rotated upright, so the left half of the stored pixels is now on top
+	assert.Equal(t, 1000, processed.Original.Width)
+	assert.Equal(t, 2000, processed.Original.Height)
+	assert.NotContains(t, string(processed.Original.Data), "Exif")
+	assert.Equal(t, orientationNormal, jpegOrientation(processed.Original.Data))
+
+	upright, err := jpeg.Decode(bytes.NewReader(processed.Original.Data))
+	require.NoError(t, err)
+	assertMostlyRed(t, upright.At(500, 100))
+
+	require.Len(t, processed.Thumbnails, len(Sizes))
+	for _, size := range Sizes {
+		thumb := processed.Thumbnails[size.Name]
+		assert.Equal(t, size.MaxDimension, thumb.Height, size.Name)
+		assert.Equal(t, size.MaxDimension/2, thumb.Width, size.Name)
+
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(thumb.Data))
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, thumb.Width, cfg.Width)
+	}
+}
+
+func TestProcess_PNGAndGIF(t *testing.T) {
+	transparent := image.NewNRGBA(image.Rect(0, 0, 600, 300))
+	transparent.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+
+	processed, err := Process(encode(t, transparent, encodePNGFile), DefaultMaxPixels)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", processed.ContentType)
+	assert.Equal(t, 600, processed.Original.Width)
+
+	small, err := png.Decode(bytes.NewReader(processed.Thumbnails["small"].Data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 160, 80), small.Bounds())
+	_, _, _, a := small.At(80, 40).RGBA()
+	assert.Zero(t, a, "transparency is kept")
+
+	// Images smaller than a thumbnail size are not scaled up
+	assert.Equal(t, 600, processed.Thumbnails["large"].Width)
+
+	var animated bytes.Buffer
+	frame := image.NewPaletted(image.Rect(0, 0, 10, 10), color.Palette{color.Black, color.White})
+	require.NoError(t, gif.EncodeAll(&animated, &gif.GIF{Image: []*image.Paletted{frame, frame}, Delay: []int{10, 10}}))
+
+	processed, err = Process(animated.Bytes(), DefaultMaxPixels)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", processed.ContentType)
+	assert.Equal(t, 10, processed.Original.Width)
+}
+
+func TestProcess_Rejects(t *testing.T) {
+	_, err := Process([]byte("<svg xmlns='http://www.w3.org/2000/svg'></svg>"), DefaultMaxPixels)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Process([]byte("%PDF-1.7"), DefaultMaxPixels)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	// Sniffed as PNG but not decodable
+	_, err = Process([]byte("\x89PNG\x0D\x0A\x1A\x0Agarbage"), DefaultMaxPixels)
+	assert.ErrorIs(t, err, ErrInvalidImage)
+
+	_, err = Process(encode(t, halves(100, 100), encodePNGFile), 100*99)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestOrient(t *testing.T) {
+	// A 2x1 image: red on the left, blue on the right
+	src := halves(2, 1)
+	red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+
+	tests := map[int][]color.RGBA{
+		orientationNormal:     {red, blue},
+		orientationFlipH:      {blue, red},
+		orientationRotate180:  {blue, red},
+		orientationFlipV:      {red, blue},
+		orientationTranspose:  {red, blue},
+		orientationRotate90:   {red, blue},
+		orientationTransverse: {blue, red},
+		orientationRotate270:  {blue, red},
+	}
+	for orientation, want := range tests {
+		got := toRGBA(orient(src, orientation))
+		var pixels []color.RGBA
+		for y := 0; y < got.Rect.Dy(); y++ {
+			for x := 0; x < got.Rect.Dx(); x++ {
+				pixels = append(pixels, got.RGBAAt(x, y))
+			}
+		}
+		assert.Equal(t, want, pixels, "orientation %d", orientation)
+
+		if orientation >= orientationTranspose {
+			assert.Equal(t, image.Rect(0, 0, 1, 2), got.Rect, "orientation %d", orientation)
+		}
+	}
+}
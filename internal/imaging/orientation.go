@@ -0,0 +1,142 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// EXIF orientation values, describing how the stored pixels must be
+// transformed to display the photo upright.
+const (
+	orientationNormal     = 1
+	orientationFlipH      = 2
+	orientationRotate180  = 3
+	orientationFlipV      = 4
+	orientationTranspose  = 5
+	orientationRotate90   = 6
+	orientationTransverse = 7
+	orientationRotate270  = 8
+)
+
+// orient applies an EXIF orientation so that the image no longer depends
+// on the metadata that re-encoding drops.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= orientationNormal || orientation > orientationRotate270 {
+		return img
+	}
+
+	src := toRGBA(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= orientationTranspose {
+		dw, dh = h, w
+	}
+
+	// source returns the source pixel shown at (x, y) of the result
+	var source func(x, y int) (int, int)
+	switch orientation {
+	case orientationFlipH:
+		source = func(x, y int) (int, int) { return w - 1 - x, y }
+	case orientationRotate180:
+		source = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case orientationFlipV:
+		source = func(x, y int) (int, int) { return x, h - 1 - y }
+	case orientationTranspose:
+		source = func(x, y int) (int, int) { return y, x }
+	case orientationRotate90:
+		source = func(x, y int) (int, int) { return y, h - 1 - x }
+	case orientationTransverse:
+		source = func(x, y int) (int, int) { return w - 1 - y, h - 1 - x }
+	case orientationRotate270:
+		source = func(x, y int) (int, int) { return w - 1 - y, x }
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := source(x, y)
+			s := src.PixOffset(sx, sy)
+			copy(dst.Pix[dst.PixOffset(x, y):], src.Pix[s:s+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the orientation tag from the EXIF segment of a
+// JPEG file. Files without a readable tag are treated as upright.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return orientationNormal
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return orientationNormal
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			// fill byte before the marker
+			i++
+			continue
+		case marker == 0xD9 || marker == 0xDA:
+			// metadata segments come before the image data
+			return orientationNormal
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return orientationNormal
+		}
+		if marker == 0xE1 {
+			if orientation, ok := exifOrientation(data[i+4 : i+2+length]); ok {
+				return orientation
+			}
+		}
+		i += 2 + length
+	}
+	return orientationNormal
+}
+
+func exifOrientation(segment []byte) (int, bool) {
+	if len(segment) < 14 || string(segment[:6]) != "Exif\x00\x00" {
+		return 0, false
+	}
+
+	tiff := segment[6:]
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 0, false
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0, false
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		entry := ifd + 2 + e*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) != 0x0112 {
+			continue
+		}
+		// A SHORT value is stored at the start of the value field
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < orientationNormal || orientation > orientationRotate270 {
+			return 0, false
+		}
+		return orientation, true
+	}
+	return 0, false
+}
@@ -0,0 +1,69 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Fit scales the image down so that neither side exceeds maxDimension,
+// keeping its aspect ratio. Smaller images are returned unchanged.
+func Fit(img image.Image, maxDimension int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxDimension && h <= maxDimension {
+		return img
+	}
+
+	dw, dh := maxDimension, maxDimension
+	if w >= h {
+		dh = max(1, h*maxDimension/w)
+	} else {
+		dw = max(1, w*maxDimension/h)
+	}
+	return resize(toRGBA(img), dw, dh)
+}
+
+// resize shrinks src by averaging the block of source pixels that falls on
+// each destination pixel. The colors are premultiplied, so transparent
+// pixels do not darken their neighbours.
+func resize(src *image.RGBA, dw, dh int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+
+			var sum [4]uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[src.PixOffset(x0, sy):src.PixOffset(x1, sy)]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += uint64(row[i])
+					sum[1] += uint64(row[i+1])
+					sum[2] += uint64(row[i+2])
+					sum[3] += uint64(row[i+3])
+				}
+			}
+
+			n := uint64((x1 - x0) * (y1 - y0))
+			o := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[o+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// toRGBA returns the image as RGBA with its origin at (0, 0), converting it
+// if necessary.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,84 @@
+package linkpreview
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	meta      Metadata
+	expiresAt time.Time
+}
+
+// cache keeps recent previews in memory. When it is full, expired entries
+// are dropped first, then the entries closest to expiry.
+type cache struct {
+	ttl  time.Duration
+	size int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newCache(ttl time.Duration, size int) *cache {
+	return &cache{
+		ttl:     ttl,
+		size:    size,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// get returns a copy of the cached metadata, so callers may modify it.
+func (c *cache) get(key string) (*Metadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	meta := entry.meta
+	if meta.Price != nil {
+		price := *meta.Price
+		meta.Price = &price
+	}
+	return &meta, true
+}
+
+func (c *cache) set(key string, meta *Metadata) {
+	if c.ttl <= 0 || c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[key] = cacheEntry{meta: *meta, expiresAt: now.Add(c.ttl)}
+}
+
+func (c *cache) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	if len(c.entries) >= c.size {
+		delete(c.entries, oldestKey)
+	}
+}
@@ -0,0 +1,13 @@
+package linkpreview
+
+import "wishlist/internal/config"
+
+// OptionsFromConfig reads the LINK_PREVIEW_* settings, keeping the defaults
+// for those that are not set.
+func OptionsFromConfig(cfg *config.Config) Options {
+	opts := DefaultOptions()
+	opts.Timeout = config.ParseDuration(cfg.LinkPreviewTimeout, opts.Timeout)
+	opts.MaxBodyBytes = int64(config.ParseInt(cfg.LinkPreviewMaxBytes, int(opts.MaxBodyBytes)))
+	opts.CacheTTL = config.ParseDuration(cfg.LinkPreviewCacheTTL, opts.CacheTTL)
+	return opts
+}
@@ -0,0 +1,307 @@
+package linkpreview
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/text/currency"
+	"wishlist/internal/domain"
+)
+
+// Limits match the item validation rules so that a preview can be saved
+// as is.
+const (
+	maxNameLength        = 200
+	maxDescriptionLength = 1000
+	maxURLLength         = 500
+)
+
+// document collects the markup that metadata is taken from.
+type document struct {
+	title    string
+	meta     map[string]string
+	links    map[string]string
+	jsonLD   []string
+	products []map[string]interface{}
+}
+
+// Extract reads the metadata of an HTML page. schema.org Product markup
+// (JSON-LD) is preferred, then OpenGraph, then Twitter cards and finally the
+// plain title and description. Relative URLs are resolved against pageURL.
+func Extract(r io.Reader, pageURL *url.URL) (*Metadata, error) {
+	root, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &document{meta: map[string]string{}, links: map[string]string{}}
+	doc.walk(root)
+	for _, raw := range doc.jsonLD {
+		var data interface{}
+		decoder := json.NewDecoder(strings.NewReader(raw))
+		decoder.UseNumber()
+		if decoder.Decode(&data) == nil {
+			findProducts(data, &doc.products)
+		}
+	}
+
+	var product map[string]interface{}
+	if len(doc.products) > 0 {
+		product = doc.products[0]
+	}
+	offer := firstOffer(product)
+
+	meta := &Metadata{
+		URL: pageURL.String(),
+		Name: truncate(firstNonEmpty(
+			stringValue(product["name"]),
+			doc.meta["og:title"],
+			doc.meta["twitter:title"],
+			doc.title,
+		), maxNameLength),
+		Description: truncate(firstNonEmpty(
+			stringValue(product["description"]),
+			doc.meta["og:description"],
+			doc.meta["twitter:description"],
+			doc.meta["description"],
+		), maxDescriptionLength),
+		ImageURL: resolveURL(pageURL, firstNonEmpty(
+			imageValue(product["image"]),
+			doc.meta["og:image:secure_url"],
+			doc.meta["og:image"],
+			doc.meta["twitter:image"],
+			doc.meta["twitter:image:src"],
+			doc.links["image_src"],
+		)),
+		SiteName: doc.meta["og:site_name"],
+	}
+
+	if canonical := resolveURL(pageURL, doc.meta["og:url"]); canonical != "" {
+		meta.URL = canonical
+	}
+
+	price := firstNonEmpty(
+		offerValue(offer, "price", "lowPrice"),
+		doc.meta["product:price:amount"],
+		doc.meta["og:price:amount"],
+		doc.meta["price"],
+	)
+	if d, ok := parsePrice(price); ok {
+		meta.Price = &d
+	}
+
+	code := firstNonEmpty(
+		offerValue(offer, "priceCurrency"),
+		doc.meta["product:price:currency"],
+		doc.meta["og:price:currency"],
+		doc.meta["pricecurrency"],
+	)
+	if unit, err := currency.ParseISO(strings.TrimSpace(code)); err == nil {
+		meta.Currency = unit.String()
+	}
+
+	return meta, nil
+}
+
+func (d *document) walk(n *html.Node) {
+	if n.Type == html.ElementNode {
+		switch n.DataAtom {
+		case atom.Title:
+			if d.title == "" && n.Namespace == "" && n.FirstChild != nil && n.FirstChild.Type == html.TextNode {
+				d.title = strings.TrimSpace(n.FirstChild.Data)
+			}
+		case atom.Meta:
+			key := strings.ToLower(firstNonEmpty(attr(n, "property"), attr(n, "name"), attr(n, "itemprop")))
+			content := strings.TrimSpace(attr(n, "content"))
+			// The first occurrence wins; pages list their main image first.
+			if _, seen := d.meta[key]; key != "" && content != "" && !seen {
+				d.meta[key] = content
+			}
+		case atom.Link:
+			for _, rel := range strings.Fields(strings.ToLower(attr(n, "rel"))) {
+				if _, seen := d.links[rel]; !seen {
+					d.links[rel] = attr(n, "href")
+				}
+			}
+		case atom.Script:
+			if strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json") && n.FirstChild != nil {
+				d.jsonLD = append(d.jsonLD, n.FirstChild.Data)
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		d.walk(c)
+	}
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && strings.EqualFold(a.Key, name) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// findProducts collects the Product objects of a JSON-LD document, which
+// may be a single object, an array or an object with a @graph.
+func findProducts(data interface{}, products *[]map[string]interface{}) {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			findProducts(item, products)
+		}
+	case map[string]interface{}:
+		if hasType(v["@type"], "Product") {
+			*products = append(*products, v)
+			return
+		}
+		findProducts(v["@graph"], products)
+	}
+}
+
+func hasType(value interface{}, want string) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.EqualFold(v, want) || strings.HasSuffix(v, "/"+want)
+	case []interface{}:
+		for _, t := range v {
+			if hasType(t, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firstOffer returns the first offer of the product, looking into
+// AggregateOffer and price specifications.
+func firstOffer(product map[string]interface{}) map[string]interface{} {
+	offers := product["offers"]
+	if list, ok := offers.([]interface{}); ok {
+		if len(list) == 0 {
+			return nil
+		}
+		offers = list[0]
+	}
+	offer, ok := offers.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if offerValue(offer, "price", "lowPrice") == "" {
+		if spec, ok := offer["priceSpecification"].(map[string]interface{}); ok {
+			return spec
+		}
+	}
+	return offer
+}
+
+func offerValue(offer map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if v := stringValue(offer[key]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(html.UnescapeString(v))
+	case json.Number:
+		return v.String()
+	case []interface{}:
+		if len(v) > 0 {
+			return stringValue(v[0])
+		}
+	}
+	return ""
+}
+
+// imageValue reads a schema.org image, which is a URL, an ImageObject or a
+// list of either.
+func imageValue(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		if len(v) > 0 {
+			return imageValue(v[0])
+		}
+	case map[string]interface{}:
+		return firstNonEmpty(stringValue(v["url"]), stringValue(v["contentUrl"]))
+	}
+	return stringValue(value)
+}
+
+// parsePrice reads amounts such as "1299.00", "1,299.00" or "1.299,00".
+// Machine-readable prices use '.' as the decimal separator; when a comma is
+// present, whichever of '.' and ',' comes last is taken as the decimal
+// separator, unless commas are the only separators and either repeat or
+// are followed by three digits, as in "1,299".
+func parsePrice(s string) (domain.Decimal, bool) {
+	s = strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' {
+			return r
+		}
+		return -1
+	}, s)
+
+	if strings.Contains(s, ",") {
+		sep := strings.LastIndexAny(s, ".,")
+		integer, fraction := s[:sep], s[sep+1:]
+		thousands := !strings.Contains(s, ".") && (len(fraction) == 3 || strings.Count(s, ",") > 1)
+		integer = strings.NewReplacer(".", "", ",", "").Replace(integer)
+		if thousands {
+			s = integer + fraction
+		} else {
+			s = integer + "." + fraction
+		}
+	}
+
+	d, err := domain.ParseDecimal(s)
+	if err != nil {
+		return "", false
+	}
+	return d, true
+}
+
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	if s := u.String(); len(s) <= maxURLLength {
+		return s
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// truncate shortens s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[:n]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,147 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// blockedPrefixes are special-purpose ranges that are not covered by the
+// netip.Addr predicates but must not be reachable either.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// publicAddress reports whether addr is a globally routable unicast
+// address, i.e. not loopback, private, link-local or otherwise reserved.
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+type page struct {
+	url  *url.URL
+	body io.ReadCloser
+}
+
+type fetcher struct {
+	client    *http.Client
+	maxBytes  int64
+	userAgent string
+}
+
+func newFetcher(opts Options) *fetcher {
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivateNetworks {
+		// The address is checked after DNS resolution, right before
+		// connecting, so a hostname cannot be rebound to an internal
+		// address between the check and the request.
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !publicAddress(addrPort.Addr()) {
+				return ErrBlockedAddress
+			}
+			return nil
+		}
+	}
+
+	transport := &http.Transport{
+		// Never use a proxy: it would make the request on our behalf
+		// and bypass the address check.
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	return &fetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > opts.MaxRedirects {
+					return errors.New("too many redirects")
+				}
+				if _, err := parseURL(req.URL.String()); err != nil {
+					return fmt.Errorf("redirect to unsupported URL %q", req.URL.Redacted())
+				}
+				return nil
+			},
+		},
+		maxBytes:  opts.MaxBodyBytes,
+		userAgent: opts.UserAgent,
+	}
+}
+
+// fetch downloads an HTML page. The returned body is decoded to UTF-8 and
+// cut off after maxBytes; metadata lives in the document head, so a
+// truncated page is still useful.
+func (f *fetcher) fetch(ctx context.Context, u *url.URL) (*page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlockedAddress) {
+			return nil, ErrBlockedAddress
+		}
+		return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrFetchFailed, resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		resp.Body.Close()
+		return nil, ErrUnsupportedContent
+	}
+
+	limited := io.LimitReader(resp.Body, f.maxBytes)
+	body, err := charset.NewReader(limited, contentType)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+	}
+
+	return &page{
+		url:  resp.Request.URL,
+		body: readCloser{Reader: body, Closer: resp.Body},
+	}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
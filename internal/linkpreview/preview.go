@@ -0,0 +1,125 @@
+// Package linkpreview extracts product details from web pages so that items
+// added by URL can be pre-filled. Pages are fetched with strict limits and
+// only from public addresses, since the URL comes from the user.
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"wishlist/internal/domain"
+)
+
+var (
+	ErrInvalidURL         = errors.New("invalid URL")
+	ErrBlockedAddress     = errors.New("address not allowed")
+	ErrUnsupportedContent = errors.New("page is not an HTML document")
+	ErrFetchFailed        = errors.New("fetching the page failed")
+)
+
+// Metadata is what could be learned about a product from its page. Fields
+// that the page does not provide are left empty.
+type Metadata struct {
+	URL         string          `json:"url"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Price       *domain.Decimal `json:"price"`
+	Currency    string          `json:"currency"`
+	ImageURL    string          `json:"image_url"`
+	SiteName    string          `json:"site_name"`
+}
+
+// Options bound the work done for a single preview.
+type Options struct {
+	Timeout      time.Duration
+	MaxBodyBytes int64
+	MaxRedirects int
+	UserAgent    string
+	CacheTTL     time.Duration
+	CacheSize    int
+	// AllowPrivateNetworks permits fetching from loopback and private
+	// addresses. It disables SSRF protection and is meant for tests only.
+	AllowPrivateNetworks bool
+}
+
+// DefaultOptions returns the limits used unless configured otherwise.
+func DefaultOptions() Options {
+	return Options{
+		Timeout:      5 * time.Second,
+		MaxBodyBytes: 1 << 20,
+		MaxRedirects: 5,
+		UserAgent:    "WishlistBot/1.0 (+link preview)",
+		CacheTTL:     time.Hour,
+		CacheSize:    1000,
+	}
+}
+
+// Previewer fetches pages and extracts their metadata. Results are cached
+// per URL for Options.CacheTTL.
+type Previewer struct {
+	fetcher *fetcher
+	cache   *cache
+}
+
+func NewPreviewer(opts Options) *Previewer {
+	return &Previewer{
+		fetcher: newFetcher(opts),
+		cache:   newCache(opts.CacheTTL, opts.CacheSize),
+	}
+}
+
+// Preview returns the metadata of the page at rawURL, from the cache when
+// it was fetched recently.
+func (p *Previewer) Preview(ctx context.Context, rawURL string) (*Metadata, error) {
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	key := u.String()
+	if meta, ok := p.cache.get(key); ok {
+		return meta, nil
+	}
+
+	meta, err := p.Fetch(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	p.cache.set(key, meta)
+	return meta, nil
+}
+
+// Fetch is like Preview but always fetches the page.
+func (p *Previewer) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := p.fetcher.fetch(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer page.body.Close()
+
+	return Extract(page.body, page.url)
+}
+
+// parseURL accepts absolute http and https URLs and drops the fragment,
+// which is never sent to the server.
+func parseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: an absolute http or https URL is required", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("%w: credentials are not allowed", ErrInvalidURL)
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u, nil
+}
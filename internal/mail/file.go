@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// FileMailer writes every message as an .eml file into a directory instead
+// of sending it, which is handy for local development.
+type FileMailer struct {
+	dir  string
+	from string
+	seq  atomic.Uint64
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().Format("20060102-150405.000000"), m.seq.Add(1))
+	return os.WriteFile(filepath.Join(m.dir, name), format(m.from, msg), 0o644)
+}
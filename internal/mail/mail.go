@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wishlist/internal/config"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewFromConfig returns the mailer selected by MAIL_DRIVER: "smtp" for real
+// delivery, "file" (default) to drop messages into MAIL_DIR for local
+// development, or "memory" to keep them in process.
+func NewFromConfig(cfg *config.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case "", "file":
+		return NewFileMailer(cfg.MailDir, cfg.MailFrom)
+	case "memory":
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", cfg.MailDriver)
+	}
+}
+
+// format renders the message in RFC 5322 format.
+func format(from string, msg Message) []byte {
+	return []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n"+
+		"MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from, msg.To, msg.Subject, time.Now().Format(time.RFC1123Z), msg.Body))
+}
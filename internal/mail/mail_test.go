@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	mailer, err := NewFileMailer(dir, "noreply@example.com")
+	require.NoError(t, err)
+
+	err = mailer.Send(context.Background(), Message{
+		To:      "user@example.com",
+		Subject: "Hello",
+		Body:    "Hi there",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "From: noreply@example.com\r\n")
+	assert.Contains(t, string(content), "To: user@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: Hello\r\n")
+	assert.Contains(t, string(content), "Hi there")
+}
+
+func TestMemoryMailer(t *testing.T) {
+	mailer := NewMemoryMailer()
+	ctx := context.Background()
+
+	require.NoError(t, mailer.Send(ctx, Message{To: "a@example.com", Subject: "first"}))
+	require.NoError(t, mailer.Send(ctx, Message{To: "b@example.com", Subject: "other"}))
+	require.NoError(t, mailer.Send(ctx, Message{To: "a@example.com", Subject: "second"}))
+
+	assert.Len(t, mailer.Messages(), 3)
+
+	last, ok := mailer.Last("a@example.com")
+	require.True(t, ok)
+	assert.Equal(t, "second", last.Subject)
+
+	_, ok = mailer.Last("nobody@example.com")
+	assert.False(t, ok)
+}
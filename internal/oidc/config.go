@@ -0,0 +1,33 @@
+package oidc
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"wishlist/internal/config"
+)
+
+// providerTimeout bounds every request to a provider.
+const providerTimeout = 10 * time.Second
+
+// ProvidersFromConfig creates the providers configured in OIDC_PROVIDERS.
+func ProvidersFromConfig(cfg *config.Config) ([]*Provider, error) {
+	client := &http.Client{Timeout: providerTimeout}
+
+	providers := make([]*Provider, 0, len(cfg.OIDCProviders))
+	for _, p := range cfg.OIDCProviders {
+		if p.IssuerURL == "" || p.ClientID == "" {
+			return nil, fmt.Errorf("OIDC provider %q needs an issuer URL and a client ID", p.Name)
+		}
+		providers = append(providers, NewProvider(Config{
+			Name:         p.Name,
+			IssuerURL:    p.IssuerURL,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}, client))
+	}
+	return providers, nil
+}
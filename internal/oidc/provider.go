@@ -0,0 +1,321 @@
+// Package oidc implements the relying-party side of OpenID Connect login
+// with the authorization code flow and PKCE.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"wishlist/internal/auth"
+)
+
+var (
+	ErrInvalidIDToken = errors.New("invalid ID token")
+	ErrExchangeFailed = errors.New("authorization code exchange failed")
+)
+
+// DefaultScopes are requested when a provider does not configure its own.
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+const (
+	// keysRefreshInterval limits how often the provider JWKS is refetched
+	// when a token is signed by an unknown key.
+	keysRefreshInterval = time.Minute
+	maxResponseBytes    = 1 << 20
+)
+
+// Config describes a provider registered with the application.
+type Config struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Claims are the identity claims taken from a verified ID token.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type verificationKey struct {
+	key    crypto.PublicKey
+	method jwt.SigningMethod
+}
+
+// Provider talks to one OpenID provider. Its discovery document and keys are
+// fetched lazily and cached.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *discoveryDocument
+	keys          map[string]verificationKey
+	keysFetchedAt time.Time
+}
+
+// NewProvider creates a provider. A nil client uses http.DefaultClient.
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = DefaultScopes
+	}
+	cfg.IssuerURL = strings.TrimRight(cfg.IssuerURL, "/")
+	return &Provider{cfg: cfg, client: client}
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL returns the address to send the user to. The PKCE challenge is
+// derived from verifier, which must be kept until Exchange.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", PKCEChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the authorization code and returns the verified claims
+// of the ID token.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := p.doJSON(req, &token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id_token in response", ErrExchangeFailed)
+	}
+
+	return p.VerifyIDToken(ctx, token.IDToken, nonce)
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of
+// the ID token and returns its claims.
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*Claims, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims idTokenClaims
+	_, err = jwt.ParseWithClaims(raw, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, ErrInvalidIDToken
+		}
+		return key.key, nil
+	},
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Nonce != nonce || claims.Subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	return &Claims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+type idTokenClaims struct {
+	Nonce         string       `json:"nonce"`
+	Email         string       `json:"email"`
+	EmailVerified flexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// flexibleBool accepts both true and "true"; some providers send the
+// email_verified claim as a string.
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.IssuerURL+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc discoveryDocument
+	if err := p.doJSON(req, &doc); err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", p.cfg.Name, err)
+	}
+
+	// The issuer must match exactly, otherwise tokens could be accepted from
+	// a different provider serving the same document (OIDC Discovery 4.3).
+	if strings.TrimRight(doc.Issuer, "/") != p.cfg.IssuerURL {
+		return nil, fmt.Errorf("issuer mismatch for %s: %q", p.cfg.Name, doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("incomplete discovery document for %s", p.cfg.Name)
+	}
+
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+// key returns the verification key with the ID, refetching the JWKS when
+// the provider has rotated its keys.
+func (p *Provider) key(ctx context.Context, kid string) (verificationKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	stale := time.Since(p.keysFetchedAt) > keysRefreshInterval
+	jwksURI := p.discovery.JWKSURI
+	p.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+	if !stale {
+		return verificationKey{}, ErrInvalidIDToken
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return verificationKey{}, err
+	}
+
+	var set auth.JWKSet
+	if err := p.doJSON(req, &set); err != nil {
+		return verificationKey{}, fmt.Errorf("failed to fetch keys of %s: %w", p.cfg.Name, err)
+	}
+
+	keys := make(map[string]verificationKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		publicKey, method, err := jwk.PublicKey()
+		if err != nil {
+			// Skip key types we do not support rather than failing
+			continue
+		}
+		keys[jwk.KeyID] = verificationKey{key: publicKey, method: method}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+
+	key, ok = keys[kid]
+	if !ok {
+		return verificationKey{}, ErrInvalidIDToken
+	}
+	return key, nil
+}
+
+func (p *Provider) doJSON(req *http.Request, v interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d: %s", req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, v)
+}
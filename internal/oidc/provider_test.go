@@ -0,0 +1,109 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"wishlist/internal/oidc/oidctest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(idp *oidctest.Server) *Provider {
+	return NewProvider(Config{
+		Name:         "test",
+		IssuerURL:    idp.Issuer(),
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  "http://localhost:5173/oauth/callback/test",
+	}, nil)
+}
+
+func TestProvider_AuthorizationCodeFlow(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+	idp.SetUser(oidctest.User{Subject: "user-1", Email: "alice@example.com", EmailVerified: true, Name: "Alice"})
+
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+
+	verifier, err := NewRandomString()
+	require.NoError(t, err)
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, PKCEChallenge(verifier), parsed.Query().Get("code_challenge"))
+	assert.Equal(t, "openid email profile", parsed.Query().Get("scope"))
+
+	code, state, err := idp.Login(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "state-1", state)
+
+	t.Run("wrong verifier is rejected", func(t *testing.T) {
+		code, _, err := idp.Login(authURL)
+		require.NoError(t, err)
+
+		other, err := NewRandomString()
+		require.NoError(t, err)
+		_, err = provider.Exchange(ctx, code, other, "nonce-1")
+		assert.ErrorIs(t, err, ErrExchangeFailed)
+	})
+
+	t.Run("exchange", func(t *testing.T) {
+		claims, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.Subject)
+		assert.Equal(t, "alice@example.com", claims.Email)
+		assert.True(t, claims.EmailVerified)
+		assert.Equal(t, "Alice", claims.Name)
+	})
+}
+
+func TestProvider_VerifyIDToken(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+	user := oidctest.User{Subject: "user-2", Email: "bob@example.com"}
+
+	valid := idp.IDToken(user, "n", oidctest.ClientID, time.Now().Add(time.Hour))
+	claims, err := provider.VerifyIDToken(ctx, valid, "n")
+	require.NoError(t, err)
+	assert.False(t, claims.EmailVerified)
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+	}{
+		{"wrong nonce", valid, "other"},
+		{"wrong audience", idp.IDToken(user, "n", "someone-else", time.Now().Add(time.Hour)), "n"},
+		{"expired", idp.IDToken(user, "n", oidctest.ClientID, time.Now().Add(-time.Hour)), "n"},
+		{"tampered", valid + "x", "n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.VerifyIDToken(ctx, tt.token, tt.nonce)
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	verifier, err := NewRandomString()
+	require.NoError(t, err)
+	assert.Len(t, verifier, 43)
+
+	challenge := PKCEChallenge(verifier)
+	assert.Len(t, challenge, 43, "base64url of a SHA-256 digest without padding")
+	assert.NotEqual(t, verifier, challenge)
+	assert.Equal(t, challenge, PKCEChallenge(verifier))
+}
@@ -0,0 +1,23 @@
+package ratelimit
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"wishlist/internal/config"
+	"wishlist/internal/repository"
+)
+
+// StoreFromConfig returns the store selected by RATE_LIMIT_STORE: "memory"
+// (default) to count per process, or "postgres" to share the counters of
+// all replicas through the database.
+func StoreFromConfig(cfg *config.Config, db *gorm.DB) (Store, error) {
+	switch cfg.RateLimitStore {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		return repository.NewRateLimitRepository(db), nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limit store %q", cfg.RateLimitStore)
+	}
+}
@@ -0,0 +1,153 @@
+// Package ratelimit limits how often a client may call the API. Limits are
+// tracked per key with the generic cell rate algorithm, which needs a
+// single timestamp per key, so the state can live in memory or be shared
+// by several replicas through the database.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSweepInterval is how often idle keys are dropped from the store.
+const DefaultSweepInterval = time.Minute
+
+// Store keeps the theoretical arrival time (TAT) of each key: the moment at
+// which the key's bucket is full again.
+type Store interface {
+	// Update atomically replaces the TAT stored for key with the result of
+	// fn. A key without state is passed as the zero time.
+	Update(ctx context.Context, key string, fn func(tat time.Time) time.Time) error
+	// DeleteIdle drops keys whose TAT is before the given time. Their
+	// buckets are full, so forgetting them changes nothing.
+	DeleteIdle(ctx context.Context, before time.Time) error
+}
+
+// Policy allows Requests per Per on average, with bursts of up to Burst
+// requests.
+type Policy struct {
+	Name     string
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// ParsePolicy reads a rate such as "10/s" or "20/1m" and a burst size.
+// A burst of 0 defaults to the number of requests.
+func ParsePolicy(name, value string, burst int) (Policy, error) {
+	requests, per, ok := strings.Cut(value, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("invalid rate %q, expected <requests>/<duration>", value)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate %q: bad request count", value)
+	}
+
+	if per != "" && (per[0] < '0' || per[0] > '9') {
+		per = "1" + per
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Policy{}, fmt.Errorf("invalid rate %q: bad duration", value)
+	}
+
+	if burst <= 0 {
+		burst = n
+	}
+
+	return Policy{Name: name, Requests: n, Per: d, Burst: burst}, nil
+}
+
+func (p Policy) interval() time.Duration {
+	return p.Per / time.Duration(p.Requests)
+}
+
+// Result describes the state of a key after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long a rejected client has to wait.
+	RetryAfter time.Duration
+	// Reset is how long until the full burst is available again.
+	Reset time.Duration
+}
+
+// Limiter applies a policy to keys kept in a store.
+type Limiter struct {
+	store         Store
+	policy        Policy
+	sweepInterval time.Duration
+	now           func() time.Time
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewLimiter(store Store, policy Policy) *Limiter {
+	return &Limiter{
+		store:         store,
+		policy:        policy,
+		sweepInterval: DefaultSweepInterval,
+		now:           time.Now,
+	}
+}
+
+// Policy returns the policy the limiter enforces.
+func (l *Limiter) Policy() Policy {
+	return l.policy
+}
+
+// Allow counts a request for key and reports whether it may proceed.
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	now := l.now()
+	interval := l.policy.interval()
+	burstOffset := interval * time.Duration(l.policy.Burst)
+	result := Result{Limit: l.policy.Burst}
+
+	err := l.store.Update(ctx, l.policy.Name+":"+key, func(tat time.Time) time.Time {
+		if tat.Before(now) {
+			tat = now
+		}
+
+		next := tat.Add(interval)
+		allowAt := next.Add(-burstOffset)
+		if now.Before(allowAt) {
+			result.RetryAfter = allowAt.Sub(now)
+			result.Reset = tat.Sub(now)
+			return tat
+		}
+
+		result.Allowed = true
+		result.Remaining = int(now.Sub(allowAt) / interval)
+		result.Reset = next.Sub(now)
+		return next
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	l.sweep(ctx, now)
+	return result, nil
+}
+
+// sweep drops idle keys at most once per sweep interval so that the store
+// does not grow with every client ever seen.
+func (l *Limiter) sweep(ctx context.Context, now time.Time) {
+	l.mu.Lock()
+	if now.Sub(l.lastSweep) < l.sweepInterval {
+		l.mu.Unlock()
+		return
+	}
+	l.lastSweep = now
+	l.mu.Unlock()
+
+	// Failing to sweep only delays eviction until the next attempt.
+	_ = l.store.DeleteIdle(ctx, now)
+}
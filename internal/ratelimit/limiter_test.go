@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("api", "10/s", 30)
+	require.NoError(t, err)
+	assert.Equal(t, Policy{Name: "api", Requests: 10, Per: time.Second, Burst: 30}, policy)
+
+	policy, err = ParsePolicy("auth", "20/5m", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, policy.Per)
+	assert.Equal(t, 20, policy.Burst)
+
+	for _, value := range []string{"", "10", "x/s", "0/s", "10/x", "10/-1s"} {
+		_, err := ParsePolicy("bad", value, 0)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	limiter := NewLimiter(store, Policy{Name: "test", Requests: 1, Per: time.Second, Burst: 3})
+	limiter.now = func() time.Time { return now }
+
+	for i := 2; i >= 0; i-- {
+		result, err := limiter.Allow(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result, err := limiter.Allow(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// Other keys have their own budget.
+	result, err = limiter.Allow(ctx, "b")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// The budget refills at the configured rate.
+	now = now.Add(time.Second)
+	result, err = limiter.Allow(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestLimiter_EvictsIdleKeys(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	limiter := NewLimiter(store, Policy{Name: "test", Requests: 1, Per: time.Second, Burst: 5})
+	limiter.now = func() time.Time { return now }
+
+	_, err := limiter.Allow(ctx, "idle")
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Len())
+
+	now = now.Add(DefaultSweepInterval)
+	_, err = limiter.Allow(ctx, "active")
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Len(), "idle key should be evicted")
+}
@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps limits in process. Each replica counts on its own.
+type MemoryStore struct {
+	mu   sync.Mutex
+	tats map[string]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tats: make(map[string]time.Time)}
+}
+
+func (s *MemoryStore) Update(ctx context.Context, key string, fn func(tat time.Time) time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tats[key] = fn(s.tats[key])
+	return nil
+}
+
+func (s *MemoryStore) DeleteIdle(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, tat := range s.tats {
+		if tat.Before(before) {
+			delete(s.tats, key)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of keys being tracked.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.tats)
+}
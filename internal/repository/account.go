@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"wishlist/internal/domain"
+)
+
+// AnonymousClaimantName replaces the name on reservations of deleted users.
+const AnonymousClaimantName = "Deleted user"
+
+// AccountRepository gathers and erases all data of a user account.
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// Export loads everything stored about the user. It returns nil if the user
+// does not exist.
+func (r *AccountRepository) Export(userID uint) (*domain.AccountData, error) {
+	data := &domain.AccountData{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Preload("WishLists.Tags", orderTags).Preload("WishLists.Items", orderItems).
+			Preload("WishLists.Items.Images", orderImages).Preload("WishLists.Items.Tags", orderTags).
+			First(&user, userID).Error; err != nil {
+			return err
+		}
+		data.User = &user
+
+		queries := []struct {
+			dest  interface{}
+			query string
+			arg   interface{}
+		}{
+			{&data.Reservations, "user_id = ?", userID},
+			{&data.Collaborations, "user_id = ?", userID},
+			{&data.InvitationsSent, "invited_by_id = ?", userID},
+			{&data.InvitationsReceived, "email = ?", user.Email},
+			{&data.Sessions, "user_id = ?", userID},
+			{&data.PersonalAccessTokens, "user_id = ?", userID},
+			{&data.Identities, "user_id = ?", userID},
+			{&data.SecurityEvents, "user_id = ?", userID},
+			{&data.Tags, "user_id = ?", userID},
+		}
+		for _, q := range queries {
+			if err := tx.Where(q.query, q.arg).Order("id").Find(q.dest).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// FindDueForDeletion returns the users whose deletion grace period ended.
+func (r *AccountRepository) FindDueForDeletion(now time.Time) ([]*domain.User, error) {
+	var users []*domain.User
+	err := r.db.Where("deletion_due_at <= ?", now).Find(&users).Error
+	return users, err
+}
+
+// Purge erases the user. Data owned by the user goes with the account
+// through the foreign keys. Reservations on other users' wishlists are
+// kept, since the owners rely on them to avoid duplicate gifts, but no
+// longer identify the claimant.
+func (r *AccountRepository) Purge(user *domain.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.Reservation{}).
+			Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"user_id":        nil,
+				"claimant_name":  AnonymousClaimantName,
+				"claimant_email": "",
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("email = ?", user.Email).Delete(&domain.Invitation{}).Error; err != nil {
+			return err
+		}
+
+		// Events keep the address and IP even once unlinked from the user
+		email := strings.ToLower(user.Email)
+		err = tx.Where("user_id = ? OR email = ?", user.ID, email).
+			Delete(&domain.SecurityEvent{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("throttle_key IN ?", []string{"account:" + email, "mfa:" + email}).
+			Delete(&domain.LoginThrottle{}).Error
+		if err != nil {
+			return err
+		}
+
+		items := tx.Model(&domain.WishItem{}).Select("id").
+			Where("wishlist_id IN (?)", tx.Model(&domain.WishList{}).Select("id").Where("user_id = ?", user.ID))
+		if err := recordOrphanedBlobs(tx, items); err != nil {
+			return err
+		}
+
+		return tx.Delete(&domain.User{}, user.ID).Error
+	})
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"wishlist/internal/domain"
+)
+
+// AdminRepository serves the queries of the admin API, which look across
+// all accounts.
+type AdminRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminRepository(db *gorm.DB) *AdminRepository {
+	return &AdminRepository{db: db}
+}
+
+// SearchUsers returns a page of users whose email or display name contains
+// query, together with the total number of matches.
+func (r *AdminRepository) SearchUsers(query string, limit, offset int) ([]*domain.User, int64, error) {
+	db := r.db.Model(&domain.User{})
+	if query = strings.TrimSpace(query); query != "" {
+		pattern := "%" + escapeLike(query) + "%"
+		db = db.Where("email ILIKE ? OR display_name ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*domain.User
+	if err := db.Order("id").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// FindWishLists returns the wishlists of the user with their items.
+func (r *AdminRepository) FindWishLists(userID uint) ([]*domain.WishList, error) {
+	var wishlists []*domain.WishList
+	err := r.db.Preload("Items", orderItems).Preload("Items.Images", orderImages).Where("user_id = ?", userID).Order("id").Find(&wishlists).Error
+	return wishlists, err
+}
+
+// FindUsersByEmails returns the users with any of the given addresses.
+func (r *AdminRepository) FindUsersByEmails(emails []string) ([]*domain.User, error) {
+	var users []*domain.User
+	err := r.db.Where("email IN ?", emails).Find(&users).Error
+	return users, err
+}
+
+func (r *AdminRepository) Stats(now time.Time) (*domain.SystemStats, error) {
+	stats := &domain.SystemStats{}
+	counts := []struct {
+		dest  *int64
+		model interface{}
+		query string
+		args  []interface{}
+	}{
+		{&stats.Users, &domain.User{}, "", nil},
+		{&stats.VerifiedUsers, &domain.User{}, "email_verified_at IS NOT NULL", nil},
+		{&stats.DisabledUsers, &domain.User{}, "disabled_at IS NOT NULL", nil},
+		{&stats.Admins, &domain.User{}, "role = ?", []interface{}{domain.UserRoleAdmin}},
+		{&stats.NewUsersLastWeek, &domain.User{}, "created_at > ?", []interface{}{now.AddDate(0, 0, -7)}},
+		{&stats.WishLists, &domain.WishList{}, "", nil},
+		{&stats.PublicWishLists, &domain.WishList{}, "is_public", nil},
+		{&stats.Items, &domain.WishItem{}, "", nil},
+		{&stats.Reservations, &domain.Reservation{}, "", nil},
+		{&stats.ActiveSessions, &domain.TokenFamily{}, "revoked_at IS NULL AND expires_at > ?", []interface{}{now}},
+	}
+
+	for _, c := range counts {
+		db := r.db.Model(c.model)
+		if c.query != "" {
+			db = db.Where(c.query, c.args...)
+		}
+		if err := db.Count(c.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
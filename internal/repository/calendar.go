@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"wishlist/internal/domain"
+)
+
+type CalendarFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarFeedRepository(db *gorm.DB) *CalendarFeedRepository {
+	return &CalendarFeedRepository{db: db}
+}
+
+// Replace stores the feed in place of the current feed of its user, whose
+// token stops working.
+func (r *CalendarFeedRepository) Replace(feed *domain.CalendarFeed) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", feed.UserID).Delete(&domain.CalendarFeed{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(feed).Error
+	})
+}
+
+func (r *CalendarFeedRepository) FindByUserID(userID uint) (*domain.CalendarFeed, error) {
+	return r.findWhere("user_id = ?", userID)
+}
+
+func (r *CalendarFeedRepository) FindByHash(hash string) (*domain.CalendarFeed, error) {
+	return r.findWhere("token_hash = ?", hash)
+}
+
+func (r *CalendarFeedRepository) findWhere(query string, arg interface{}) (*domain.CalendarFeed, error) {
+	var feed domain.CalendarFeed
+	if err := r.db.Where(query, arg).First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *CalendarFeedRepository) Update(feed *domain.CalendarFeed) error {
+	return r.db.Save(feed).Error
+}
+
+// DeleteByUserID revokes the feed of the user. It reports false if the user
+// had none.
+func (r *CalendarFeedRepository) DeleteByUserID(userID uint) (bool, error) {
+	result := r.db.Where("user_id = ?", userID).Delete(&domain.CalendarFeed{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
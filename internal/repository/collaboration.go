@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"wishlist/internal/domain"
+)
+
+type CollaboratorRepository struct {
+	db *gorm.DB
+}
+
+func NewCollaboratorRepository(db *gorm.DB) *CollaboratorRepository {
+	return &CollaboratorRepository{db: db}
+}
+
+func (r *CollaboratorRepository) Find(wishlistID, userID uint) (*domain.Collaborator, error) {
+	var collaborator domain.Collaborator
+	err := r.db.Where("wish_list_id = ? AND user_id = ?", wishlistID, userID).First(&collaborator).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &collaborator, nil
+}
+
+func (r *CollaboratorRepository) FindByWishListID(wishlistID uint) ([]*domain.Collaborator, error) {
+	var collaborators []*domain.Collaborator
+	if err := r.db.Preload("User").Where("wish_list_id = ?", wishlistID).Order("id").Find(&collaborators).Error; err != nil {
+		return nil, err
+	}
+	return collaborators, nil
+}
+
+func (r *CollaboratorRepository) Update(collaborator *domain.Collaborator) error {
+	return r.db.Save(collaborator).Error
+}
+
+func (r *CollaboratorRepository) Delete(wishlistID, userID uint) error {
+	return r.db.Where("wish_list_id = ? AND user_id = ?", wishlistID, userID).Delete(&domain.Collaborator{}).Error
+}
+
+type InvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+func (r *InvitationRepository) Create(invitation *domain.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *InvitationRepository) FindByID(id uint) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	err := r.db.Preload("WishList").First(&invitation, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *InvitationRepository) FindPending(wishlistID uint, email string) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	err := r.db.Where("wish_list_id = ? AND LOWER(email) = LOWER(?) AND status = ?",
+		wishlistID, email, domain.InvitationPending).First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *InvitationRepository) FindByWishListID(wishlistID uint) ([]*domain.Invitation, error) {
+	var invitations []*domain.Invitation
+	if err := r.db.Where("wish_list_id = ?", wishlistID).Order("created_at DESC").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *InvitationRepository) FindPendingByEmail(email string) ([]*domain.Invitation, error) {
+	var invitations []*domain.Invitation
+	err := r.db.Preload("WishList").
+		Where("LOWER(email) = LOWER(?) AND status = ?", email, domain.InvitationPending).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *InvitationRepository) Update(invitation *domain.Invitation) error {
+	return r.db.Omit("WishList").Save(invitation).Error
+}
+
+func (r *InvitationRepository) Delete(id uint) error {
+	return r.db.Delete(&domain.Invitation{}, id).Error
+}
+
+// Accept marks the invitation as accepted and adds the collaborator in a
+// single transaction, so a half-accepted invitation can never be observed.
+func (r *InvitationRepository) Accept(invitation *domain.Invitation, collaborator *domain.Collaborator) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("WishList").Save(invitation).Error; err != nil {
+			return err
+		}
+		return tx.Create(collaborator).Error
+	})
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"wishlist/internal/domain"
+)
+
+type EmailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: db}
+}
+
+func (r *EmailVerificationRepository) Create(token *domain.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *EmailVerificationRepository) FindByHash(hash string) (*domain.EmailVerificationToken, error) {
+	var token domain.EmailVerificationToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindLatest returns the most recently issued token of the user, used or
+// not, so that resends can be throttled.
+func (r *EmailVerificationRepository) FindLatest(userID uint) (*domain.EmailVerificationToken, error) {
+	var token domain.EmailVerificationToken
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed consumes the token. It reports false if the token was already
+// used.
+func (r *EmailVerificationRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&domain.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// InvalidateForUser consumes all outstanding tokens of the user.
+func (r *EmailVerificationRepository) InvalidateForUser(userID uint) error {
+	return r.db.Model(&domain.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"wishlist/internal/domain"
+)
+
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+func (r *IdentityRepository) Create(identity *domain.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *IdentityRepository) Find(provider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) FindByUserID(userID uint) ([]*domain.UserIdentity, error) {
+	var identities []*domain.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error
+	return identities, err
+}
+
+func (r *IdentityRepository) CreateLoginState(state *domain.OIDCLoginState) error {
+	return r.db.Create(state).Error
+}
+
+// ConsumeLoginState deletes and returns the unexpired login state with the
+// hash, so that every state can be used only once.
+func (r *IdentityRepository) ConsumeLoginState(hash string) (*domain.OIDCLoginState, error) {
+	var state domain.OIDCLoginState
+	err := r.db.Where("state_hash = ?", hash).First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	result := r.db.Where("id = ?", state.ID).Delete(&domain.OIDCLoginState{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || time.Now().After(state.ExpiresAt) {
+		return nil, nil
+	}
+
+	return &state, nil
+}
+
+// DeleteExpiredLoginStates removes logins that were started but never
+// completed.
+func (r *IdentityRepository) DeleteExpiredLoginStates() error {
+	return r.db.Where("expires_at < ?", time.Now()).Delete(&domain.OIDCLoginState{}).Error
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"wishlist/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type ItemImageRepository struct {
+	db *gorm.DB
+}
+
+func NewItemImageRepository(db *gorm.DB) *ItemImageRepository {
+	return &ItemImageRepository{db: db}
+}
+
+// FindItem returns the item of the wishlist with its images.
+func (r *ItemImageRepository) FindItem(wishlistID, itemID uint) (*domain.WishItem, error) {
+	var item domain.WishItem
+	err := r.db.Preload("Images", orderImages).Where("wishlist_id = ? AND id = ?", wishlistID, itemID).First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *ItemImageRepository) Create(image *domain.ItemImage) error {
+	return r.db.Create(image).Error
+}
+
+func (r *ItemImageRepository) FindByID(itemID, imageID uint) (*domain.ItemImage, error) {
+	var image domain.ItemImage
+	err := r.db.Where("wish_item_id = ? AND id = ?", itemID, imageID).First(&image).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &image, nil
+}
+
+// Delete removes the image. The file of an uploaded image is recorded as
+// orphaned in the same transaction and returned, so that it is removed
+// from blob storage even if the caller fails to do so.
+func (r *ItemImageRepository) Delete(image *domain.ItemImage) (*domain.OrphanedBlob, error) {
+	var orphan *domain.OrphanedBlob
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if image.Uploaded() {
+			orphan = &domain.OrphanedBlob{BlobKey: image.BlobKey, CreatedAt: time.Now()}
+			if err := tx.Create(orphan).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&domain.ItemImage{}, image.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orphan, nil
+}
+
+// FindOrphanedBlobs returns the oldest orphaned files first.
+func (r *ItemImageRepository) FindOrphanedBlobs(limit int) ([]*domain.OrphanedBlob, error) {
+	var orphans []*domain.OrphanedBlob
+	if err := r.db.Order("id").Limit(limit).Find(&orphans).Error; err != nil {
+		return nil, err
+	}
+	return orphans, nil
+}
+
+func (r *ItemImageRepository) DeleteOrphanedBlob(id uint) error {
+	return r.db.Delete(&domain.OrphanedBlob{}, id).Error
+}
+
+// recordOrphanedBlobs records the files of the uploaded images of the items
+// selected by itemIDs, a subquery, as orphaned. It must run in the
+// transaction that deletes the items.
+func recordOrphanedBlobs(tx *gorm.DB, itemIDs *gorm.DB) error {
+	return tx.Exec(`INSERT INTO orphaned_blobs (blob_key, created_at)
+		SELECT blob_key, ? FROM wishlist_item_images WHERE blob_key <> '' AND wish_item_id IN (?)`,
+		time.Now(), itemIDs).Error
+}
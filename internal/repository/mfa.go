@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"wishlist/internal/domain"
+)
+
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+// Replace discards all recovery codes of the user and stores the new ones.
+func (r *RecoveryCodeRepository) Replace(userID uint, codes []*domain.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *RecoveryCodeRepository) FindUnused(userID uint) ([]*domain.RecoveryCode, error) {
+	var codes []*domain.RecoveryCode
+	err := r.db.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error
+	return codes, err
+}
+
+// MarkUsed consumes the code. It reports false if the code was already used.
+func (r *RecoveryCodeRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&domain.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *RecoveryCodeRepository) DeleteForUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error
+}
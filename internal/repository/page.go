@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+
+	"wishlist/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// sortColumn is an expression rows are sorted by. Cursors carry the value
+// of the expression as text, which is cast back to sqlType.
+type sortColumn struct {
+	expr    string
+	sqlType string
+}
+
+// keyset orders the query by column and then by idColumn, and restricts it
+// to the rows after the cursor of the page, or before it when paging
+// backwards. One row more than the limit is fetched to tell whether there
+// are more.
+func keyset(db *gorm.DB, column sortColumn, idColumn string, page domain.PageQuery) *gorm.DB {
+	backwards := page.Cursor != nil && page.Cursor.Before
+	desc := page.Desc != backwards
+
+	if page.Cursor != nil {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		db = db.Where(
+			fmt.Sprintf("(%s, %s) %s (CAST(? AS %s), ?)", column.expr, idColumn, op, column.sqlType),
+			page.Cursor.Key, page.Cursor.ID,
+		)
+	}
+
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	return db.Order(fmt.Sprintf("%s %s, %s %s", column.expr, dir, idColumn, dir)).Limit(page.Limit + 1)
+}
+
+// pageRows trims the extra row fetched by keyset and restores the
+// requested order of a backwards page. It returns the rows and the cursors
+// of the adjacent pages, built by key from the sort key and ID of a row.
+func pageRows[T any](rows []T, page domain.PageQuery, key func(row T) (string, uint)) ([]T, *domain.Cursor, *domain.Cursor) {
+	backwards := page.Cursor != nil && page.Cursor.Before
+	more := len(rows) > page.Limit
+	if more {
+		rows = rows[:page.Limit]
+	}
+	if backwards {
+		slices.Reverse(rows)
+	}
+	if len(rows) == 0 {
+		return rows, nil, nil
+	}
+
+	cursor := func(row T, before bool) *domain.Cursor {
+		k, id := key(row)
+		return &domain.Cursor{Sort: page.SortParam(), Key: k, ID: id, Before: before}
+	}
+
+	var next, prev *domain.Cursor
+	// Paging backwards came from the next page; paging forwards from a
+	// cursor came from the previous one
+	if more && !backwards || backwards {
+		next = cursor(rows[len(rows)-1], false)
+	}
+	if more && backwards || page.Cursor != nil && !backwards {
+		prev = cursor(rows[0], true)
+	}
+	return rows, next, prev
+}
+
+// countIf returns the number of rows the query matches if counting was
+// requested, and nil otherwise.
+func countIf(db *gorm.DB, page domain.PageQuery) (*int64, error) {
+	if !page.Count {
+		return nil, nil
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"wishlist/internal/domain"
+)
+
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+func (r *PasswordResetRepository) Create(token *domain.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *PasswordResetRepository) FindByHash(hash string) (*domain.PasswordResetToken, error) {
+	var token domain.PasswordResetToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed consumes the token. It reports false if the token was already
+// used, so that two concurrent resets cannot both succeed.
+func (r *PasswordResetRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&domain.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// InvalidateForUser consumes all outstanding tokens of the user.
+func (r *PasswordResetRepository) InvalidateForUser(userID uint) error {
+	return r.db.Model(&domain.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"wishlist/internal/domain"
+)
+
+type PersonalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{db: db}
+}
+
+func (r *PersonalAccessTokenRepository) Create(token *domain.PersonalAccessToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *PersonalAccessTokenRepository) FindByHash(hash string) (*domain.PersonalAccessToken, error) {
+	var token domain.PersonalAccessToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// FindByUserID returns the tokens of the user that have not been revoked.
+func (r *PersonalAccessTokenRepository) FindByUserID(userID uint) ([]*domain.PersonalAccessToken, error) {
+	var tokens []*domain.PersonalAccessToken
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// Revoke revokes the token if it belongs to the user. It reports false if
+// there was no such active token.
+func (r *PersonalAccessTokenRepository) Revoke(id, userID uint) (bool, error) {
+	result := r.db.Model(&domain.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RevokeAllForUser revokes every active token of the user.
+func (r *PersonalAccessTokenRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&domain.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *PersonalAccessTokenRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&domain.PersonalAccessToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"wishlist/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type PriceRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceRepository(db *gorm.DB) *PriceRepository {
+	return &PriceRepository{db: db}
+}
+
+// FindDueForCheck returns up to limit items with a product URL whose price
+// was not checked since before, least recently checked first. Their
+// reservations are preloaded.
+func (r *PriceRepository) FindDueForCheck(before time.Time, limit int) ([]*domain.WishItem, error) {
+	var items []*domain.WishItem
+	err := r.db.Preload("Reservation").
+		Where("url <> '' AND (price_checked_at IS NULL OR price_checked_at < ?)", before).
+		Order("price_checked_at NULLS FIRST, id").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// RecordCheck stores the price fields of the item after a check, and point
+// when the price changed.
+func (r *PriceRepository) RecordCheck(item *domain.WishItem, point *domain.PricePoint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.WishItem{ID: item.ID}).UpdateColumns(map[string]interface{}{
+			"price":            item.Price,
+			"currency":         item.Currency,
+			"price_checked_at": item.PriceCheckedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if point == nil {
+			return nil
+		}
+		return tx.Create(point).Error
+	})
+}
+
+// FindHistory returns the recorded prices of an item of the wishlist,
+// oldest first.
+func (r *PriceRepository) FindHistory(wishlistID, itemID uint) ([]*domain.PricePoint, error) {
+	var points []*domain.PricePoint
+	err := r.db.
+		Where("wish_item_id = ?", itemID).
+		Where("wish_item_id IN (?)", r.db.Model(&domain.WishItem{}).Select("id").Where("wishlist_id = ?", wishlistID)).
+		Order("checked_at, id").
+		Find(&points).Error
+	return points, err
+}
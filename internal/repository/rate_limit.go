@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"wishlist/internal/domain"
+)
+
+// RateLimitRepository stores rate limits in the database so that every
+// replica of the API enforces the same counters.
+type RateLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewRateLimitRepository(db *gorm.DB) *RateLimitRepository {
+	return &RateLimitRepository{db: db}
+}
+
+// Update locks the bucket of key while fn computes its new state, so that
+// concurrent requests from one client are counted one after another.
+func (r *RateLimitRepository) Update(ctx context.Context, key string, fn func(tat time.Time) time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		bucket := domain.RateLimitBucket{Key: key}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&bucket).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("bucket_key = ?", key).
+			First(&bucket).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.RateLimitBucket{}).
+			Where("bucket_key = ?", key).
+			Update("tat", fn(bucket.TAT)).Error
+	})
+}
+
+func (r *RateLimitRepository) DeleteIdle(ctx context.Context, before time.Time) error {
+	return r.db.WithContext(ctx).
+		Where("tat < ?", before).
+		Delete(&domain.RateLimitBucket{}).Error
+}
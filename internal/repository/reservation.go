@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"wishlist/internal/domain"
+)
+
+type ReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// CreateIfAbsent inserts the reservation unless the item is already reserved.
+// The unique index on wish_item_id makes this safe against concurrent claims;
+// the returned flag reports whether the row was inserted.
+func (r *ReservationRepository) CreateIfAbsent(reservation *domain.Reservation) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "wish_item_id"}},
+		DoNothing: true,
+	}).Create(reservation)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *ReservationRepository) FindByItemID(itemID uint) (*domain.Reservation, error) {
+	var reservation domain.Reservation
+	err := r.db.Where("wish_item_id = ?", itemID).First(&reservation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+func (r *ReservationRepository) Delete(id uint) error {
+	return r.db.Delete(&domain.Reservation{}, id).Error
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"wishlist/internal/domain"
+)
+
+type SecurityEventRepository struct {
+	db *gorm.DB
+}
+
+func NewSecurityEventRepository(db *gorm.DB) *SecurityEventRepository {
+	return &SecurityEventRepository{db: db}
+}
+
+func (r *SecurityEventRepository) Create(event *domain.SecurityEvent) error {
+	return r.db.Create(event).Error
+}
+
+// FindByUserID returns the most recent events of the user.
+func (r *SecurityEventRepository) FindByUserID(userID uint, limit int) ([]*domain.SecurityEvent, error) {
+	var events []*domain.SecurityEvent
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+type LoginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+func (r *LoginThrottleRepository) Find(key string) (*domain.LoginThrottle, error) {
+	var throttle domain.LoginThrottle
+	err := r.db.Where("throttle_key = ?", key).First(&throttle).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *LoginThrottleRepository) FindByUnlockHash(hash string) (*domain.LoginThrottle, error) {
+	var throttle domain.LoginThrottle
+	err := r.db.Where("unlock_token_hash = ?", hash).First(&throttle).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// RecordFailure counts a failed login in a single statement, so that
+// concurrent attempts cannot be undercounted. Failures before resetBefore
+// are forgotten.
+func (r *LoginThrottleRepository) RecordFailure(key string, at, resetBefore time.Time) (*domain.LoginThrottle, error) {
+	throttle := &domain.LoginThrottle{Key: key, Failures: 1, LastFailureAt: at}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "throttle_key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures": gorm.Expr(
+				"CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END",
+				resetBefore),
+			"last_failure_at": at,
+		}),
+	}).Create(throttle).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.Find(key)
+}
+
+// Lock locks the key until the given time. unlockHash, if set, lets the
+// owner lift the lock early.
+func (r *LoginThrottleRepository) Lock(key string, until time.Time, unlockHash *string) error {
+	return r.db.Model(&domain.LoginThrottle{}).
+		Where("throttle_key = ?", key).
+		Updates(map[string]interface{}{
+			"locked_until":      until,
+			"unlock_token_hash": unlockHash,
+		}).Error
+}
+
+func (r *LoginThrottleRepository) Delete(key string) error {
+	return r.db.Where("throttle_key = ?", key).Delete(&domain.LoginThrottle{}).Error
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"wishlist/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) Create(tag *domain.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *TagRepository) FindByID(id uint) (*domain.Tag, error) {
+	var tag domain.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// FindByNames returns the tags of the user with any of the names, ignoring
+// case.
+func (r *TagRepository) FindByNames(userID uint, names []string) ([]*domain.Tag, error) {
+	var tags []*domain.Tag
+	err := r.db.Where("user_id = ? AND LOWER(name) IN ?", userID, lowerAll(names)).Find(&tags).Error
+	return tags, err
+}
+
+// FindUsageByUserID returns the tags of the user in alphabetical order,
+// with how often each is used.
+func (r *TagRepository) FindUsageByUserID(userID uint) ([]*domain.TagUsage, error) {
+	var usages []*domain.TagUsage
+	err := r.db.Model(&domain.Tag{}).
+		Select(`tags.*,
+			(SELECT COUNT(*) FROM wishlist_tags WHERE wishlist_tags.tag_id = tags.id) AS wish_list_count,
+			(SELECT COUNT(*) FROM wishlist_item_tags WHERE wishlist_item_tags.tag_id = tags.id) AS item_count`).
+		Where("user_id = ?", userID).
+		Order("LOWER(name), id").
+		Scan(&usages).Error
+	return usages, err
+}
+
+func (r *TagRepository) Update(tag *domain.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+// Delete removes the tag from everything it is on.
+func (r *TagRepository) Delete(id uint) error {
+	return r.db.Delete(&domain.Tag{}, id).Error
+}
+
+// Merge moves the tag sourceID onto everything tagged with it, skipping
+// what already has targetID, and deletes the source tag.
+func (r *TagRepository) Merge(sourceID, targetID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO wishlist_tags (wish_list_id, tag_id)
+			SELECT wish_list_id, ? FROM wishlist_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, targetID, sourceID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(`INSERT INTO wishlist_item_tags (wish_item_id, tag_id)
+			SELECT wish_item_id, ? FROM wishlist_item_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, targetID, sourceID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&domain.Tag{}, sourceID).Error
+	})
+}
+
+// ReplaceWishListTags sets the tags of the wishlist.
+func (r *TagRepository) ReplaceWishListTags(wishlistID uint, tags []*domain.Tag) error {
+	return replaceTags(r.db.Model(&domain.WishList{ID: wishlistID}).Association("Tags"), tags)
+}
+
+// ReplaceItemTags sets the tags of the item.
+func (r *TagRepository) ReplaceItemTags(itemID uint, tags []*domain.Tag) error {
+	return replaceTags(r.db.Model(&domain.WishItem{ID: itemID}).Association("Tags"), tags)
+}
+
+func replaceTags(association *gorm.Association, tags []*domain.Tag) error {
+	if len(tags) == 0 {
+		return association.Clear()
+	}
+	return association.Replace(tags)
+}
+
+// filterByTags restricts a query of wishlists or items to those tagged
+// according to the filter. joinTable links the rows to tags through
+// joinColumn.
+func filterByTags(db *gorm.DB, joinTable, joinColumn string, filter domain.TagFilter) *gorm.DB {
+	if filter.Empty() {
+		return db
+	}
+
+	names := lowerAll(filter.Names)
+	tagged := db.Session(&gorm.Session{NewDB: true}).
+		Table(joinTable).
+		Select(joinTable+"."+joinColumn).
+		Joins("JOIN tags ON tags.id = "+joinTable+".tag_id").
+		Where("LOWER(tags.name) IN ?", names)
+	if !filter.MatchAny {
+		tagged = tagged.Group(joinTable+"."+joinColumn).
+			Having("COUNT(DISTINCT LOWER(tags.name)) = ?", len(names))
+	}
+	return db.Where("id IN (?)", tagged)
+}
+
+// lowerAll returns the distinct lowercase forms of the names.
+func lowerAll(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	lower := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if !seen[name] {
+			seen[name] = true
+			lower = append(lower, name)
+		}
+	}
+	return lower
+}
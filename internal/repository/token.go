@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"wishlist/internal/domain"
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+func (r *TokenRepository) CreateFamily(family *domain.TokenFamily) error {
+	return r.db.Create(family).Error
+}
+
+func (r *TokenRepository) FindFamily(id string) (*domain.TokenFamily, error) {
+	var family domain.TokenFamily
+	err := r.db.Where("id = ?", id).First(&family).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &family, nil
+}
+
+func (r *TokenRepository) RevokeFamily(id string) error {
+	return r.db.Model(&domain.TokenFamily{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserFamilies ends every session of the user.
+func (r *TokenRepository) RevokeUserFamilies(userID uint) error {
+	return r.db.Model(&domain.TokenFamily{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeOtherFamilies ends every session of the user except the given one.
+func (r *TokenRepository) RevokeOtherFamilies(userID uint, keepID string) error {
+	return r.db.Model(&domain.TokenFamily{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// FindActiveFamilies returns the sessions of the user that can still be
+// refreshed, most recently used first.
+func (r *TokenRepository) FindActiveFamilies(userID uint, now time.Time) ([]*domain.TokenFamily, error) {
+	var families []*domain.TokenFamily
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&families).Error
+	return families, err
+}
+
+// ExtendFamily records a refresh: the session was used from the given
+// client and lives on until expiresAt.
+func (r *TokenRepository) ExtendFamily(id, ip, userAgent string, seenAt, expiresAt time.Time) error {
+	return r.db.Model(&domain.TokenFamily{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"ip":           ip,
+			"user_agent":   userAgent,
+			"last_seen_at": seenAt,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *TokenRepository) TouchFamily(id string, seenAt time.Time) error {
+	return r.db.Model(&domain.TokenFamily{}).
+		Where("id = ?", id).
+		Update("last_seen_at", seenAt).Error
+}
+
+func (r *TokenRepository) CreateRefreshToken(token *domain.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *TokenRepository) FindRefreshTokenByHash(hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.Preload("Family").Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkRefreshTokenUsed flags the token as consumed. It reports false if the
+// token had already been used, which happens when two requests race to
+// rotate the same token.
+func (r *TokenRepository) MarkRefreshTokenUsed(id uint) (bool, error) {
+	result := r.db.Model(&domain.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
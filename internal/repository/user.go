@@ -49,4 +49,8 @@ func (r *UserRepository) FindByID(id uint) (*domain.User, error) {
 		return nil, err
 	}
 	return &user, nil
-} 
\ No newline at end of file
+}
+
+func (r *UserRepository) Update(user *domain.User) error {
+	return r.db.Omit("WishLists").Save(user).Error
+}
@@ -1,10 +1,15 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"time"
 	"wishlist/internal/domain"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type WishListRepository struct {
@@ -15,8 +20,10 @@ func NewWishListRepository(db *gorm.DB) *WishListRepository {
 	return &WishListRepository{db: db}
 }
 
+// Create stores the wishlist without its associations: items are added
+// one by one and tags are set with TagRepository.
 func (r *WishListRepository) Create(wishlist *domain.WishList) error {
-	return r.db.Create(wishlist).Error
+	return r.db.Omit(clause.Associations).Create(wishlist).Error
 }
 
 func (r *WishListRepository) FindByID(id uint) (*domain.WishList, error) {
@@ -27,6 +34,32 @@ func (r *WishListRepository) FindByID(id uint) (*domain.WishList, error) {
 	return &wishlist, nil
 }
 
+// FindByIDWithItems returns the wishlist with its tags and its items in
+// position order, with their images, reservations and tags.
+func (r *WishListRepository) FindByIDWithItems(id uint) (*domain.WishList, error) {
+	var wishlist domain.WishList
+	err := r.db.Preload("Tags", orderTags).
+		Preload("Items", orderItems).Preload("Items.Images", orderImages).Preload("Items.Reservation").
+		Preload("Items.Tags", orderTags).
+		First(&wishlist, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *WishListRepository) FindByShareCode(code string) (*domain.WishList, error) {
+	var wishlist domain.WishList
+	err := r.db.Preload("Items", orderItems).Preload("Items.Reservation").Preload("Items.Images", orderImages).Where("share_code = ?", code).First(&wishlist).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
 func (r *WishListRepository) FindByUserID(userID uint) ([]*domain.WishList, error) {
 	var wishlists []*domain.WishList
 	if err := r.db.Where("user_id = ?", userID).Find(&wishlists).Error; err != nil {
@@ -35,30 +68,356 @@ func (r *WishListRepository) FindByUserID(userID uint) ([]*domain.WishList, erro
 	return wishlists, nil
 }
 
+// wishListSorts are the orders of wishlists by sort name.
+var wishListSorts = map[string]sortColumn{
+	domain.SortCreated:   {"wishlists.created_at", "timestamptz"},
+	domain.SortUpdated:   {"wishlists.updated_at", "timestamptz"},
+	domain.SortName:      {"wishlists.name", "text"},
+	domain.SortEventDate: {"COALESCE(wishlists.event_date, DATE '9999-12-31')", "date"},
+}
+
+// wishListSortKey returns the value of the sort expression for the wishlist.
+func wishListSortKey(sort string, wishlist *domain.WishList) string {
+	switch sort {
+	case domain.SortUpdated:
+		return wishlist.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case domain.SortName:
+		return wishlist.Name
+	case domain.SortEventDate:
+		if wishlist.EventDate == nil {
+			return "9999-12-31"
+		}
+		return wishlist.EventDate.String()
+	default:
+		return wishlist.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// FindAccessibleByUserID returns a page of the wishlists the user owns or
+// collaborates on that match the query, with their tags.
+func (r *WishListRepository) FindAccessibleByUserID(userID uint, query domain.WishListQuery) (*domain.WishListPage, error) {
+	matching := func() *gorm.DB {
+		accessible := r.db.
+			Where("user_id = ?", userID).
+			Or("id IN (?)", r.db.Model(&domain.Collaborator{}).Select("wish_list_id").Where("user_id = ?", userID))
+		db := r.db.Model(&domain.WishList{}).Where(accessible)
+		if query.Status != "" {
+			db = db.Where("status = ?", query.Status)
+		}
+		return filterByTags(db, "wishlist_tags", "wish_list_id", query.Tags)
+	}
+
+	var wishlists []*domain.WishList
+	err := keyset(matching(), wishListSorts[query.Page.Sort], "wishlists.id", query.Page).
+		Preload("Tags", orderTags).
+		Find(&wishlists).Error
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := countIf(matching(), query.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.WishListPage{Total: total}
+	page.WishLists, page.Next, page.Prev = pageRows(wishlists, query.Page, func(wishlist *domain.WishList) (string, uint) {
+		return wishListSortKey(query.Page.Sort, wishlist), wishlist.ID
+	})
+	return page, nil
+}
+
+// FindEventsForUser returns the wishlists with an event that may fall
+// between from and to, with the name of their owner. Yearly events are
+// always returned since their next occurrence depends on the timezone of
+// the list; archived lists are not.
+func (r *WishListRepository) FindEventsForUser(userID uint, from, to domain.Date) ([]*domain.EventWishList, error) {
+	var events []*domain.EventWishList
+	inRange := r.db.
+		Where("wishlists.recurrence = ?", domain.RecurrenceYearly).
+		Or("wishlists.event_date BETWEEN ? AND ?", from, to)
+	err := r.eventWishLists(userID).
+		Where("wishlists.status <> ?", domain.WishListArchived).
+		Where(inRange).
+		Scan(&events).Error
+	return events, err
+}
+
+// FindCalendarEventsForUser returns the wishlists with a yearly event or
+// a one-off event on or after since, with the name of their owner, oldest
+// event first.
+func (r *WishListRepository) FindCalendarEventsForUser(userID uint, since domain.Date) ([]*domain.EventWishList, error) {
+	var events []*domain.EventWishList
+	err := r.eventWishLists(userID).
+		Where(r.db.Where("wishlists.recurrence = ?", domain.RecurrenceYearly).Or("wishlists.event_date >= ?", since)).
+		Order("wishlists.event_date, wishlists.id").
+		Scan(&events).Error
+	return events, err
+}
+
+// eventWishLists selects the wishlists with an event date that concern the
+// user, with the name of their owner: the lists the user owns or
+// collaborates on and the lists of friends the user reserved items on.
+func (r *WishListRepository) eventWishLists(userID uint) *gorm.DB {
+	related := r.db.
+		Where("wishlists.user_id = ?", userID).
+		Or("wishlists.id IN (?)", r.db.Model(&domain.Collaborator{}).Select("wish_list_id").Where("user_id = ?", userID)).
+		Or("wishlists.id IN (?)", r.db.Model(&domain.WishItem{}).
+			Select("wishlist_items.wishlist_id").
+			Joins("JOIN reservations ON reservations.wish_item_id = wishlist_items.id").
+			Where("reservations.user_id = ?", userID))
+	return r.db.Model(&domain.WishList{}).
+		Select("wishlists.*, users.display_name AS owner_name").
+		Joins("JOIN users ON users.id = wishlists.user_id").
+		Where(related).
+		Where("wishlists.event_date IS NOT NULL")
+}
+
+// ArchiveEndedEvents archives the active wishlists whose one-off event
+// ended, at midnight after the event day in the timezone of the list,
+// before the given time. It returns the number of archived lists.
+func (r *WishListRepository) ArchiveEndedEvents(endedBefore time.Time) (int64, error) {
+	result := r.db.Model(&domain.WishList{}).
+		Where("status = ? AND recurrence = '' AND event_date IS NOT NULL", domain.WishListActive).
+		Where("CAST(event_date + 1 AS timestamp) AT TIME ZONE timezone <= ?", endedBefore).
+		Updates(map[string]interface{}{"status": domain.WishListArchived, "updated_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
+// Update stores the fields of the wishlist itself. Items and tags given
+// with it are ignored.
 func (r *WishListRepository) Update(wishlist *domain.WishList) error {
-	return r.db.Save(wishlist).Error
+	return r.db.Omit(clause.Associations).Save(wishlist).Error
 }
 
+// Delete removes the wishlist with its items, recording the files of their
+// uploaded images as orphaned.
 func (r *WishListRepository) Delete(id uint) error {
-	return r.db.Delete(&domain.WishList{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		items := tx.Model(&domain.WishItem{}).Select("id").Where("wishlist_id = ?", id)
+		if err := recordOrphanedBlobs(tx, items); err != nil {
+			return err
+		}
+		return tx.Delete(&domain.WishList{}, id).Error
+	})
 }
 
+// AddItem creates the item, placing it at the end of the list unless it
+// already has a position.
 func (r *WishListRepository) AddItem(item *domain.WishItem) error {
-	return r.db.Create(item).Error
+	if item.Position == 0 {
+		var last int64
+		err := r.db.Model(&domain.WishItem{}).
+			Where("wishlist_id = ?", item.WishListID).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		item.Position = last + domain.ItemPositionGap
+	}
+	return r.db.Omit("Tags").Create(item).Error
 }
 
-func (r *WishListRepository) UpdateItem(item *domain.WishItem) error {
-	return r.db.Save(item).Error
+// itemSorts are the orders of items by sort name.
+var itemSorts = map[string]sortColumn{
+	domain.SortPosition: {"wishlist_items.position", "bigint"},
+	domain.SortCreated:  {"wishlist_items.created_at", "timestamptz"},
+	domain.SortUpdated:  {"wishlist_items.updated_at", "timestamptz"},
+	domain.SortName:     {"wishlist_items.name", "text"},
+	domain.SortPriority: {"wishlist_items.priority", "bigint"},
+}
+
+// itemSortKey returns the value of the sort expression for the item.
+func itemSortKey(sort string, item *domain.WishItem) string {
+	switch sort {
+	case domain.SortCreated:
+		return item.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case domain.SortUpdated:
+		return item.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case domain.SortName:
+		return item.Name
+	case domain.SortPriority:
+		return strconv.Itoa(item.Priority)
+	default:
+		return strconv.FormatInt(item.Position, 10)
+	}
+}
+
+// FindItems returns a page of the items of the wishlist that match the
+// query, with their images, reservations and tags.
+func (r *WishListRepository) FindItems(wishlistID uint, query domain.ItemQuery) (*domain.ItemPage, error) {
+	matching := func() *gorm.DB {
+		db := r.db.Model(&domain.WishItem{}).Where("wishlist_id = ?", wishlistID)
+		if query.Status != "" {
+			db = db.Where("status = ?", query.Status)
+		}
+		return filterByTags(db, "wishlist_item_tags", "wish_item_id", query.Tags)
+	}
+
+	var items []*domain.WishItem
+	err := keyset(matching(), itemSorts[query.Page.Sort], "wishlist_items.id", query.Page).
+		Preload("Images", orderImages).Preload("Reservation").Preload("Tags", orderTags).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := countIf(matching(), query.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.ItemPage{Total: total}
+	page.Items, page.Next, page.Prev = pageRows(items, query.Page, func(item *domain.WishItem) (string, uint) {
+		return itemSortKey(query.Page.Sort, item), item.ID
+	})
+	return page, nil
 }
 
+// UpdateItem saves the item and replaces its images referenced by URL with
+// item.Images. Uploaded images are kept, even when item.Images repeats
+// them; they are removed through ItemImageRepository. The position is kept
+// too, as is the creation time; items are moved with ReorderItems.
+// item.Images is reloaded. It reports whether the item exists in its
+// wishlist; nothing is written otherwise.
+func (r *WishListRepository) UpdateItem(item *domain.WishItem) (bool, error) {
+	found := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var stored domain.WishItem
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "position", "created_at").
+			Where("wishlist_id = ? AND id = ?", item.WishListID, item.ID).
+			Take(&stored).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		found = true
+		item.Position = stored.Position
+		item.CreatedAt = stored.CreatedAt
+
+		err = tx.Model(item).Select("*").Omit(clause.Associations, "ID", "Position", "CreatedAt").Updates(item).Error
+		if err != nil {
+			return err
+		}
+
+		var uploadedURLs []string
+		err = tx.Model(&domain.ItemImage{}).Where("wish_item_id = ? AND blob_key <> ''", item.ID).
+			Pluck("url", &uploadedURLs).Error
+		if err != nil {
+			return err
+		}
+		uploaded := make(map[string]bool, len(uploadedURLs))
+		for _, url := range uploadedURLs {
+			uploaded[url] = true
+		}
+
+		if err := tx.Where("wish_item_id = ? AND blob_key = ''", item.ID).Delete(&domain.ItemImage{}).Error; err != nil {
+			return err
+		}
+		var linked []domain.ItemImage
+		for _, image := range item.Images {
+			if uploaded[image.URL] {
+				continue
+			}
+			image.ID = 0
+			image.WishItemID = item.ID
+			image.BlobKey = ""
+			linked = append(linked, image)
+		}
+		if len(linked) > 0 {
+			if err := tx.Create(&linked).Error; err != nil {
+				return err
+			}
+		}
+
+		item.Images = nil
+		return orderImages(tx.Where("wish_item_id = ?", item.ID)).Find(&item.Images).Error
+	})
+	return found, err
+}
+
+// DeleteItem removes the item, recording the files of its uploaded images
+// as orphaned.
 func (r *WishListRepository) DeleteItem(wishlistID, itemID uint) error {
-	return r.db.Where("wishlist_id = ? AND id = ?", wishlistID, itemID).Delete(&domain.WishItem{}).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		items := tx.Model(&domain.WishItem{}).Select("id").Where("wishlist_id = ? AND id = ?", wishlistID, itemID)
+		if err := recordOrphanedBlobs(tx, items); err != nil {
+			return err
+		}
+		return tx.Where("wishlist_id = ? AND id = ?", wishlistID, itemID).Delete(&domain.WishItem{}).Error
+	})
 }
 
 func (r *WishListRepository) GetItem(wishlistID, itemID uint) (*domain.WishItem, error) {
 	var item domain.WishItem
-	if err := r.db.Where("wishlist_id = ? AND id = ?", wishlistID, itemID).First(&item).Error; err != nil {
+	err := r.db.Preload("Images", orderImages).Where("wishlist_id = ? AND id = ?", wishlistID, itemID).First(&item).Error
+	if err != nil {
 		return nil, fmt.Errorf("item not found: %w", err)
 	}
 	return &item, nil
-} 
\ No newline at end of file
+}
+
+// ReorderItems locks the items of the wishlist and passes them to plan in
+// position order. The positions plan returns are stored in the same
+// transaction, so concurrent reorders apply one after the other. It returns
+// the items in their new order.
+func (r *WishListRepository) ReorderItems(wishlistID uint, plan func(items []*domain.WishItem) (map[uint]int64, error)) ([]*domain.WishItem, error) {
+	var items []*domain.WishItem
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("wishlist_id = ?", wishlistID).
+			Order("position, id").
+			Find(&items).Error
+		if err != nil {
+			return err
+		}
+
+		positions, err := plan(items)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			position, ok := positions[item.ID]
+			if !ok {
+				continue
+			}
+			err := tx.Model(&domain.WishItem{}).Where("id = ?", item.ID).UpdateColumn("position", position).Error
+			if err != nil {
+				return err
+			}
+			item.Position = position
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Position != items[j].Position {
+			return items[i].Position < items[j].Position
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+// orderItems sorts preloaded items by their position in the list.
+func orderItems(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
+
+// orderTags sorts preloaded tags alphabetically.
+func orderTags(db *gorm.DB) *gorm.DB {
+	return db.Order("LOWER(name), id")
+}
+
+// orderImages sorts preloaded item images into display order.
+func orderImages(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/mail"
+)
+
+var (
+	ErrDeletionScheduled    = errors.New("account deletion is already scheduled")
+	ErrDeletionNotScheduled = errors.New("account deletion is not scheduled")
+)
+
+// DefaultDeletionGracePeriod is how long a deleted account can be restored.
+const DefaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+type AccountRepository interface {
+	Export(userID uint) (*domain.AccountData, error)
+	FindDueForDeletion(now time.Time) ([]*domain.User, error)
+	Purge(user *domain.User) error
+}
+
+// AccountExport is the archive handed out for a data export request.
+type AccountExport struct {
+	ExportedAt time.Time `json:"exported_at"`
+	*domain.AccountData
+}
+
+// AccountService serves data subject requests: exporting all data of an
+// account and deleting it. Deletion takes effect after a grace period in
+// which the user can change their mind.
+type AccountService struct {
+	accounts       AccountRepository
+	users          UserRepository
+	reauth         *Reauthenticator
+	tokens         *TokenService
+	personalTokens PersonalAccessTokenRevoker
+	mailer         mail.Mailer
+	gracePeriod    time.Duration
+}
+
+func NewAccountService(
+	accounts AccountRepository,
+	users UserRepository,
+	reauth *Reauthenticator,
+	tokens *TokenService,
+	personalTokens PersonalAccessTokenRevoker,
+	mailer mail.Mailer,
+	gracePeriod time.Duration,
+) *AccountService {
+	return &AccountService{
+		accounts:       accounts,
+		users:          users,
+		reauth:         reauth,
+		tokens:         tokens,
+		personalTokens: personalTokens,
+		mailer:         mailer,
+		gracePeriod:    gracePeriod,
+	}
+}
+
+// Export returns everything stored about the user.
+func (s *AccountService) Export(userID uint) (*AccountExport, error) {
+	data, err := s.accounts.Export(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return &AccountExport{ExportedAt: time.Now(), AccountData: data}, nil
+}
+
+// RequestDeletion schedules the account for deletion and logs the user out
+// everywhere. Logging in again and cancelling restores the account until
+// the grace period ends.
+func (s *AccountService) RequestDeletion(ctx context.Context, userID uint, proof Reauthentication) (*domain.User, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.reauth.Confirm(user, proof); err != nil {
+		return nil, err
+	}
+
+	if user.DeletionScheduled() {
+		return nil, ErrDeletionScheduled
+	}
+
+	now := time.Now()
+	due := now.Add(s.gracePeriod)
+	user.DeletionDueAt = &due
+	user.UpdatedAt = now
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.tokens.RevokeAll(user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.personalTokens.RevokeAllForUser(user.ID); err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Your Wishlist account will be deleted",
+		Body: fmt.Sprintf("We received a request to delete your Wishlist account.\n\n"+
+			"It will be erased with all its wishlists on %s. Until then you can log in and cancel the deletion.\n\n"+
+			"If you did not ask for this, log in, cancel the deletion and change your password.\n",
+			due.UTC().Format("January 2, 2006")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CancelDeletion keeps the account after all.
+func (s *AccountService) CancelDeletion(userID uint) (*domain.User, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if !user.DeletionScheduled() {
+		return nil, ErrDeletionNotScheduled
+	}
+
+	user.DeletionDueAt = nil
+	user.UpdatedAt = time.Now()
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// PurgeDue erases the accounts whose grace period ended and returns how
+// many were erased.
+func (s *AccountService) PurgeDue() (int, error) {
+	users, err := s.accounts.FindDueForDeletion(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for i, user := range users {
+		if err := s.accounts.Purge(user); err != nil {
+			return i, fmt.Errorf("purge user %d: %w", user.ID, err)
+		}
+	}
+
+	return len(users), nil
+}
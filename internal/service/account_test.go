@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wishlist/internal/auth"
+	"wishlist/internal/domain"
+	"wishlist/internal/mail"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	wishListRepo := repository.NewWishListRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
+	tokenService := NewTokenService(repository.NewTokenRepository(db),
+		auth.NewJWTManager("test-secret", time.Hour), DefaultRefreshTokenTTL)
+	userService := NewUserService(userRepo, nil)
+	policy := NewAccessPolicy(repository.NewCollaboratorRepository(db), nil)
+	wishListService := NewWishListService(wishListRepo, policy)
+	reservationService := NewReservationService(reservationRepo, wishListRepo, policy)
+	mailer := mail.NewMemoryMailer()
+	personalTokenRepo := repository.NewPersonalAccessTokenRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	accountService := NewAccountService(repository.NewAccountRepository(db), userRepo,
+		NewReauthenticator(identityRepo, tokenService), tokenService, personalTokenRepo, mailer, DefaultDeletionGracePeriod)
+	ctx := context.Background()
+
+	leaving, err := userService.Register("leaving@example.com", "password123")
+	require.NoError(t, err)
+	friend, err := userService.Register("friend@example.com", "password123")
+	require.NoError(t, err)
+
+	ownList := &domain.WishList{UserID: leaving.ID, Name: "Mine", Status: "active"}
+	require.NoError(t, wishListService.Create(ownList))
+	require.NoError(t, wishListService.AddItem(&domain.WishItem{WishListID: ownList.ID, Name: "Kite"}, leaving.ID))
+
+	friendList := &domain.WishList{UserID: friend.ID, Name: "Friend's", Status: "active"}
+	require.NoError(t, wishListService.Create(friendList))
+	friendItem := &domain.WishItem{WishListID: friendList.ID, Name: "Lamp"}
+	require.NoError(t, wishListService.AddItem(friendItem, friend.ID))
+	code, err := wishListService.GenerateShareCode(friendList.ID, friend.ID)
+	require.NoError(t, err)
+	_, err = wishListService.UpdateShareSettings(friendList.ID, friend.ID, true, nil)
+	require.NoError(t, err)
+	_, _, err = reservationService.Claim(code, friendItem.ID, Claimant{UserID: leaving.ID, Name: "Leaving", Email: leaving.Email})
+	require.NoError(t, err)
+
+	t.Run("export contains the account data", func(t *testing.T) {
+		export, err := accountService.Export(leaving.ID)
+		require.NoError(t, err)
+		assert.Equal(t, leaving.Email, export.User.Email)
+		require.Len(t, export.User.WishLists, 1)
+		assert.Len(t, export.User.WishLists[0].Items, 1)
+		require.Len(t, export.Reservations, 1)
+		assert.Equal(t, friendItem.ID, export.Reservations[0].WishItemID)
+
+		_, err = accountService.Export(leaving.ID + 1000)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("deletion can be cancelled", func(t *testing.T) {
+		pair, err := tokenService.Issue(leaving.ID, ClientInfo{})
+		require.NoError(t, err)
+		_, _, err = NewPersonalAccessTokenService(personalTokenRepo, wishListRepo, nil).
+			Create(leaving.ID, NewPersonalAccessToken{Name: "script", Scope: domain.ScopeRead})
+		require.NoError(t, err)
+
+		_, err = accountService.RequestDeletion(ctx, leaving.ID, Reauthentication{Password: "wrong"})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		user, err := accountService.RequestDeletion(ctx, leaving.ID, Reauthentication{Password: "password123"})
+		require.NoError(t, err)
+		require.NotNil(t, user.DeletionDueAt)
+		_, ok := mailer.Last(leaving.Email)
+		assert.True(t, ok)
+
+		_, err = tokenService.Refresh(pair.RefreshToken, ClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		tokens, err := personalTokenRepo.FindByUserID(leaving.ID)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+
+		_, err = accountService.RequestDeletion(ctx, leaving.ID, Reauthentication{Password: "password123"})
+		assert.ErrorIs(t, err, ErrDeletionScheduled)
+
+		user, err = accountService.CancelDeletion(leaving.ID)
+		require.NoError(t, err)
+		assert.Nil(t, user.DeletionDueAt)
+
+		_, err = accountService.CancelDeletion(leaving.ID)
+		assert.ErrorIs(t, err, ErrDeletionNotScheduled)
+	})
+
+	t.Run("purge after the grace period", func(t *testing.T) {
+		_, err := accountService.RequestDeletion(ctx, leaving.ID, Reauthentication{Password: "password123"})
+		require.NoError(t, err)
+
+		purged, err := accountService.PurgeDue()
+		require.NoError(t, err)
+		assert.Zero(t, purged, "grace period has not ended")
+
+		past := time.Now().Add(-time.Minute)
+		require.NoError(t, db.Model(&domain.User{}).Where("id = ?", leaving.ID).Update("deletion_due_at", past).Error)
+
+		purged, err = accountService.PurgeDue()
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		gone, err := userRepo.FindByID(leaving.ID)
+		require.NoError(t, err)
+		assert.Nil(t, gone)
+
+		lists, err := wishListRepo.FindByUserID(leaving.ID)
+		require.NoError(t, err)
+		assert.Empty(t, lists)
+
+		// The friend's item stays reserved, without naming the claimant
+		reservation, err := reservationRepo.FindByItemID(friendItem.ID)
+		require.NoError(t, err)
+		require.NotNil(t, reservation)
+		assert.Nil(t, reservation.UserID)
+		assert.Equal(t, repository.AnonymousClaimantName, reservation.ClaimantName)
+		assert.Empty(t, reservation.ClaimantEmail)
+	})
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"wishlist/internal/domain"
+)
+
+var (
+	ErrAccountDisabled  = errors.New("account is disabled")
+	ErrCannotModifySelf = errors.New("administrators cannot change their own account this way")
+)
+
+const (
+	DefaultUserPageSize = 50
+	MaxUserPageSize     = 200
+)
+
+type AdminRepository interface {
+	SearchUsers(query string, limit, offset int) ([]*domain.User, int64, error)
+	FindWishLists(userID uint) ([]*domain.WishList, error)
+	FindUsersByEmails(emails []string) ([]*domain.User, error)
+	Stats(now time.Time) (*domain.SystemStats, error)
+}
+
+type PersonalAccessTokenRevoker interface {
+	RevokeAllForUser(userID uint) error
+}
+
+// UserPage is one page of a user search.
+type UserPage struct {
+	Users  []*domain.User `json:"users"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// AdminActor identifies the administrator performing an action, for the
+// security log.
+type AdminActor struct {
+	UserID    uint
+	IP        string
+	UserAgent string
+}
+
+// AdminService implements the admin API: looking up accounts for support,
+// disabling them and forcing password resets.
+type AdminService struct {
+	admin          AdminRepository
+	users          UserRepository
+	tokens         *TokenService
+	personalTokens PersonalAccessTokenRevoker
+	resets         *PasswordResetService
+	events         SecurityEventRepository
+}
+
+func NewAdminService(
+	admin AdminRepository,
+	users UserRepository,
+	tokens *TokenService,
+	personalTokens PersonalAccessTokenRevoker,
+	resets *PasswordResetService,
+	events SecurityEventRepository,
+) *AdminService {
+	return &AdminService{
+		admin:          admin,
+		users:          users,
+		tokens:         tokens,
+		personalTokens: personalTokens,
+		resets:         resets,
+		events:         events,
+	}
+}
+
+// ListUsers searches users by email or display name.
+func (s *AdminService) ListUsers(query string, limit, offset int) (*UserPage, error) {
+	if limit <= 0 {
+		limit = DefaultUserPageSize
+	}
+	if limit > MaxUserPageSize {
+		limit = MaxUserPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	users, total, err := s.admin.SearchUsers(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserPage{Users: users, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+func (s *AdminService) GetUser(userID uint) (*domain.User, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// UserWishLists returns the wishlists of a user with their items.
+func (s *AdminService) UserWishLists(userID uint) ([]*domain.WishList, error) {
+	if _, err := s.GetUser(userID); err != nil {
+		return nil, err
+	}
+	return s.admin.FindWishLists(userID)
+}
+
+// SetDisabled disables or re-enables an account. Disabling ends all its
+// sessions and revokes its personal access tokens.
+func (s *AdminService) SetDisabled(actor AdminActor, userID uint, disabled bool) (*domain.User, error) {
+	if actor.UserID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Disabled() == disabled {
+		return user, nil
+	}
+
+	now := time.Now()
+	eventType := domain.SecurityEventAccountEnabled
+	user.DisabledAt = nil
+	if disabled {
+		eventType = domain.SecurityEventAccountDisabled
+		user.DisabledAt = &now
+	}
+	user.UpdatedAt = now
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+
+	if disabled {
+		if err := s.tokens.RevokeAll(user.ID); err != nil {
+			return nil, err
+		}
+		if err := s.personalTokens.RevokeAllForUser(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.record(eventType, actor, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SetRole grants or takes away administrator rights.
+func (s *AdminService) SetRole(actor AdminActor, userID uint, role string) (*domain.User, error) {
+	if role != domain.UserRoleUser && role != domain.UserRoleAdmin {
+		return nil, ErrInvalidRole
+	}
+
+	// Keeps at least the acting administrator around
+	if actor.UserID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == role {
+		return user, nil
+	}
+
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.record(domain.SecurityEventRoleChanged, actor, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ForcePasswordReset makes the user choose a new password through the
+// emailed reset link before logging in again.
+func (s *AdminService) ForcePasswordReset(ctx context.Context, actor AdminActor, userID uint) error {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.resets.ForceReset(ctx, user); err != nil {
+		return err
+	}
+
+	return s.record(domain.SecurityEventPasswordResetForced, actor, user)
+}
+
+func (s *AdminService) Stats() (*domain.SystemStats, error) {
+	return s.admin.Stats(time.Now())
+}
+
+// EnsureAdmins grants administrator rights to the accounts with the given
+// addresses, so that a deployment can bootstrap its first administrator.
+// Addresses without an account are skipped, and so are accounts that have
+// not verified their address: anyone can register an address, but only its
+// owner can verify it.
+func (s *AdminService) EnsureAdmins(emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+
+	users, err := s.admin.FindUsersByEmails(emails)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if user.IsAdmin() || !user.EmailVerified() {
+			continue
+		}
+		user.Role = domain.UserRoleAdmin
+		user.UpdatedAt = time.Now()
+		if err := s.users.Update(user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *AdminService) record(eventType string, actor AdminActor, user *domain.User) error {
+	return s.events.Create(&domain.SecurityEvent{
+		UserID:    &user.ID,
+		ActorID:   &actor.UserID,
+		Type:      eventType,
+		Email:     strings.ToLower(user.Email),
+		IP:        actor.IP,
+		UserAgent: actor.UserAgent,
+		CreatedAt: time.Now(),
+	})
+}
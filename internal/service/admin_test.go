@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wishlist/internal/auth"
+	"wishlist/internal/domain"
+	"wishlist/internal/mail"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	personalTokenRepo := repository.NewPersonalAccessTokenRepository(db)
+	eventRepo := repository.NewSecurityEventRepository(db)
+	tokenService := NewTokenService(repository.NewTokenRepository(db),
+		auth.NewJWTManager("test-secret", time.Hour), DefaultRefreshTokenTTL)
+	mailer := mail.NewMemoryMailer()
+	resetService := NewPasswordResetService(repository.NewPasswordResetRepository(db), userRepo,
+		tokenService, personalTokenRepo, mailer, "https://wishlist.example.com", DefaultPasswordResetTTL)
+	adminService := NewAdminService(repository.NewAdminRepository(db), userRepo, tokenService, personalTokenRepo,
+		resetService, eventRepo)
+	userService := NewUserService(userRepo, nil)
+	wishListService := NewWishListService(repository.NewWishListRepository(db),
+		NewAccessPolicy(repository.NewCollaboratorRepository(db), nil))
+	ctx := context.Background()
+
+	admin, err := userService.Register("admin@example.com", "password123")
+	require.NoError(t, err)
+	alice, err := userService.Register("alice@example.com", "password123")
+	require.NoError(t, err)
+	_, err = userService.Register("bob@example.com", "password123")
+	require.NoError(t, err)
+
+	// Unverified addresses are not trusted
+	require.NoError(t, adminService.EnsureAdmins([]string{admin.Email}))
+	isAdmin, err := userService.IsAdmin(admin.ID)
+	require.NoError(t, err)
+	assert.False(t, isAdmin)
+
+	verifiedAt := time.Now()
+	admin.EmailVerifiedAt = &verifiedAt
+	require.NoError(t, userRepo.Update(admin))
+
+	require.NoError(t, adminService.EnsureAdmins([]string{admin.Email, "missing@example.com"}))
+	isAdmin, err = userService.IsAdmin(admin.ID)
+	require.NoError(t, err)
+	assert.True(t, isAdmin)
+	isAdmin, err = userService.IsAdmin(alice.ID)
+	require.NoError(t, err)
+	assert.False(t, isAdmin)
+
+	actor := AdminActor{UserID: admin.ID, IP: "10.0.0.1"}
+
+	t.Run("search users", func(t *testing.T) {
+		page, err := adminService.ListUsers("ALICE", 0, 0)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, page.Total)
+		require.Len(t, page.Users, 1)
+		assert.Equal(t, alice.ID, page.Users[0].ID)
+		assert.Equal(t, DefaultUserPageSize, page.Limit)
+
+		page, err = adminService.ListUsers("", 2, 0)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, page.Total)
+		assert.Len(t, page.Users, 2)
+
+		page, err = adminService.ListUsers("%", 10, 0)
+		require.NoError(t, err)
+		assert.Zero(t, page.Total)
+	})
+
+	t.Run("view wishlists", func(t *testing.T) {
+		wishList := &domain.WishList{UserID: alice.ID, Name: "Support", Status: "active"}
+		require.NoError(t, wishListService.Create(wishList))
+		require.NoError(t, wishListService.AddItem(&domain.WishItem{WishListID: wishList.ID, Name: "Mug"}, alice.ID))
+
+		wishlists, err := adminService.UserWishLists(alice.ID)
+		require.NoError(t, err)
+		require.Len(t, wishlists, 1)
+		assert.Len(t, wishlists[0].Items, 1)
+	})
+
+	t.Run("disable account", func(t *testing.T) {
+		pair, err := tokenService.Issue(alice.ID, ClientInfo{})
+		require.NoError(t, err)
+		_, _, err = NewPersonalAccessTokenService(personalTokenRepo, repository.NewWishListRepository(db), nil).
+			Create(alice.ID, NewPersonalAccessToken{Name: "script", Scope: domain.ScopeRead})
+		require.NoError(t, err)
+
+		_, err = adminService.SetDisabled(actor, admin.ID, true)
+		assert.ErrorIs(t, err, ErrCannotModifySelf)
+
+		user, err := adminService.SetDisabled(actor, alice.ID, true)
+		require.NoError(t, err)
+		assert.True(t, user.Disabled())
+
+		_, err = tokenService.Refresh(pair.RefreshToken, ClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+		tokens, err := personalTokenRepo.FindByUserID(alice.ID)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+
+		events, err := eventRepo.FindByUserID(alice.ID, 10)
+		require.NoError(t, err)
+		require.NotEmpty(t, events)
+		assert.Equal(t, domain.SecurityEventAccountDisabled, events[0].Type)
+		require.NotNil(t, events[0].ActorID)
+		assert.Equal(t, admin.ID, *events[0].ActorID)
+
+		user, err = adminService.SetDisabled(actor, alice.ID, false)
+		require.NoError(t, err)
+		assert.False(t, user.Disabled())
+	})
+
+	t.Run("change role", func(t *testing.T) {
+		_, err := adminService.SetRole(actor, alice.ID, "superuser")
+		assert.ErrorIs(t, err, ErrInvalidRole)
+
+		_, err = adminService.SetRole(actor, admin.ID, domain.UserRoleUser)
+		assert.ErrorIs(t, err, ErrCannotModifySelf)
+
+		user, err := adminService.SetRole(actor, alice.ID, domain.UserRoleAdmin)
+		require.NoError(t, err)
+		assert.True(t, user.IsAdmin())
+	})
+
+	t.Run("force password reset", func(t *testing.T) {
+		_, _, err := NewPersonalAccessTokenService(personalTokenRepo, repository.NewWishListRepository(db), nil).
+			Create(alice.ID, NewPersonalAccessToken{Name: "script", Scope: domain.ScopeRead})
+		require.NoError(t, err)
+
+		require.NoError(t, adminService.ForcePasswordReset(ctx, actor, alice.ID))
+
+		tokens, err := personalTokenRepo.FindByUserID(alice.ID)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+
+		_, err = userService.Login(alice.Email, "password123")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		msg, ok := mailer.Last(alice.Email)
+		require.True(t, ok)
+		assert.Contains(t, msg.Body, "reset-password?token=")
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		stats, err := adminService.Stats()
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, stats.Users)
+		assert.EqualValues(t, 2, stats.Admins)
+		assert.EqualValues(t, 1, stats.WishLists)
+		assert.EqualValues(t, 1, stats.Items)
+	})
+}
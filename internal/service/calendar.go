@@ -0,0 +1,206 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/ical"
+)
+
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+const (
+	calendarFeedTokenBytes = 32
+	// calendarHistoryDays is how long one-off events stay in the calendar
+	// after they happened.
+	calendarHistoryDays = 365
+	// CalendarRefreshInterval is how often calendar clients are asked to
+	// poll the feed.
+	CalendarRefreshInterval = time.Hour
+)
+
+type CalendarFeedRepository interface {
+	Replace(feed *domain.CalendarFeed) error
+	FindByUserID(userID uint) (*domain.CalendarFeed, error)
+	FindByHash(hash string) (*domain.CalendarFeed, error)
+	Update(feed *domain.CalendarFeed) error
+	DeleteByUserID(userID uint) (bool, error)
+}
+
+type CalendarEventRepository interface {
+	FindCalendarEventsForUser(userID uint, since domain.Date) ([]*domain.EventWishList, error)
+}
+
+// CalendarDocument is a rendered calendar feed with the validators for
+// conditional requests.
+type CalendarDocument struct {
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+}
+
+// CalendarService publishes the events of the wishlists that concern a user
+// as an iCalendar feed behind a secret, revocable URL.
+type CalendarService struct {
+	feeds     CalendarFeedRepository
+	events    CalendarEventRepository
+	users     UserRepository
+	appURL    string
+	uidDomain string
+	now       func() time.Time
+}
+
+func NewCalendarService(feeds CalendarFeedRepository, events CalendarEventRepository, users UserRepository, appURL string) *CalendarService {
+	uidDomain := "wishlist"
+	if u, err := url.Parse(appURL); err == nil && u.Hostname() != "" {
+		uidDomain = u.Hostname()
+	}
+	return &CalendarService{feeds: feeds, events: events, users: users, appURL: appURL, uidDomain: uidDomain, now: time.Now}
+}
+
+// CreateFeed issues a new feed token for the user, revoking the previous
+// one. The token is not stored and cannot be shown again.
+func (s *CalendarService) CreateFeed(userID uint) (*domain.CalendarFeed, string, error) {
+	secret, err := randomToken(calendarFeedTokenBytes)
+	if err != nil {
+		return nil, "", err
+	}
+	token := domain.CalendarFeedPrefix + secret
+
+	now := s.now()
+	feed := &domain.CalendarFeed{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ChangedAt: now,
+		CreatedAt: now,
+	}
+	if err := s.feeds.Replace(feed); err != nil {
+		return nil, "", err
+	}
+	return feed, token, nil
+}
+
+// Feed returns the calendar feed of the user.
+func (s *CalendarService) Feed(userID uint) (*domain.CalendarFeed, error) {
+	feed, err := s.feeds.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, ErrCalendarFeedNotFound
+	}
+	return feed, nil
+}
+
+// RevokeFeed disables the feed URL of the user.
+func (s *CalendarService) RevokeFeed(userID uint) error {
+	deleted, err := s.feeds.DeleteByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrCalendarFeedNotFound
+	}
+	return nil
+}
+
+// Render returns the calendar of the feed with the given token. The feeds
+// of disabled accounts and accounts scheduled for deletion are not served.
+func (s *CalendarService) Render(token string) (*CalendarDocument, error) {
+	feed, err := s.feeds.FindByHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	user, err := s.users.FindByID(feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.DisabledAt != nil || user.DeletionDueAt != nil {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	now := s.now()
+	wishlists, err := s.events.FindCalendarEventsForUser(user.ID, domain.DateOf(now.UTC()).AddDays(-calendarHistoryDays))
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &ical.Calendar{
+		ProdID:          "-//Wishlist//Events//EN",
+		Name:            "Wishlist events",
+		RefreshInterval: CalendarRefreshInterval,
+	}
+	for _, wishlist := range wishlists {
+		calendar.Events = append(calendar.Events, s.calendarEvent(wishlist, user.ID))
+	}
+
+	var body bytes.Buffer
+	if err := calendar.Encode(&body); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body.Bytes())
+	contentHash := hex.EncodeToString(sum[:])
+
+	// The calendar is considered modified when its content changes, which
+	// also covers lists that were deleted or are no longer shared
+	changed := contentHash != feed.ContentHash
+	if changed {
+		feed.ContentHash = contentHash
+		feed.ChangedAt = now
+	}
+	if changed || feed.LastUsedAt == nil || now.Sub(*feed.LastUsedAt) >= lastUsedResolution {
+		feed.LastUsedAt = &now
+		if err := s.feeds.Update(feed); err != nil {
+			return nil, err
+		}
+	}
+
+	return &CalendarDocument{
+		Body:         body.Bytes(),
+		ETag:         `"` + contentHash[:32] + `"`,
+		LastModified: feed.ChangedAt,
+	}, nil
+}
+
+func (s *CalendarService) calendarEvent(wishlist *domain.EventWishList, userID uint) ical.Event {
+	summary := wishlist.Name
+	if wishlist.UserID != userID && wishlist.OwnerName != "" {
+		summary = fmt.Sprintf("%s (%s)", wishlist.Name, wishlist.OwnerName)
+	}
+
+	event := ical.Event{
+		UID:          fmt.Sprintf("wishlist-%d@%s", wishlist.ID, s.uidDomain),
+		Summary:      summary,
+		Description:  wishlist.Description,
+		URL:          fmt.Sprintf("%s/wishlists/%d", s.appURL, wishlist.ID),
+		Date:         wishlist.EventDate.In(time.UTC),
+		LastModified: wishlist.UpdatedAt,
+	}
+	if wishlist.Occasion != "" {
+		event.Categories = []string{wishlist.Occasion}
+	}
+	if wishlist.Recurrence == domain.RecurrenceYearly {
+		event.RRule = yearlyRule(*wishlist.EventDate)
+	}
+	return event
+}
+
+// yearlyRule returns the recurrence rule of a yearly event on the date.
+// Events on February 29 fall on the last day of February, as in
+// domain.Date.Anniversary.
+func yearlyRule(date domain.Date) string {
+	if date.Month == time.February && date.Day == 29 {
+		return "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"
+	}
+	return "FREQ=YEARLY"
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYearlyRule(t *testing.T) {
+	assert.Equal(t, "FREQ=YEARLY", yearlyRule(*date(t, "1990-05-04")))
+	assert.Equal(t, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1", yearlyRule(*date(t, "2000-02-29")))
+}
+
+func TestCalendarService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	wishListRepo := repository.NewWishListRepository(db)
+	userService := NewUserService(userRepo, nil)
+	policy := NewAccessPolicy(repository.NewCollaboratorRepository(db), nil)
+	wishListService := NewWishListService(wishListRepo, policy)
+	calendarService := NewCalendarService(repository.NewCalendarFeedRepository(db), wishListRepo, userRepo, "https://app.example.com")
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	calendarService.now = func() time.Time { return now }
+
+	user, err := userService.Register("user@example.com", "password123")
+	require.NoError(t, err)
+	friend, err := userService.Register("friend@example.com", "password123")
+	require.NoError(t, err)
+	friend.DisplayName = "Sam"
+	require.NoError(t, userRepo.Update(friend))
+
+	wedding := &domain.WishList{UserID: user.ID, Name: "Wedding", Occasion: domain.OccasionWedding, EventDate: date(t, "2026-09-12")}
+	require.NoError(t, wishListService.Create(wedding))
+	longAgo := &domain.WishList{UserID: user.ID, Name: "Graduation", EventDate: date(t, "2020-07-01")}
+	require.NoError(t, wishListService.Create(longAgo))
+	birthday := &domain.WishList{
+		UserID: friend.ID, Name: "Birthday", Occasion: domain.OccasionBirthday,
+		EventDate: date(t, "1992-02-29"), Recurrence: domain.RecurrenceYearly,
+	}
+	require.NoError(t, wishListService.Create(birthday))
+	gift := &domain.WishItem{WishListID: birthday.ID, Name: "Scarf", Quantity: 1}
+	require.NoError(t, wishListService.AddItem(gift, friend.ID))
+	require.NoError(t, db.Create(&domain.Reservation{
+		WishItemID: gift.ID, UserID: &user.ID, ClaimantName: "User", TokenHash: "hash",
+	}).Error)
+
+	_, err = calendarService.Feed(user.ID)
+	assert.ErrorIs(t, err, ErrCalendarFeedNotFound)
+	_, token, err := calendarService.CreateFeed(user.ID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, domain.CalendarFeedPrefix))
+
+	t.Run("render", func(t *testing.T) {
+		document, err := calendarService.Render(token)
+		require.NoError(t, err)
+		body := string(document.Body)
+		assert.Equal(t, 2, strings.Count(body, "BEGIN:VEVENT"))
+		assert.Contains(t, body, "SUMMARY:Birthday (Sam)\r\n")
+		assert.Contains(t, body, "RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1\r\n")
+		assert.Contains(t, body, "SUMMARY:Wedding\r\n")
+		assert.Contains(t, body, "DTSTART;VALUE=DATE:20260912\r\n")
+		assert.NotContains(t, body, "Graduation")
+		assert.True(t, document.LastModified.Equal(now))
+
+		feed, err := calendarService.Feed(user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, feed.LastUsedAt)
+
+		now = now.Add(time.Hour)
+		again, err := calendarService.Render(token)
+		require.NoError(t, err)
+		assert.Equal(t, document.ETag, again.ETag)
+		assert.True(t, again.LastModified.Equal(document.LastModified))
+
+		require.NoError(t, wishListService.Delete(wedding.ID, user.ID))
+		changed, err := calendarService.Render(token)
+		require.NoError(t, err)
+		assert.NotEqual(t, document.ETag, changed.ETag)
+		assert.True(t, changed.LastModified.Equal(now))
+	})
+
+	t.Run("a new feed revokes the old one", func(t *testing.T) {
+		_, newToken, err := calendarService.CreateFeed(user.ID)
+		require.NoError(t, err)
+		_, err = calendarService.Render(token)
+		assert.ErrorIs(t, err, ErrCalendarFeedNotFound)
+		token = newToken
+	})
+
+	t.Run("disabled accounts are not served", func(t *testing.T) {
+		disabledAt := time.Now()
+		user.DisabledAt = &disabledAt
+		require.NoError(t, userRepo.Update(user))
+		_, err := calendarService.Render(token)
+		assert.ErrorIs(t, err, ErrCalendarFeedNotFound)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		require.NoError(t, calendarService.RevokeFeed(user.ID))
+		assert.ErrorIs(t, calendarService.RevokeFeed(user.ID), ErrCalendarFeedNotFound)
+	})
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"wishlist/internal/domain"
+)
+
+var (
+	ErrInvalidRole          = errors.New("invalid role")
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrInvitationExists     = errors.New("invitation already pending")
+	ErrInvitationNotPending = errors.New("invitation is no longer pending")
+	ErrAlreadyCollaborator  = errors.New("user already has access to the wishlist")
+	ErrCollaboratorNotFound = errors.New("collaborator not found")
+)
+
+type InvitationRepository interface {
+	Create(invitation *domain.Invitation) error
+	FindByID(id uint) (*domain.Invitation, error)
+	FindPending(wishlistID uint, email string) (*domain.Invitation, error)
+	FindByWishListID(wishlistID uint) ([]*domain.Invitation, error)
+	FindPendingByEmail(email string) ([]*domain.Invitation, error)
+	Update(invitation *domain.Invitation) error
+	Delete(id uint) error
+	Accept(invitation *domain.Invitation, collaborator *domain.Collaborator) error
+}
+
+type UserFinder interface {
+	FindByID(id uint) (*domain.User, error)
+	FindByEmail(email string) (*domain.User, error)
+}
+
+// CollaborationService manages who, besides the owner, has access to a
+// wishlist: invitations by email and the roles of accepted collaborators.
+type CollaborationService struct {
+	collaborators CollaboratorRepository
+	invitations   InvitationRepository
+	wishlists     WishListRepository
+	users         UserFinder
+	policy        *AccessPolicy
+}
+
+func NewCollaborationService(
+	collaborators CollaboratorRepository,
+	invitations InvitationRepository,
+	wishlists WishListRepository,
+	users UserFinder,
+	policy *AccessPolicy,
+) *CollaborationService {
+	return &CollaborationService{
+		collaborators: collaborators,
+		invitations:   invitations,
+		wishlists:     wishlists,
+		users:         users,
+		policy:        policy,
+	}
+}
+
+// Invite invites the owner of the email address to the wishlist with the
+// given role. The invitee does not need to have an account yet.
+func (s *CollaborationService) Invite(wishlistID uint, userID uint, email, role string) (*domain.Invitation, error) {
+	wishlist, err := s.wishlists.FindByID(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionManage); err != nil {
+		return nil, err
+	}
+
+	if !validCollaboratorRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	if err := s.policy.RequireVerified(userID, VerifiedActionInviteCollaborator); err != nil {
+		return nil, err
+	}
+
+	email = strings.TrimSpace(email)
+
+	invitee, err := s.users.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitee != nil {
+		existingRole, err := s.policy.Role(wishlist, invitee.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existingRole != "" {
+			return nil, ErrAlreadyCollaborator
+		}
+	}
+
+	pending, err := s.invitations.FindPending(wishlistID, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if pending != nil {
+		return nil, ErrInvitationExists
+	}
+
+	now := time.Now()
+	invitation := &domain.Invitation{
+		WishListID:  wishlistID,
+		Email:       email,
+		Role:        role,
+		Status:      domain.InvitationPending,
+		InvitedByID: userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.invitations.Create(invitation); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (s *CollaborationService) ListInvitations(wishlistID uint, userID uint) ([]*domain.Invitation, error) {
+	wishlist, err := s.wishlists.FindByID(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionManage); err != nil {
+		return nil, err
+	}
+
+	return s.invitations.FindByWishListID(wishlistID)
+}
+
+func (s *CollaborationService) RevokeInvitation(wishlistID, invitationID uint, userID uint) error {
+	wishlist, err := s.wishlists.FindByID(wishlistID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionManage); err != nil {
+		return err
+	}
+
+	invitation, err := s.invitations.FindByID(invitationID)
+	if err != nil {
+		return err
+	}
+
+	if invitation == nil || invitation.WishListID != wishlistID {
+		return ErrInvitationNotFound
+	}
+
+	return s.invitations.Delete(invitation.ID)
+}
+
+// PendingInvitations returns the invitations addressed to the user's email.
+// Only users who verified that they own the address see them.
+func (s *CollaborationService) PendingInvitations(userID uint) ([]*domain.Invitation, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrAccessDenied
+	}
+
+	if !user.EmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	return s.invitations.FindPendingByEmail(user.Email)
+}
+
+func (s *CollaborationService) AcceptInvitation(invitationID uint, userID uint) (*domain.Collaborator, error) {
+	invitation, err := s.pendingInvitationFor(invitationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invitation.Status = domain.InvitationAccepted
+	invitation.UpdatedAt = now
+
+	collaborator := &domain.Collaborator{
+		WishListID: invitation.WishListID,
+		UserID:     userID,
+		Role:       invitation.Role,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := s.invitations.Accept(invitation, collaborator); err != nil {
+		return nil, err
+	}
+
+	return collaborator, nil
+}
+
+func (s *CollaborationService) DeclineInvitation(invitationID uint, userID uint) error {
+	invitation, err := s.pendingInvitationFor(invitationID, userID)
+	if err != nil {
+		return err
+	}
+
+	invitation.Status = domain.InvitationDeclined
+	invitation.UpdatedAt = time.Now()
+	return s.invitations.Update(invitation)
+}
+
+func (s *CollaborationService) ListCollaborators(wishlistID uint, userID uint) ([]*domain.Collaborator, error) {
+	wishlist, err := s.wishlists.FindByID(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionView); err != nil {
+		return nil, err
+	}
+
+	return s.collaborators.FindByWishListID(wishlistID)
+}
+
+func (s *CollaborationService) UpdateRole(wishlistID, collaboratorID uint, role string, userID uint) (*domain.Collaborator, error) {
+	wishlist, err := s.wishlists.FindByID(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionManage); err != nil {
+		return nil, err
+	}
+
+	if !validCollaboratorRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	collaborator, err := s.collaborators.Find(wishlistID, collaboratorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if collaborator == nil {
+		return nil, ErrCollaboratorNotFound
+	}
+
+	collaborator.Role = role
+	collaborator.UpdatedAt = time.Now()
+	if err := s.collaborators.Update(collaborator); err != nil {
+		return nil, err
+	}
+
+	return collaborator, nil
+}
+
+// RemoveCollaborator revokes a collaborator's access. Collaborators may also
+// remove themselves to leave a wishlist.
+func (s *CollaborationService) RemoveCollaborator(wishlistID, collaboratorID uint, userID uint) error {
+	wishlist, err := s.wishlists.FindByID(wishlistID)
+	if err != nil {
+		return err
+	}
+
+	if collaboratorID != userID {
+		if err := s.policy.Authorize(wishlist, userID, ActionManage); err != nil {
+			return err
+		}
+	}
+
+	collaborator, err := s.collaborators.Find(wishlistID, collaboratorID)
+	if err != nil {
+		return err
+	}
+
+	if collaborator == nil {
+		return ErrCollaboratorNotFound
+	}
+
+	return s.collaborators.Delete(wishlistID, collaboratorID)
+}
+
+func (s *CollaborationService) pendingInvitationFor(invitationID uint, userID uint) (*domain.Invitation, error) {
+	invitation, err := s.invitations.FindByID(invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Invitations addressed to someone else are reported as missing
+	if user == nil || !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationNotFound
+	}
+
+	// Anyone can register with the address; only its owner may answer
+	if !user.EmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	if invitation.Status != domain.InvitationPending {
+		return nil, ErrInvitationNotPending
+	}
+
+	return invitation, nil
+}
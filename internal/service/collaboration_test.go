@@ -0,0 +1,133 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollaborationService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	wishListRepo := repository.NewWishListRepository(db)
+	collaboratorRepo := repository.NewCollaboratorRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+
+	policy := NewAccessPolicy(collaboratorRepo, nil)
+	userService := NewUserService(userRepo, nil)
+	wishListService := NewWishListService(wishListRepo, policy)
+	collaborationService := NewCollaborationService(collaboratorRepo, invitationRepo, wishListRepo, userRepo, policy)
+
+	owner, err := userService.Register("owner@example.com", "password123")
+	require.NoError(t, err)
+	partner, err := userService.Register("partner@example.com", "password123")
+	require.NoError(t, err)
+
+	registry := &domain.WishList{
+		UserID: owner.ID,
+		Name:   "Wedding Registry",
+		Status: "active",
+	}
+	require.NoError(t, wishListService.Create(registry))
+
+	t.Run("invite and accept", func(t *testing.T) {
+		invitation, err := collaborationService.Invite(registry.ID, owner.ID, "partner@example.com", domain.RoleEditor)
+		require.NoError(t, err)
+		assert.Equal(t, domain.InvitationPending, invitation.Status)
+
+		_, err = collaborationService.Invite(registry.ID, owner.ID, "partner@example.com", domain.RoleEditor)
+		assert.ErrorIs(t, err, ErrInvitationExists)
+
+		// Only the verified owner of the address can see and answer it
+		_, err = collaborationService.PendingInvitations(partner.ID)
+		assert.ErrorIs(t, err, ErrEmailNotVerified)
+		_, err = collaborationService.AcceptInvitation(invitation.ID, partner.ID)
+		assert.ErrorIs(t, err, ErrEmailNotVerified)
+
+		verifiedAt := time.Now()
+		partner.EmailVerifiedAt = &verifiedAt
+		require.NoError(t, userRepo.Update(partner))
+
+		pending, err := collaborationService.PendingInvitations(partner.ID)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+
+		collaborator, err := collaborationService.AcceptInvitation(invitation.ID, partner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.RoleEditor, collaborator.Role)
+
+		_, err = collaborationService.AcceptInvitation(invitation.ID, partner.ID)
+		assert.ErrorIs(t, err, ErrInvitationNotPending)
+	})
+
+	t.Run("editor can add items but not delete the list", func(t *testing.T) {
+		item := &domain.WishItem{
+			WishListID: registry.ID,
+			Name:       "Toaster",
+			Status:     "wanted",
+		}
+		require.NoError(t, wishListService.AddItem(item, partner.ID))
+
+		err := wishListService.Delete(registry.ID, partner.ID)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+
+		page, err := wishListService.GetByUserID(partner.ID, domain.WishListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.WishLists, 1)
+		assert.Equal(t, registry.ID, page.WishLists[0].ID)
+	})
+
+	t.Run("editor update keeps ownership", func(t *testing.T) {
+		update := &domain.WishList{
+			ID:       registry.ID,
+			Name:     "Our Wedding Registry",
+			Status:   "active",
+			IsPublic: true,
+		}
+		require.NoError(t, wishListService.Update(update, partner.ID))
+
+		updated, err := wishListService.GetByID(registry.ID, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, owner.ID, updated.UserID)
+		assert.Equal(t, "Our Wedding Registry", updated.Name)
+		assert.False(t, updated.IsPublic)
+	})
+
+	t.Run("downgrade to viewer", func(t *testing.T) {
+		_, err := collaborationService.UpdateRole(registry.ID, partner.ID, domain.RoleViewer, owner.ID)
+		require.NoError(t, err)
+
+		item := &domain.WishItem{WishListID: registry.ID, Name: "Kettle"}
+		err = wishListService.AddItem(item, partner.ID)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+
+		_, err = wishListService.GetByID(registry.ID, partner.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("collaborator leaves", func(t *testing.T) {
+		require.NoError(t, collaborationService.RemoveCollaborator(registry.ID, partner.ID, partner.ID))
+
+		_, err := wishListService.GetByID(registry.ID, partner.ID)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+
+	t.Run("decline invitation", func(t *testing.T) {
+		invitation, err := collaborationService.Invite(registry.ID, owner.ID, "partner@example.com", domain.RoleViewer)
+		require.NoError(t, err)
+
+		// Only the addressee can answer an invitation
+		err = collaborationService.DeclineInvitation(invitation.ID, owner.ID)
+		assert.ErrorIs(t, err, ErrInvitationNotFound)
+
+		require.NoError(t, collaborationService.DeclineInvitation(invitation.ID, partner.ID))
+	})
+}
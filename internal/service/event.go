@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"wishlist/internal/domain"
+)
+
+var ErrInvalidEvent = errors.New("invalid event")
+
+const (
+	// DefaultUpcomingEventDays is how far ahead upcoming events are listed
+	// unless asked otherwise, and MaxUpcomingEventDays the furthest.
+	DefaultUpcomingEventDays = 30
+	MaxUpcomingEventDays     = 366
+
+	// DefaultEventArchiveDelay is how long after its event a wishlist is
+	// archived, leaving time to mark gifts as received.
+	DefaultEventArchiveDelay = 24 * time.Hour
+)
+
+type EventRepository interface {
+	FindEventsForUser(userID uint, from, to domain.Date) ([]*domain.EventWishList, error)
+	ArchiveEndedEvents(endedBefore time.Time) (int64, error)
+}
+
+// EventService lists the events wishlists are for and archives the lists
+// whose event has passed.
+type EventService struct {
+	repo         EventRepository
+	archiveDelay time.Duration
+	now          func() time.Time
+}
+
+func NewEventService(repo EventRepository, archiveDelay time.Duration) *EventService {
+	return &EventService{repo: repo, archiveDelay: archiveDelay, now: time.Now}
+}
+
+// Upcoming returns the events of the next days, today included, on the
+// wishlists of the user and of their friends in date order. Days are
+// counted in the timezone of each list.
+func (s *EventService) Upcoming(userID uint, days int) ([]*domain.UpcomingEvent, error) {
+	if days < 1 || days > MaxUpcomingEventDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidEvent, MaxUpcomingEventDays)
+	}
+
+	now := s.now()
+	// Timezones are at most a day apart, so the lists that may be due are
+	// those with an event within a day of the window in UTC.
+	today := domain.DateOf(now.UTC())
+	candidates, err := s.repo.FindEventsForUser(userID, today.AddDays(-1), today.AddDays(days))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*domain.UpcomingEvent, 0, len(candidates))
+	for _, wishlist := range candidates {
+		event, ok := upcomingEvent(wishlist, now, days)
+		if !ok {
+			continue
+		}
+		event.Own = wishlist.UserID == userID
+		events = append(events, event)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].StartsAt.Equal(events[j].StartsAt) {
+			return events[i].StartsAt.Before(events[j].StartsAt)
+		}
+		return events[i].WishListID < events[j].WishListID
+	})
+	return events, nil
+}
+
+// ArchiveEnded archives the wishlists whose one-off event has passed and
+// returns how many were archived.
+func (s *EventService) ArchiveEnded() (int, error) {
+	archived, err := s.repo.ArchiveEndedEvents(s.now().Add(-s.archiveDelay))
+	return int(archived), err
+}
+
+// upcomingEvent returns the next occurrence of the event of the wishlist if
+// it is within the given number of days from now.
+func upcomingEvent(wishlist *domain.EventWishList, now time.Time, days int) (*domain.UpcomingEvent, bool) {
+	loc, err := time.LoadLocation(wishlist.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	today := domain.DateOf(now.In(loc))
+	date := *wishlist.EventDate
+	recurring := wishlist.Recurrence == domain.RecurrenceYearly
+	if recurring {
+		date = date.Anniversary(today)
+	}
+
+	daysUntil := today.DaysUntil(date)
+	if daysUntil < 0 || daysUntil >= days {
+		return nil, false
+	}
+
+	return &domain.UpcomingEvent{
+		WishListID:   wishlist.ID,
+		WishListName: wishlist.Name,
+		OwnerID:      wishlist.UserID,
+		OwnerName:    wishlist.OwnerName,
+		Occasion:     wishlist.Occasion,
+		Date:         date,
+		Timezone:     loc.String(),
+		StartsAt:     date.In(loc),
+		DaysUntil:    daysUntil,
+		Recurring:    recurring,
+	}, true
+}
+
+// normalizeEvent validates the event of a wishlist that is about to be
+// saved and defaults its timezone to UTC.
+func normalizeEvent(wishlist *domain.WishList) error {
+	if wishlist.Occasion != "" && !slices.Contains(domain.Occasions, wishlist.Occasion) {
+		return fmt.Errorf("%w: unknown occasion %q", ErrInvalidEvent, wishlist.Occasion)
+	}
+
+	switch wishlist.Recurrence {
+	case "":
+	case domain.RecurrenceYearly:
+		if wishlist.EventDate == nil {
+			return fmt.Errorf("%w: a recurring event needs an event_date", ErrInvalidEvent)
+		}
+	default:
+		return fmt.Errorf("%w: recurrence must be empty or %q", ErrInvalidEvent, domain.RecurrenceYearly)
+	}
+
+	if wishlist.Timezone == "" {
+		wishlist.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(wishlist.Timezone); err != nil || wishlist.Timezone == "Local" {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidEvent, wishlist.Timezone)
+	}
+	return nil
+}
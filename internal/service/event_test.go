@@ -0,0 +1,163 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(t *testing.T, value string) *domain.Date {
+	d, err := domain.ParseDate(value)
+	require.NoError(t, err)
+	return &d
+}
+
+func TestUpcomingEventOccurrence(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 23, 30, 0, 0, time.UTC)
+
+	t.Run("yearly events recur", func(t *testing.T) {
+		wishlist := &domain.EventWishList{WishList: domain.WishList{
+			EventDate: date(t, "1990-03-20"), Recurrence: domain.RecurrenceYearly, Timezone: "UTC",
+		}}
+		event, ok := upcomingEvent(wishlist, now, 30)
+		require.True(t, ok)
+		assert.Equal(t, "2026-03-20", event.Date.String())
+		assert.Equal(t, 10, event.DaysUntil)
+		assert.True(t, event.Recurring)
+
+		_, ok = upcomingEvent(wishlist, now, 10)
+		assert.False(t, ok)
+	})
+
+	t.Run("february 29 recurs on february 28", func(t *testing.T) {
+		birthday := *date(t, "2000-02-29")
+		assert.Equal(t, "2027-02-28", birthday.Anniversary(*date(t, "2026-03-01")).String())
+		assert.Equal(t, "2028-02-29", birthday.Anniversary(*date(t, "2027-03-01")).String())
+	})
+
+	t.Run("days are counted in the timezone of the list", func(t *testing.T) {
+		wishlist := &domain.EventWishList{WishList: domain.WishList{
+			EventDate: date(t, "2026-03-11"), Timezone: "Europe/Berlin",
+		}}
+		event, ok := upcomingEvent(wishlist, now, 30)
+		require.True(t, ok)
+		assert.Equal(t, 0, event.DaysUntil)
+		assert.Equal(t, time.Date(2026, time.March, 10, 23, 0, 0, 0, time.UTC), event.StartsAt.UTC())
+
+		wishlist.Timezone = "America/New_York"
+		event, ok = upcomingEvent(wishlist, now, 30)
+		require.True(t, ok)
+		assert.Equal(t, 1, event.DaysUntil)
+	})
+
+	t.Run("past one-off events are skipped", func(t *testing.T) {
+		wishlist := &domain.EventWishList{WishList: domain.WishList{
+			EventDate: date(t, "2026-03-09"), Timezone: "UTC",
+		}}
+		_, ok := upcomingEvent(wishlist, now, 30)
+		assert.False(t, ok)
+	})
+}
+
+func TestNormalizeEvent(t *testing.T) {
+	wishlist := &domain.WishList{Occasion: domain.OccasionBirthday, EventDate: date(t, "1990-03-20"), Recurrence: domain.RecurrenceYearly}
+	require.NoError(t, normalizeEvent(wishlist))
+	assert.Equal(t, "UTC", wishlist.Timezone)
+
+	invalid := map[string]*domain.WishList{
+		"unknown occasion":        {Occasion: "party"},
+		"unknown recurrence":      {EventDate: date(t, "2026-01-01"), Recurrence: "monthly"},
+		"recurrence without date": {Recurrence: domain.RecurrenceYearly},
+		"unknown timezone":        {Timezone: "Mars/Olympus"},
+		"local timezone":          {Timezone: "Local"},
+	}
+	for name, wishlist := range invalid {
+		assert.ErrorIs(t, normalizeEvent(wishlist), ErrInvalidEvent, name)
+	}
+}
+
+func TestEventService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	wishListRepo := repository.NewWishListRepository(db)
+	userService := NewUserService(userRepo, nil)
+	policy := NewAccessPolicy(repository.NewCollaboratorRepository(db), nil)
+	wishListService := NewWishListService(wishListRepo, policy)
+	eventService := NewEventService(wishListRepo, DefaultEventArchiveDelay)
+	now := time.Date(2026, time.December, 1, 12, 0, 0, 0, time.UTC)
+	eventService.now = func() time.Time { return now }
+
+	user, err := userService.Register("user@example.com", "password123")
+	require.NoError(t, err)
+	friend, err := userService.Register("friend@example.com", "password123")
+	require.NoError(t, err)
+	stranger, err := userService.Register("stranger@example.com", "password123")
+	require.NoError(t, err)
+
+	christmas := &domain.WishList{UserID: user.ID, Name: "Christmas", Occasion: domain.OccasionChristmas, EventDate: date(t, "2026-12-24")}
+	require.NoError(t, wishListService.Create(christmas))
+	assert.Equal(t, domain.WishListActive, christmas.Status)
+	birthday := &domain.WishList{
+		UserID: friend.ID, Name: "Birthday", Occasion: domain.OccasionBirthday,
+		EventDate: date(t, "1990-12-05"), Recurrence: domain.RecurrenceYearly, Timezone: "Europe/Berlin",
+	}
+	require.NoError(t, wishListService.Create(birthday))
+	wedding := &domain.WishList{UserID: stranger.ID, Name: "Wedding", Occasion: domain.OccasionWedding, EventDate: date(t, "2026-12-10")}
+	require.NoError(t, wishListService.Create(wedding))
+	past := &domain.WishList{UserID: user.ID, Name: "Housewarming", Occasion: domain.OccasionHousewarming, EventDate: date(t, "2026-11-29")}
+	require.NoError(t, wishListService.Create(past))
+
+	// The user reserved a gift on the friend's list
+	gift := &domain.WishItem{WishListID: birthday.ID, Name: "Scarf", Quantity: 1}
+	require.NoError(t, wishListService.AddItem(gift, friend.ID))
+	require.NoError(t, db.Create(&domain.Reservation{
+		WishItemID: gift.ID, UserID: &user.ID, ClaimantName: "User", TokenHash: "hash",
+	}).Error)
+
+	t.Run("upcoming", func(t *testing.T) {
+		events, err := eventService.Upcoming(user.ID, 30)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, birthday.ID, events[0].WishListID)
+		assert.Equal(t, "2026-12-05", events[0].Date.String())
+		assert.False(t, events[0].Own)
+		assert.True(t, events[0].Recurring)
+		assert.Equal(t, christmas.ID, events[1].WishListID)
+		assert.True(t, events[1].Own)
+		assert.Equal(t, 23, events[1].DaysUntil)
+
+		events, err = eventService.Upcoming(user.ID, 7)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+
+		_, err = eventService.Upcoming(user.ID, MaxUpcomingEventDays+1)
+		assert.ErrorIs(t, err, ErrInvalidEvent)
+	})
+
+	t.Run("archives lists after their event", func(t *testing.T) {
+		archived, err := eventService.ArchiveEnded()
+		require.NoError(t, err)
+		assert.Equal(t, 1, archived)
+
+		got, err := wishListRepo.FindByID(past.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.WishListArchived, got.Status)
+
+		now = time.Date(2026, time.December, 26, 0, 0, 0, 0, time.UTC)
+		archived, err = eventService.ArchiveEnded()
+		require.NoError(t, err)
+		assert.Equal(t, 2, archived)
+
+		got, err = wishListRepo.FindByID(birthday.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.WishListActive, got.Status)
+	})
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"wishlist/internal/blob"
+	"wishlist/internal/domain"
+	"wishlist/internal/imaging"
+)
+
+var (
+	ErrImageNotFound = errors.New("image not found")
+	ErrTooManyImages = fmt.Errorf("an item can have at most %d images", domain.MaxItemImages)
+)
+
+// imageKeyBytes is the randomness in the blob key of an uploaded image.
+// Blobs are publicly readable, so keys must not be guessable.
+const imageKeyBytes = 16
+
+// orphanBatchSize is how many orphaned blobs are read at a time.
+const orphanBatchSize = 100
+
+type ItemImageRepository interface {
+	FindItem(wishlistID, itemID uint) (*domain.WishItem, error)
+	Create(image *domain.ItemImage) error
+	FindByID(itemID, imageID uint) (*domain.ItemImage, error)
+	Delete(image *domain.ItemImage) (*domain.OrphanedBlob, error)
+	FindOrphanedBlobs(limit int) ([]*domain.OrphanedBlob, error)
+	DeleteOrphanedBlob(id uint) error
+}
+
+// ItemImageService stores uploaded item images and their thumbnails in a
+// blob store, and removes the files once their images are gone.
+type ItemImageService struct {
+	images    ItemImageRepository
+	wishlists WishListRepository
+	policy    *AccessPolicy
+	store     blob.Store
+	maxPixels int
+}
+
+func NewItemImageService(images ItemImageRepository, wishlists WishListRepository, policy *AccessPolicy, store blob.Store) *ItemImageService {
+	return &ItemImageService{
+		images:    images,
+		wishlists: wishlists,
+		policy:    policy,
+		store:     store,
+		maxPixels: imaging.DefaultMaxPixels,
+	}
+}
+
+// Upload adds an uploaded picture to the item. The picture is re-encoded
+// without its metadata and stored along with its thumbnails; the image is
+// appended after the existing ones.
+func (s *ItemImageService) Upload(ctx context.Context, wishlistID, itemID uint, data []byte, userID uint) (*domain.ItemImage, error) {
+	item, err := s.editableItem(wishlistID, itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(item.Images) >= domain.MaxItemImages {
+		return nil, ErrTooManyImages
+	}
+
+	processed, err := imaging.Process(data, s.maxPixels)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := randomToken(imageKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("items/%d/%s.%s", item.ID, name, processed.Extension)
+
+	image := &domain.ItemImage{
+		WishItemID:  item.ID,
+		URL:         s.store.URL(key),
+		BlobKey:     key,
+		ContentType: processed.ContentType,
+		Width:       processed.Original.Width,
+		Height:      processed.Original.Height,
+		Thumbnails:  make(map[string]string, len(processed.Thumbnails)),
+	}
+	for _, existing := range item.Images {
+		image.Position = max(image.Position, existing.Position+1)
+	}
+
+	if err := s.store.Put(ctx, key, processed.Original.Data, processed.ContentType); err != nil {
+		return nil, err
+	}
+	for size, thumb := range processed.Thumbnails {
+		thumbKey := thumbnailKey(key, size)
+		if err := s.store.Put(ctx, thumbKey, thumb.Data, processed.ContentType); err != nil {
+			s.deleteBlobs(ctx, key)
+			return nil, err
+		}
+		image.Thumbnails[size] = s.store.URL(thumbKey)
+	}
+
+	if err := s.images.Create(image); err != nil {
+		s.deleteBlobs(ctx, key)
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// Delete removes an image from the item. The files of an uploaded image
+// are deleted right away when possible, and otherwise by
+// DeleteOrphanedBlobs.
+func (s *ItemImageService) Delete(ctx context.Context, wishlistID, itemID, imageID uint, userID uint) error {
+	item, err := s.editableItem(wishlistID, itemID, userID)
+	if err != nil {
+		return err
+	}
+
+	image, err := s.images.FindByID(item.ID, imageID)
+	if err != nil {
+		return err
+	}
+	if image == nil {
+		return ErrImageNotFound
+	}
+
+	orphan, err := s.images.Delete(image)
+	if err != nil || orphan == nil {
+		return err
+	}
+
+	if s.deleteBlobs(ctx, orphan.BlobKey) == nil {
+		// Already gone; a failure here only leaves work for the next sweep
+		_ = s.images.DeleteOrphanedBlob(orphan.ID)
+	}
+	return nil
+}
+
+// DeleteOrphanedBlobs removes the files of deleted images from the blob
+// store and returns how many images were cleaned up. Files that cannot be
+// deleted are kept for the next run.
+func (s *ItemImageService) DeleteOrphanedBlobs(ctx context.Context) (int, error) {
+	deleted := 0
+	var failed []error
+	for {
+		orphans, err := s.images.FindOrphanedBlobs(orphanBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+
+		progress := false
+		for _, orphan := range orphans {
+			if err := ctx.Err(); err != nil {
+				return deleted, err
+			}
+
+			if err := s.deleteBlobs(ctx, orphan.BlobKey); err != nil {
+				failed = append(failed, fmt.Errorf("delete blob %s: %w", orphan.BlobKey, err))
+				continue
+			}
+			if err := s.images.DeleteOrphanedBlob(orphan.ID); err != nil {
+				return deleted, err
+			}
+			deleted++
+			progress = true
+		}
+
+		// Stop at the last batch, or when a whole batch keeps failing
+		if len(orphans) < orphanBatchSize || !progress {
+			return deleted, errors.Join(failed...)
+		}
+	}
+}
+
+// editableItem returns the item after checking that the user may edit its
+// wishlist.
+func (s *ItemImageService) editableItem(wishlistID, itemID uint, userID uint) (*domain.WishItem, error) {
+	wishlist, err := s.wishlists.FindByID(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionEdit); err != nil {
+		return nil, err
+	}
+
+	item, err := s.images.FindItem(wishlistID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	return item, nil
+}
+
+// deleteBlobs deletes an uploaded image and all of its thumbnails.
+func (s *ItemImageService) deleteBlobs(ctx context.Context, key string) error {
+	keys := []string{key}
+	for _, size := range imaging.Sizes {
+		keys = append(keys, thumbnailKey(key, size.Name))
+	}
+
+	var errs []error
+	for _, k := range keys {
+		if err := s.store.Delete(ctx, k); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// thumbnailKey returns the blob key of a thumbnail of the image stored
+// under key: items/1/abc.jpg becomes items/1/abc_small.jpg.
+func thumbnailKey(key, size string) string {
+	if dot := strings.LastIndex(key, "."); dot > strings.LastIndex(key, "/") {
+		return key[:dot] + "_" + size + key[dot:]
+	}
+	return key + "_" + size
+}
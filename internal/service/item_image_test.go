@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"wishlist/internal/blob"
+	"wishlist/internal/domain"
+	"wishlist/internal/imaging"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThumbnailKey(t *testing.T) {
+	assert.Equal(t, "items/1/abc_small.jpg", thumbnailKey("items/1/abc.jpg", "small"))
+	assert.Equal(t, "items/1.x/abc_small", thumbnailKey("items/1.x/abc", "small"))
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+// blobFiles lists the files in the blob directory, relative to it.
+func blobFiles(t *testing.T, dir string) []string {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	})
+	require.NoError(t, err)
+	return files
+}
+
+func TestItemImageService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	dir := t.TempDir()
+	store, err := blob.NewLocalStore(dir, "https://media.example.com")
+	require.NoError(t, err)
+
+	userRepo := repository.NewUserRepository(db)
+	wishListRepo := repository.NewWishListRepository(db)
+	imageRepo := repository.NewItemImageRepository(db)
+	userService := NewUserService(userRepo, nil)
+	policy := NewAccessPolicy(repository.NewCollaboratorRepository(db), nil)
+	wishListService := NewWishListService(wishListRepo, policy)
+	images := NewItemImageService(imageRepo, wishListRepo, policy, store)
+	ctx := context.Background()
+
+	owner, err := userService.Register("owner@example.com", "password123")
+	require.NoError(t, err)
+	stranger, err := userService.Register("stranger@example.com", "password123")
+	require.NoError(t, err)
+
+	wishList := &domain.WishList{UserID: owner.ID, Name: "Birthday", Status: "active"}
+	require.NoError(t, wishListService.Create(wishList))
+	item := &domain.WishItem{
+		WishListID: wishList.ID,
+		Name:       "Lamp",
+		Quantity:   1,
+		Images:     []domain.ItemImage{{URL: "https://shop.example.com/lamp.jpg"}},
+	}
+	require.NoError(t, wishListService.AddItem(item, owner.ID))
+
+	var uploaded *domain.ItemImage
+	t.Run("upload", func(t *testing.T) {
+		uploaded, err = images.Upload(ctx, wishList.ID, item.ID, testPNG(t, 800, 400), owner.ID)
+		require.NoError(t, err)
+		assert.True(t, uploaded.Uploaded())
+		assert.Equal(t, "image/png", uploaded.ContentType)
+		assert.Equal(t, 800, uploaded.Width)
+		assert.Equal(t, 1, uploaded.Position)
+		assert.Equal(t, "https://media.example.com/"+uploaded.BlobKey, uploaded.URL)
+		require.Len(t, uploaded.Thumbnails, len(imaging.Sizes))
+		assert.Equal(t, store.URL(thumbnailKey(uploaded.BlobKey, "small")), uploaded.Thumbnails["small"])
+		assert.Len(t, blobFiles(t, dir), 1+len(imaging.Sizes))
+
+		got, err := wishListService.GetItem(wishList.ID, item.ID, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, got.Images, 2)
+		assert.Equal(t, uploaded.Thumbnails, got.Images[1].Thumbnails)
+	})
+
+	t.Run("rejects other files and strangers", func(t *testing.T) {
+		_, err := images.Upload(ctx, wishList.ID, item.ID, []byte("%PDF-1.7"), owner.ID)
+		assert.ErrorIs(t, err, imaging.ErrUnsupportedType)
+
+		_, err = images.Upload(ctx, wishList.ID, item.ID, testPNG(t, 10, 10), stranger.ID)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+
+		_, err = images.Upload(ctx, wishList.ID, item.ID+100, testPNG(t, 10, 10), owner.ID)
+		assert.ErrorIs(t, err, ErrItemNotFound)
+
+		assert.Len(t, blobFiles(t, dir), 1+len(imaging.Sizes))
+	})
+
+	t.Run("updating the item keeps uploaded images", func(t *testing.T) {
+		item.Images = []domain.ItemImage{
+			{URL: uploaded.URL},
+			{URL: "https://shop.example.com/lamp-2.jpg", Position: 1},
+		}
+		require.NoError(t, wishListService.UpdateItem(item, owner.ID))
+		require.Len(t, item.Images, 2)
+
+		var urls []string
+		for _, image := range item.Images {
+			urls = append(urls, image.URL)
+		}
+		assert.ElementsMatch(t, []string{uploaded.URL, "https://shop.example.com/lamp-2.jpg"}, urls)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.ErrorIs(t, images.Delete(ctx, wishList.ID, item.ID, uploaded.ID, stranger.ID), ErrAccessDenied)
+		require.NoError(t, images.Delete(ctx, wishList.ID, item.ID, uploaded.ID, owner.ID))
+		assert.Empty(t, blobFiles(t, dir))
+		assert.ErrorIs(t, images.Delete(ctx, wishList.ID, item.ID, uploaded.ID, owner.ID), ErrImageNotFound)
+
+		orphans, err := imageRepo.FindOrphanedBlobs(10)
+		require.NoError(t, err)
+		assert.Empty(t, orphans)
+	})
+
+	t.Run("deleting items and lists orphans their files", func(t *testing.T) {
+		_, err := images.Upload(ctx, wishList.ID, item.ID, testPNG(t, 20, 20), owner.ID)
+		require.NoError(t, err)
+		other := &domain.WishItem{WishListID: wishList.ID, Name: "Rug", Quantity: 1}
+		require.NoError(t, wishListService.AddItem(other, owner.ID))
+		_, err = images.Upload(ctx, wishList.ID, other.ID, testPNG(t, 20, 20), owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, blobFiles(t, dir), 2*(1+len(imaging.Sizes)))
+
+		require.NoError(t, wishListService.DeleteItem(wishList.ID, item.ID, owner.ID))
+		require.NoError(t, wishListService.Delete(wishList.ID, owner.ID))
+
+		orphans, err := imageRepo.FindOrphanedBlobs(10)
+		require.NoError(t, err)
+		assert.Len(t, orphans, 2)
+
+		deleted, err := images.DeleteOrphanedBlobs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+		assert.Empty(t, blobFiles(t, dir))
+
+		orphans, err = imageRepo.FindOrphanedBlobs(10)
+		require.NoError(t, err)
+		assert.Empty(t, orphans)
+	})
+}
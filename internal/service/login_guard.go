@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"wishlist/internal/domain"
+	"wishlist/internal/mail"
+)
+
+var (
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+	ErrAccountLocked        = errors.New("account is temporarily locked")
+	ErrInvalidUnlockToken   = errors.New("invalid or expired unlock token")
+)
+
+const unlockTokenBytes = 32
+
+// LoginThrottledError is returned when a login is refused before checking
+// the password. RetryAfter tells the client when to try again.
+type LoginThrottledError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// LockoutPolicy configures how failed logins are slowed down. Each failure
+// beyond the free attempts doubles the wait before the next try, up to
+// MaxDelay; an account that reaches LockoutThreshold is locked outright.
+type LockoutPolicy struct {
+	// Window is how long failures are remembered after the last one.
+	Window       time.Duration
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	// IPFreeAttempts is higher than FreeAttempts because many users may
+	// share an address.
+	IPFreeAttempts   int
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// MFAChallengeAttempts is how many wrong codes a single two-factor
+	// challenge accepts before the password has to be entered again.
+	MFAChallengeAttempts int
+}
+
+// DefaultLockoutPolicy returns the policy used unless configured otherwise.
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		Window:           24 * time.Hour,
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         5 * time.Minute,
+		IPFreeAttempts:   20,
+		LockoutThreshold: 10,
+		LockoutDuration:  time.Hour,
+
+		MFAChallengeAttempts: 5,
+	}
+}
+
+// backoff returns how long to wait after the given number of failures.
+func (p LockoutPolicy) backoff(failures, free int) time.Duration {
+	if failures < free {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := free; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// LoginAttempt describes who tries to log in.
+type LoginAttempt struct {
+	Email     string
+	IP        string
+	UserAgent string
+}
+
+func (a LoginAttempt) accountKey() string {
+	return "account:" + strings.ToLower(strings.TrimSpace(a.Email))
+}
+
+func (a LoginAttempt) ipKey() string {
+	return "ip:" + a.IP
+}
+
+// mfaKey counts wrong two-factor codes apart from wrong passwords, so that
+// whoever knows the password cannot reset them by logging in again.
+func (a LoginAttempt) mfaKey() string {
+	return "mfa:" + strings.ToLower(strings.TrimSpace(a.Email))
+}
+
+func mfaChallengeKey(challenge string) string {
+	return "mfa-challenge:" + hashToken(challenge)
+}
+
+type LoginThrottleRepository interface {
+	Find(key string) (*domain.LoginThrottle, error)
+	FindByUnlockHash(hash string) (*domain.LoginThrottle, error)
+	RecordFailure(key string, at, resetBefore time.Time) (*domain.LoginThrottle, error)
+	Lock(key string, until time.Time, unlockHash *string) error
+	Delete(key string) error
+}
+
+type SecurityEventRepository interface {
+	Create(event *domain.SecurityEvent) error
+	FindByUserID(userID uint, limit int) ([]*domain.SecurityEvent, error)
+}
+
+// LoginGuard protects password logins against guessing. It tracks failures
+// per account and per client IP, slows down repeated failures, locks
+// accounts that keep failing and records security events.
+type LoginGuard struct {
+	throttles LoginThrottleRepository
+	events    SecurityEventRepository
+	users     UserRepository
+	mailer    mail.Mailer
+	appURL    string
+	policy    LockoutPolicy
+}
+
+func NewLoginGuard(
+	throttles LoginThrottleRepository,
+	events SecurityEventRepository,
+	users UserRepository,
+	mailer mail.Mailer,
+	appURL string,
+	policy LockoutPolicy,
+) *LoginGuard {
+	return &LoginGuard{
+		throttles: throttles,
+		events:    events,
+		users:     users,
+		mailer:    mailer,
+		appURL:    appURL,
+		policy:    policy,
+	}
+}
+
+// Check returns a *LoginThrottledError if the attempt must be refused
+// without looking at the password.
+func (g *LoginGuard) Check(attempt LoginAttempt) error {
+	now := time.Now()
+
+	account, err := g.throttles.Find(attempt.accountKey())
+	if err != nil {
+		return err
+	}
+
+	if account != nil && account.LockedUntil != nil && now.Before(*account.LockedUntil) {
+		return g.refuse(attempt, ErrAccountLocked, account.LockedUntil.Sub(now))
+	}
+
+	if wait := g.wait(account, g.policy.FreeAttempts, now); wait > 0 {
+		return g.refuse(attempt, ErrTooManyLoginAttempts, wait)
+	}
+
+	if attempt.IP == "" {
+		return nil
+	}
+
+	ip, err := g.throttles.Find(attempt.ipKey())
+	if err != nil {
+		return err
+	}
+
+	if wait := g.wait(ip, g.policy.IPFreeAttempts, now); wait > 0 {
+		return g.refuse(attempt, ErrTooManyLoginAttempts, wait)
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed login and locks the account once it reaches
+// the threshold. user is nil when no account has the email address; such
+// attempts are throttled all the same so that responses do not reveal
+// which addresses are registered.
+func (g *LoginGuard) RecordFailure(ctx context.Context, attempt LoginAttempt, user *domain.User) error {
+	now := time.Now()
+	resetBefore := now.Add(-g.policy.Window)
+
+	if err := g.record(domain.SecurityEventLoginFailed, attempt, user); err != nil {
+		return err
+	}
+
+	if attempt.IP != "" {
+		if _, err := g.throttles.RecordFailure(attempt.ipKey(), now, resetBefore); err != nil {
+			return err
+		}
+	}
+
+	account, err := g.throttles.RecordFailure(attempt.accountKey(), now, resetBefore)
+	if err != nil {
+		return err
+	}
+
+	if account.Failures < g.policy.LockoutThreshold || account.LockedUntil != nil && now.Before(*account.LockedUntil) {
+		return nil
+	}
+
+	return g.lock(ctx, attempt.accountKey(), attempt, user, now)
+}
+
+// CheckMFA is Check for the second step of a login with the challenge. It
+// returns ErrInvalidMFAChallenge once the challenge has used up its
+// attempts.
+func (g *LoginGuard) CheckMFA(attempt LoginAttempt, challenge string) error {
+	now := time.Now()
+
+	spent, err := g.throttles.Find(mfaChallengeKey(challenge))
+	if err != nil {
+		return err
+	}
+
+	if spent != nil && g.policy.MFAChallengeAttempts > 0 && spent.Failures >= g.policy.MFAChallengeAttempts {
+		return ErrInvalidMFAChallenge
+	}
+
+	mfa, err := g.throttles.Find(attempt.mfaKey())
+	if err != nil {
+		return err
+	}
+
+	if mfa != nil && mfa.LockedUntil != nil && now.Before(*mfa.LockedUntil) {
+		return g.refuse(attempt, ErrAccountLocked, mfa.LockedUntil.Sub(now))
+	}
+
+	if wait := g.wait(mfa, g.policy.FreeAttempts, now); wait > 0 {
+		return g.refuse(attempt, ErrTooManyLoginAttempts, wait)
+	}
+
+	return nil
+}
+
+// RecordMFAFailure counts a wrong two-factor code against the user and the
+// challenge, and locks the second step once the user reaches the lockout
+// threshold. The lock is lifted like that of the password.
+func (g *LoginGuard) RecordMFAFailure(ctx context.Context, attempt LoginAttempt, user *domain.User, challenge string) error {
+	now := time.Now()
+	resetBefore := now.Add(-g.policy.Window)
+
+	if err := g.record(domain.SecurityEventMFAFailed, attempt, user); err != nil {
+		return err
+	}
+
+	if _, err := g.throttles.RecordFailure(mfaChallengeKey(challenge), now, resetBefore); err != nil {
+		return err
+	}
+
+	mfa, err := g.throttles.RecordFailure(attempt.mfaKey(), now, resetBefore)
+	if err != nil {
+		return err
+	}
+
+	if mfa.Failures < g.policy.LockoutThreshold || mfa.LockedUntil != nil && now.Before(*mfa.LockedUntil) {
+		return nil
+	}
+
+	return g.lock(ctx, attempt.mfaKey(), attempt, user, now)
+}
+
+// RecordMFASuccess clears the wrong codes of the user and the challenge.
+func (g *LoginGuard) RecordMFASuccess(attempt LoginAttempt, challenge string) error {
+	if err := g.throttles.Delete(attempt.mfaKey()); err != nil {
+		return err
+	}
+	return g.throttles.Delete(mfaChallengeKey(challenge))
+}
+
+// RecordSuccess clears the failures of the account. Failures of the IP are
+// kept, since one valid password does not make the other attempts from
+// that address legitimate.
+func (g *LoginGuard) RecordSuccess(attempt LoginAttempt, user *domain.User) error {
+	if err := g.throttles.Delete(attempt.accountKey()); err != nil {
+		return err
+	}
+	return g.record(domain.SecurityEventLoginSucceeded, attempt, user)
+}
+
+// Unlock lifts a lockout using the token from the unlock email.
+func (g *LoginGuard) Unlock(token string, attempt LoginAttempt) error {
+	throttle, err := g.throttles.FindByUnlockHash(hashToken(token))
+	if err != nil {
+		return err
+	}
+
+	if throttle == nil || throttle.LockedUntil == nil || time.Now().After(*throttle.LockedUntil) {
+		return ErrInvalidUnlockToken
+	}
+
+	if err := g.throttles.Delete(throttle.Key); err != nil {
+		return err
+	}
+
+	_, attempt.Email, _ = strings.Cut(throttle.Key, ":")
+	user, err := g.users.FindByEmail(attempt.Email)
+	if err != nil {
+		return err
+	}
+
+	return g.record(domain.SecurityEventAccountUnlocked, attempt, user)
+}
+
+func (g *LoginGuard) lock(ctx context.Context, key string, attempt LoginAttempt, user *domain.User, now time.Time) error {
+	until := now.Add(g.policy.LockoutDuration)
+
+	// Only existing accounts get an unlock link; locking unknown addresses
+	// as well keeps the behaviour identical for both.
+	var token string
+	var unlockHash *string
+	if user != nil {
+		var err error
+		token, err = randomToken(unlockTokenBytes)
+		if err != nil {
+			return err
+		}
+		hash := hashToken(token)
+		unlockHash = &hash
+	}
+
+	if err := g.throttles.Lock(key, until, unlockHash); err != nil {
+		return err
+	}
+
+	if err := g.record(domain.SecurityEventAccountLocked, attempt, user); err != nil {
+		return err
+	}
+
+	if user == nil {
+		return nil
+	}
+
+	cause := "too many failed login attempts"
+	warning := "someone may be trying to guess your password. Consider changing it."
+	if key == attempt.mfaKey() {
+		cause = "too many wrong two-factor codes"
+		warning = "someone knows your password and is trying to guess your codes. Change your password now."
+	}
+
+	link := fmt.Sprintf("%s/unlock-account?token=%s", g.appURL, url.QueryEscape(token))
+	return g.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Your Wishlist account has been locked",
+		Body: fmt.Sprintf("We locked your Wishlist account after %s.\n\n"+
+			"It unlocks automatically in %s. If these attempts were yours, open the link below to unlock it now:\n\n%s\n\n"+
+			"If they were not, %s\n",
+			cause, g.policy.LockoutDuration, link, warning),
+	})
+}
+
+func (g *LoginGuard) wait(throttle *domain.LoginThrottle, free int, now time.Time) time.Duration {
+	if throttle == nil || now.Sub(throttle.LastFailureAt) > g.policy.Window {
+		return 0
+	}
+	return time.Until(throttle.LastFailureAt.Add(g.policy.backoff(throttle.Failures, free)))
+}
+
+func (g *LoginGuard) refuse(attempt LoginAttempt, err error, retryAfter time.Duration) error {
+	if recordErr := g.record(domain.SecurityEventLoginThrottled, attempt, nil); recordErr != nil {
+		return recordErr
+	}
+	return &LoginThrottledError{Err: err, RetryAfter: retryAfter}
+}
+
+func (g *LoginGuard) record(eventType string, attempt LoginAttempt, user *domain.User) error {
+	event := &domain.SecurityEvent{
+		Type:      eventType,
+		Email:     strings.ToLower(strings.TrimSpace(attempt.Email)),
+		IP:        attempt.IP,
+		UserAgent: attempt.UserAgent,
+		CreatedAt: time.Now(),
+	}
+	if user != nil {
+		event.UserID = &user.ID
+	}
+	return g.events.Create(event)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/mail"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockoutPolicy_Backoff(t *testing.T) {
+	policy := LockoutPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{6, 8 * time.Second},
+		{7, 10 * time.Second},
+		{100, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policy.backoff(tt.failures, 3), "failures=%d", tt.failures)
+	}
+}
+
+var unlockLinkPattern = regexp.MustCompile(`unlock-account\?token=(\S+)`)
+
+func TestLoginGuard(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	eventRepo := repository.NewSecurityEventRepository(db)
+	mailer := mail.NewMemoryMailer()
+	policy := LockoutPolicy{
+		Window:           time.Hour,
+		FreeAttempts:     2,
+		BaseDelay:        time.Minute,
+		MaxDelay:         time.Hour,
+		IPFreeAttempts:   5,
+		LockoutThreshold: 4,
+		LockoutDuration:  time.Hour,
+	}
+	guard := NewLoginGuard(repository.NewLoginThrottleRepository(db), eventRepo, userRepo, mailer,
+		"https://wishlist.example.com", policy)
+	ctx := context.Background()
+
+	user, err := NewUserService(userRepo, nil).Register("target@example.com", "password123")
+	require.NoError(t, err)
+
+	t.Run("free attempts are not throttled", func(t *testing.T) {
+		attempt := LoginAttempt{Email: "free@example.com", IP: "10.0.0.1"}
+		require.NoError(t, guard.RecordFailure(ctx, attempt, nil))
+		assert.NoError(t, guard.Check(attempt))
+	})
+
+	t.Run("repeated failures back off", func(t *testing.T) {
+		attempt := LoginAttempt{Email: "Target@example.com", IP: "10.0.0.2", UserAgent: "test"}
+		require.NoError(t, guard.RecordFailure(ctx, attempt, user))
+		require.NoError(t, guard.RecordFailure(ctx, attempt, user))
+
+		err := guard.Check(attempt)
+		var throttled *LoginThrottledError
+		require.True(t, errors.As(err, &throttled))
+		assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+		assert.InDelta(t, time.Minute.Seconds(), throttled.RetryAfter.Seconds(), 5)
+
+		// A success clears the account failures.
+		require.NoError(t, guard.RecordSuccess(attempt, user))
+		assert.NoError(t, guard.Check(attempt))
+	})
+
+	t.Run("lockout and unlock by email", func(t *testing.T) {
+		attempt := LoginAttempt{Email: user.Email, IP: "10.0.0.3"}
+		for i := 0; i < policy.LockoutThreshold; i++ {
+			require.NoError(t, guard.RecordFailure(ctx, attempt, user))
+		}
+
+		// The lock applies from any address.
+		err := guard.Check(LoginAttempt{Email: user.Email, IP: "10.0.0.4"})
+		assert.ErrorIs(t, err, ErrAccountLocked)
+
+		msg, ok := mailer.Last(user.Email)
+		require.True(t, ok)
+		match := unlockLinkPattern.FindStringSubmatch(msg.Body)
+		require.Len(t, match, 2)
+		token, err := url.QueryUnescape(match[1])
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, guard.Unlock("bogus", attempt), ErrInvalidUnlockToken)
+		require.NoError(t, guard.Unlock(token, attempt))
+		assert.NoError(t, guard.Check(LoginAttempt{Email: user.Email}))
+		assert.ErrorIs(t, guard.Unlock(token, attempt), ErrInvalidUnlockToken)
+
+		events, err := eventRepo.FindByUserID(user.ID, 50)
+		require.NoError(t, err)
+		types := make(map[string]bool)
+		for _, event := range events {
+			types[event.Type] = true
+		}
+		assert.True(t, types[domain.SecurityEventLoginFailed])
+		assert.True(t, types[domain.SecurityEventAccountLocked])
+		assert.True(t, types[domain.SecurityEventAccountUnlocked])
+	})
+
+	t.Run("wrong two-factor codes lock the second step", func(t *testing.T) {
+		attempt := LoginAttempt{Email: user.Email, IP: "10.0.0.5"}
+		for i := 0; i < policy.LockoutThreshold; i++ {
+			require.NoError(t, guard.RecordMFAFailure(ctx, attempt, user, fmt.Sprintf("challenge-%d", i)))
+		}
+
+		assert.ErrorIs(t, guard.CheckMFA(attempt, "challenge-new"), ErrAccountLocked)
+		// Passwords are counted separately
+		assert.NoError(t, guard.Check(attempt))
+
+		msg, ok := mailer.Last(user.Email)
+		require.True(t, ok)
+		assert.Contains(t, msg.Body, "two-factor codes")
+		match := unlockLinkPattern.FindStringSubmatch(msg.Body)
+		require.Len(t, match, 2)
+		token, err := url.QueryUnescape(match[1])
+		require.NoError(t, err)
+
+		require.NoError(t, guard.Unlock(token, attempt))
+		assert.NoError(t, guard.CheckMFA(attempt, "challenge-new"))
+	})
+
+	t.Run("unknown accounts are locked without email", func(t *testing.T) {
+		attempt := LoginAttempt{Email: "ghost@example.com"}
+		for i := 0; i < policy.LockoutThreshold; i++ {
+			require.NoError(t, guard.RecordFailure(ctx, attempt, nil))
+		}
+		assert.ErrorIs(t, guard.Check(attempt), ErrAccountLocked)
+		_, ok := mailer.Last(attempt.Email)
+		assert.False(t, ok)
+	})
+
+	t.Run("failures from one address are throttled across accounts", func(t *testing.T) {
+		for i := 0; i < policy.IPFreeAttempts; i++ {
+			attempt := LoginAttempt{Email: "spray" + string(rune('a'+i)) + "@example.com", IP: "10.0.0.9"}
+			require.NoError(t, guard.RecordFailure(ctx, attempt, nil))
+		}
+		err := guard.Check(LoginAttempt{Email: "fresh@example.com", IP: "10.0.0.9"})
+		assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	})
+}
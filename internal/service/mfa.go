@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+	"wishlist/internal/auth"
+	"wishlist/internal/domain"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrMFASetupRequired    = errors.New("two-factor setup has not been started")
+	ErrInvalidMFACode      = errors.New("invalid two-factor code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired two-factor challenge")
+)
+
+const (
+	DefaultMFAChallengeTTL = 5 * time.Minute
+
+	recoveryCodeCount = 10
+	// recoveryCodeBytes yields 16 base32 characters, shown in groups of 4
+	recoveryCodeBytes = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type RecoveryCodeRepository interface {
+	Replace(userID uint, codes []*domain.RecoveryCode) error
+	FindUnused(userID uint) ([]*domain.RecoveryCode, error)
+	MarkUsed(id uint) (bool, error)
+	DeleteForUser(userID uint) error
+}
+
+// TOTPSetup is what the user needs to add the account to an authenticator.
+type TOTPSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAChallenge is returned by the first login step when the account has
+// two-factor authentication enabled.
+type MFAChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// MFAService manages TOTP two-factor authentication: enrollment, recovery
+// codes and the second step of the login.
+type MFAService struct {
+	users        UserRepository
+	codes        RecoveryCodeRepository
+	tokens       *TokenService
+	jwtManager   *auth.JWTManager
+	guard        *LoginGuard
+	reauth       *Reauthenticator
+	issuer       string
+	challengeTTL time.Duration
+}
+
+func NewMFAService(
+	users UserRepository,
+	codes RecoveryCodeRepository,
+	tokens *TokenService,
+	jwtManager *auth.JWTManager,
+	guard *LoginGuard,
+	reauth *Reauthenticator,
+	issuer string,
+	challengeTTL time.Duration,
+) *MFAService {
+	return &MFAService{
+		users:        users,
+		codes:        codes,
+		tokens:       tokens,
+		jwtManager:   jwtManager,
+		guard:        guard,
+		reauth:       reauth,
+		issuer:       issuer,
+		challengeTTL: challengeTTL,
+	}
+}
+
+// BeginSetup generates a new secret for the user. It takes effect only
+// after Enable confirms that the authenticator produces valid codes.
+func (s *MFAService) BeginSetup(userID uint) (*TOTPSetup, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &TOTPSetup{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable turns on two-factor authentication once the user proves the
+// authenticator works, and returns the recovery codes. They are shown only
+// this once.
+func (s *MFAService) Enable(userID uint, code string) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, ErrMFASetupRequired
+	}
+
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	user.TOTPLastStep = step
+	user.UpdatedAt = now
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// Disable turns off two-factor authentication. The user has to confirm both
+// the password, or a recent login, and a current code, so a stolen session
+// alone is not enough.
+func (s *MFAService) Disable(userID uint, proof Reauthentication, code string) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.TOTPEnabled() {
+		return ErrMFANotEnabled
+	}
+
+	if err := s.reauth.Confirm(user, proof); err != nil {
+		return err
+	}
+
+	if err := s.verifyCode(user, code); err != nil {
+		return err
+	}
+
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.TOTPLastStep = 0
+	user.UpdatedAt = time.Now()
+	if err := s.users.Update(user); err != nil {
+		return err
+	}
+
+	return s.codes.DeleteForUser(user.ID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after the user
+// confirms the password or a recent login.
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, proof Reauthentication) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TOTPEnabled() {
+		return nil, ErrMFANotEnabled
+	}
+
+	if err := s.reauth.Confirm(user, proof); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// Challenge issues the short-lived token that the client exchanges, together
+// with a code, for a session in CompleteLogin.
+func (s *MFAService) Challenge(user *domain.User) (*MFAChallenge, error) {
+	token, expiresAt, err := s.jwtManager.IssuePurpose(user.ID, auth.PurposeMFA, s.challengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAChallenge{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// CompleteLogin finishes a two-step login with a TOTP or recovery code.
+// Wrong codes are throttled per user like wrong passwords, and a challenge
+// is invalidated after a few of them.
+func (s *MFAService) CompleteLogin(ctx context.Context, challenge, code string, client ClientInfo) (*TokenPair, error) {
+	claims, err := s.jwtManager.ParsePurpose(challenge, auth.PurposeMFA)
+	if err != nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	user, err := s.users.FindByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || !user.TOTPEnabled() {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	attempt := LoginAttempt{Email: user.Email, IP: client.IP, UserAgent: client.UserAgent}
+	if err := s.guard.CheckMFA(attempt, challenge); err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyCode(user, code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			if err := s.guard.RecordMFAFailure(ctx, attempt, user, challenge); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.guard.RecordMFASuccess(attempt, challenge); err != nil {
+		return nil, err
+	}
+
+	if user.Disabled() {
+		return nil, ErrAccountDisabled
+	}
+
+	return s.tokens.Issue(user.ID, client)
+}
+
+// verifyCode accepts either a TOTP code or an unused recovery code.
+func (s *MFAService) verifyCode(user *domain.User, code string) error {
+	code = strings.TrimSpace(code)
+
+	if len(code) == auth.TOTPDigits {
+		step, ok := auth.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+
+		user.TOTPLastStep = step
+		return s.users.Update(user)
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return ErrInvalidMFACode
+	}
+
+	codes, err := s.codes.FindUnused(user.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, recovery := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(recovery.CodeHash), []byte(normalized)) != nil {
+			continue
+		}
+
+		marked, err := s.codes.MarkUsed(recovery.ID)
+		if err != nil {
+			return err
+		}
+		if !marked {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	return ErrInvalidMFACode
+}
+
+func (s *MFAService) replaceRecoveryCodes(userID uint) ([]string, error) {
+	plain := make([]string, 0, recoveryCodeCount)
+	codes := make([]*domain.RecoveryCode, 0, recoveryCodeCount)
+	now := time.Now()
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(normalizeRecoveryCode(code)), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+
+		plain = append(plain, code)
+		codes = append(codes, &domain.RecoveryCode{
+			UserID:    userID,
+			CodeHash:  string(hash),
+			CreatedAt: now,
+		})
+	}
+
+	if err := s.codes.Replace(userID, codes); err != nil {
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+func (s *MFAService) findUser(userID uint) (*domain.User, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// newRecoveryCode returns a code such as "ABCD-EFGH-IJKL-MNOP".
+func newRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	encoded := recoveryCodeEncoding.EncodeToString(b)
+	groups := make([]string, 0, len(encoded)/4)
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// normalizeRecoveryCode makes codes comparable regardless of case, spaces
+// and dashes as typed by the user.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wishlist/internal/auth"
+	"wishlist/internal/mail"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	jwtManager := auth.NewJWTManager("test-secret", time.Hour)
+	tokenService := NewTokenService(repository.NewTokenRepository(db), jwtManager, DefaultRefreshTokenTTL)
+	mailer := mail.NewMemoryMailer()
+	policy := DefaultLockoutPolicy()
+	policy.FreeAttempts = 4
+	policy.BaseDelay = time.Minute
+	policy.MFAChallengeAttempts = 2
+	guard := NewLoginGuard(repository.NewLoginThrottleRepository(db), repository.NewSecurityEventRepository(db),
+		userRepo, mailer, "https://wishlist.example.com", policy)
+	mfaService := NewMFAService(userRepo, repository.NewRecoveryCodeRepository(db), tokenService, jwtManager,
+		guard, nil, "Wishlist", DefaultMFAChallengeTTL)
+	ctx := context.Background()
+	userService := NewUserService(userRepo, nil)
+
+	user, err := userService.Register("mfa@example.com", "password123")
+	require.NoError(t, err)
+
+	_, err = mfaService.Enable(user.ID, "123456")
+	assert.ErrorIs(t, err, ErrMFASetupRequired)
+
+	setup, err := mfaService.BeginSetup(user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, setup.ProvisioningURI, "otpauth://totp/Wishlist:")
+
+	code, err := auth.TOTPCode(setup.Secret, time.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := mfaService.Enable(user.ID, code)
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes, recoveryCodeCount)
+
+	_, err = mfaService.BeginSetup(user.ID)
+	assert.ErrorIs(t, err, ErrMFAAlreadyEnabled)
+
+	stored, err := userRepo.FindByID(user.ID)
+	require.NoError(t, err)
+	challenge, err := mfaService.Challenge(stored)
+	require.NoError(t, err)
+
+	t.Run("challenge is not an access token", func(t *testing.T) {
+		_, err := jwtManager.Parse(challenge.Token)
+		assert.Error(t, err)
+	})
+
+	t.Run("used TOTP code is rejected", func(t *testing.T) {
+		_, err := mfaService.CompleteLogin(ctx, challenge.Token, code, ClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidMFACode)
+	})
+
+	t.Run("recovery code works once", func(t *testing.T) {
+		pair, err := mfaService.CompleteLogin(ctx, challenge.Token, recoveryCodes[0], ClientInfo{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, pair.AccessToken)
+
+		_, err = mfaService.CompleteLogin(ctx, challenge.Token, recoveryCodes[0], ClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidMFACode)
+	})
+
+	t.Run("invalid challenge", func(t *testing.T) {
+		_, err := mfaService.CompleteLogin(ctx, "bogus", recoveryCodes[1], ClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidMFAChallenge)
+	})
+
+	t.Run("wrong codes are throttled", func(t *testing.T) {
+		const wrongCode = "AAAA-BBBB-CCCC-DDDD"
+
+		spent, err := mfaService.Challenge(stored)
+		require.NoError(t, err)
+		for i := 0; i < policy.MFAChallengeAttempts; i++ {
+			_, err := mfaService.CompleteLogin(ctx, spent.Token, wrongCode, ClientInfo{})
+			assert.ErrorIs(t, err, ErrInvalidMFACode)
+		}
+
+		// The challenge is used up, even for a valid code
+		_, err = mfaService.CompleteLogin(ctx, spent.Token, recoveryCodes[1], ClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidMFAChallenge)
+
+		// Logging in with the password again does not reset the failures
+		fresh, err := mfaService.Challenge(stored)
+		require.NoError(t, err)
+		assert.NotEqual(t, spent.Token, fresh.Token)
+		_, err = mfaService.CompleteLogin(ctx, fresh.Token, wrongCode, ClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidMFACode)
+
+		_, err = mfaService.CompleteLogin(ctx, fresh.Token, recoveryCodes[1], ClientInfo{})
+		var throttled *LoginThrottledError
+		require.True(t, errors.As(err, &throttled))
+		assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	})
+
+	t.Run("disable requires password and code", func(t *testing.T) {
+		err := mfaService.Disable(user.ID, Reauthentication{Password: "wrong-password"}, recoveryCodes[1])
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		require.NoError(t, mfaService.Disable(user.ID, Reauthentication{Password: "password123"}, recoveryCodes[1]))
+
+		disabled, err := userRepo.FindByID(user.ID)
+		require.NoError(t, err)
+		assert.False(t, disabled.TOTPEnabled())
+	})
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	code, err := newRecoveryCode()
+	require.NoError(t, err)
+	assert.Regexp(t, `^[A-Z2-7]{4}(-[A-Z2-7]{4}){3}$`, code)
+
+	assert.Equal(t, "ABCDEFGH", normalizeRecoveryCode("abcd-efgh"))
+	assert.Equal(t, "ABCDEFGH", normalizeRecoveryCode(" ABCD EFGH "))
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"wishlist/internal/domain"
+	"wishlist/internal/oidc"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUnknownProvider      = errors.New("unknown identity provider")
+	ErrInvalidOIDCState     = errors.New("invalid or expired login state")
+	ErrOIDCLoginFailed      = errors.New("login with identity provider failed")
+	ErrOIDCEmailNotVerified = errors.New("identity provider did not confirm the email address")
+)
+
+// oidcStateTTL is how long the user has to complete the login at the
+// provider.
+const oidcStateTTL = 10 * time.Minute
+
+type IdentityRepository interface {
+	Create(identity *domain.UserIdentity) error
+	Find(provider, subject string) (*domain.UserIdentity, error)
+	FindByUserID(userID uint) ([]*domain.UserIdentity, error)
+	CreateLoginState(state *domain.OIDCLoginState) error
+	ConsumeLoginState(hash string) (*domain.OIDCLoginState, error)
+	DeleteExpiredLoginStates() error
+}
+
+// OIDCAuthorization is where to send the user to log in with a provider.
+type OIDCAuthorization struct {
+	URL   string `json:"authorization_url"`
+	State string `json:"state"`
+}
+
+// OIDCService logs users in through external OpenID providers and links
+// provider accounts to local users.
+type OIDCService struct {
+	providers      map[string]*oidc.Provider
+	identities     IdentityRepository
+	users          UserRepository
+	tokens         *TokenService
+	personalTokens PersonalAccessTokenRevoker
+}
+
+func NewOIDCService(
+	providers []*oidc.Provider,
+	identities IdentityRepository,
+	users UserRepository,
+	tokens *TokenService,
+	personalTokens PersonalAccessTokenRevoker,
+) *OIDCService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+	return &OIDCService{
+		providers:      byName,
+		identities:     identities,
+		users:          users,
+		tokens:         tokens,
+		personalTokens: personalTokens,
+	}
+}
+
+// Providers returns the names of the configured providers.
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Authorize starts a login with the provider. The client has to keep the
+// returned state and hand it back to Callback, and should reject redirects
+// carrying a state it did not start.
+func (s *OIDCService) Authorize(ctx context.Context, providerName string) (*OIDCAuthorization, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	state, err := oidc.NewRandomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.NewRandomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.NewRandomString()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.identities.DeleteExpiredLoginStates(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.identities.CreateLoginState(&domain.OIDCLoginState{
+		StateHash:    hashToken(state),
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    now.Add(oidcStateTTL),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCAuthorization{URL: authURL, State: state}, nil
+}
+
+// Callback completes the login with the authorization code and returns the
+// local user, creating or linking the account on first login.
+func (s *OIDCService) Callback(ctx context.Context, providerName, code, state string) (*domain.User, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	login, err := s.identities.ConsumeLoginState(hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+
+	if login == nil || login.Provider != providerName {
+		return nil, ErrInvalidOIDCState
+	}
+
+	claims, err := provider.Exchange(ctx, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	identity, err := s.identities.Find(providerName, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity != nil {
+		user, err := s.users.FindByID(identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+		return user, nil
+	}
+
+	return s.linkOrCreate(providerName, claims)
+}
+
+// linkOrCreate attaches a provider account seen for the first time to the
+// user with the same email address, or creates a new user. Only addresses
+// the provider has verified are trusted.
+func (s *OIDCService) linkOrCreate(providerName string, claims *oidc.Claims) (*domain.User, error) {
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if user == nil {
+		user, err = s.createUser(email, now)
+		if err != nil {
+			return nil, err
+		}
+	} else if !user.EmailVerified() {
+		// Whoever registered the address never proved owning it. Drop their
+		// password, sessions and tokens so that they cannot keep access to
+		// the account of the actual owner.
+		if err := s.tokens.RevokeAll(user.ID); err != nil {
+			return nil, err
+		}
+		if err := s.personalTokens.RevokeAllForUser(user.ID); err != nil {
+			return nil, err
+		}
+		passwordHash, err := unusablePasswordHash()
+		if err != nil {
+			return nil, err
+		}
+		user.PasswordHash = passwordHash
+		user.EmailVerifiedAt = &now
+		user.UpdatedAt = now
+		if err := s.users.Update(user); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.identities.Create(&domain.UserIdentity{
+		UserID:    user.ID,
+		Provider:  providerName,
+		Subject:   claims.Subject,
+		Email:     email,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// createUser creates an account without a usable password. The user can set
+// one later through the password reset flow.
+func (s *OIDCService) createUser(email string, now time.Time) (*domain.User, error) {
+	passwordHash, err := unusablePasswordHash()
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		Email:           email,
+		PasswordHash:    passwordHash,
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.users.Create(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// unusablePasswordHash hashes a random secret nobody knows.
+func unusablePasswordHash() (string, error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wishlist/internal/auth"
+	"wishlist/internal/oidc"
+	"wishlist/internal/oidc/oidctest"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestOIDCService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	idp := oidctest.NewServer()
+	defer idp.Close()
+
+	provider := oidc.NewProvider(oidc.Config{
+		Name:         "mock",
+		IssuerURL:    idp.Issuer(),
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  "http://localhost:5173/oauth/callback/mock",
+	}, nil)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := NewTokenService(repository.NewTokenRepository(db),
+		auth.NewJWTManager("test-secret", time.Hour), DefaultRefreshTokenTTL)
+	oidcService := NewOIDCService([]*oidc.Provider{provider}, repository.NewIdentityRepository(db), userRepo, tokenService,
+		repository.NewPersonalAccessTokenRepository(db))
+	userService := NewUserService(userRepo, nil)
+	ctx := context.Background()
+
+	login := func(t *testing.T, user oidctest.User) (string, string) {
+		t.Helper()
+		idp.SetUser(user)
+		authorization, err := oidcService.Authorize(ctx, "mock")
+		require.NoError(t, err)
+		code, state, err := idp.Login(authorization.URL)
+		require.NoError(t, err)
+		require.Equal(t, authorization.State, state)
+		return code, state
+	}
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := oidcService.Authorize(ctx, "nope")
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+	})
+
+	t.Run("first login creates a verified user", func(t *testing.T) {
+		code, state := login(t, oidctest.User{Subject: "sub-new", Email: "new@example.com", EmailVerified: true})
+
+		user, err := oidcService.Callback(ctx, "mock", code, state)
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", user.Email)
+		assert.True(t, user.EmailVerified())
+
+		_, err = oidcService.Callback(ctx, "mock", code, state)
+		assert.ErrorIs(t, err, ErrInvalidOIDCState, "state is single-use")
+
+		code, state = login(t, oidctest.User{Subject: "sub-new", Email: "renamed@example.com", EmailVerified: true})
+		again, err := oidcService.Callback(ctx, "mock", code, state)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, again.ID, "subject identifies the user")
+	})
+
+	t.Run("links existing user by verified email", func(t *testing.T) {
+		existing, err := userService.Register("existing@example.com", "password123")
+		require.NoError(t, err)
+
+		code, state := login(t, oidctest.User{Subject: "sub-existing", Email: "existing@example.com", EmailVerified: true})
+		user, err := oidcService.Callback(ctx, "mock", code, state)
+		require.NoError(t, err)
+		assert.Equal(t, existing.ID, user.ID)
+
+		// The unverified local password may belong to someone else
+		linked, err := userRepo.FindByID(existing.ID)
+		require.NoError(t, err)
+		assert.Error(t, bcrypt.CompareHashAndPassword([]byte(linked.PasswordHash), []byte("password123")))
+		assert.True(t, linked.EmailVerified())
+	})
+
+	t.Run("unverified provider email is rejected", func(t *testing.T) {
+		code, state := login(t, oidctest.User{Subject: "sub-unverified", Email: "unverified@example.com"})
+		_, err := oidcService.Callback(ctx, "mock", code, state)
+		assert.ErrorIs(t, err, ErrOIDCEmailNotVerified)
+	})
+
+	t.Run("state from another login", func(t *testing.T) {
+		code, _ := login(t, oidctest.User{Subject: "sub-x", Email: "x@example.com", EmailVerified: true})
+		_, err := oidcService.Callback(ctx, "mock", code, "forged")
+		assert.ErrorIs(t, err, ErrInvalidOIDCState)
+	})
+}
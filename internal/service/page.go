@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"wishlist/internal/domain"
+)
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// normalizePage applies the default sort and page size to the query and
+// checks that its cursor was issued for the same order.
+func normalizePage(page *domain.PageQuery, sorts []string, defaultSort string) error {
+	if page.Sort == "" {
+		page.Sort = defaultSort
+	}
+	if !slices.Contains(sorts, page.Sort) {
+		return fmt.Errorf("%w: %q, expected one of %v", ErrInvalidSort, page.Sort, sorts)
+	}
+	if page.Limit <= 0 {
+		page.Limit = DefaultPageSize
+	}
+	if page.Limit > MaxPageSize {
+		page.Limit = MaxPageSize
+	}
+	if page.Cursor != nil && page.Cursor.Sort != page.SortParam() {
+		return fmt.Errorf("%w: it was issued for sort %q", domain.ErrInvalidCursor, page.Cursor.Sort)
+	}
+	return nil
+}
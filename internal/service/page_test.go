@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePage(t *testing.T) {
+	page := domain.PageQuery{}
+	require.NoError(t, normalizePage(&page, domain.WishListSorts, domain.SortCreated))
+	assert.Equal(t, domain.SortCreated, page.Sort)
+	assert.Equal(t, DefaultPageSize, page.Limit)
+
+	page = domain.PageQuery{Sort: domain.SortName, Desc: true, Limit: MaxPageSize + 1}
+	require.NoError(t, normalizePage(&page, domain.WishListSorts, domain.SortCreated))
+	assert.Equal(t, MaxPageSize, page.Limit)
+
+	page = domain.PageQuery{Sort: domain.SortPosition}
+	assert.ErrorIs(t, normalizePage(&page, domain.WishListSorts, domain.SortCreated), ErrInvalidSort)
+
+	cursor, err := domain.ParseCursor((&domain.Cursor{Sort: "name", Key: "Books", ID: 3}).Encode())
+	require.NoError(t, err)
+	page = domain.PageQuery{Sort: domain.SortName, Desc: true, Cursor: cursor}
+	assert.ErrorIs(t, normalizePage(&page, domain.WishListSorts, domain.SortCreated), domain.ErrInvalidCursor)
+
+	_, err = domain.ParseCursor("not a cursor")
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
+
+func TestWishListPagination(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	wishListRepo := repository.NewWishListRepository(db)
+	userService := NewUserService(userRepo, nil)
+	policy := NewAccessPolicy(repository.NewCollaboratorRepository(db), nil)
+	wishListService := NewWishListService(wishListRepo, policy)
+
+	user, err := userService.Register("user@example.com", "password123")
+	require.NoError(t, err)
+
+	names := []string{"Camping", "Books", "Kitchen", "Garden", "Music"}
+	for i, name := range names {
+		wishlist := &domain.WishList{UserID: user.ID, Name: name, Status: domain.WishListActive}
+		if i == 4 {
+			wishlist.Status = domain.WishListArchived
+		}
+		require.NoError(t, wishListService.Create(wishlist))
+	}
+
+	listNames := func(page *domain.WishListPage) []string {
+		var names []string
+		for _, wishlist := range page.WishLists {
+			names = append(names, wishlist.Name)
+		}
+		return names
+	}
+
+	t.Run("pages forwards and backwards", func(t *testing.T) {
+		query := domain.WishListQuery{Page: domain.PageQuery{Sort: domain.SortName, Limit: 2, Count: true}}
+		first, err := wishListService.GetByUserID(user.ID, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Books", "Camping"}, listNames(first))
+		assert.Nil(t, first.Prev)
+		require.NotNil(t, first.Next)
+		require.NotNil(t, first.Total)
+		assert.EqualValues(t, 5, *first.Total)
+
+		query.Page.Cursor = first.Next
+		query.Page.Count = false
+		second, err := wishListService.GetByUserID(user.ID, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Garden", "Kitchen"}, listNames(second))
+		assert.Nil(t, second.Total)
+
+		query.Page.Cursor = second.Next
+		last, err := wishListService.GetByUserID(user.ID, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Music"}, listNames(last))
+		assert.Nil(t, last.Next)
+
+		query.Page.Cursor = last.Prev
+		back, err := wishListService.GetByUserID(user.ID, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Garden", "Kitchen"}, listNames(back))
+		require.NotNil(t, back.Prev)
+
+		query.Page.Cursor = back.Prev
+		front, err := wishListService.GetByUserID(user.ID, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Books", "Camping"}, listNames(front))
+		assert.Nil(t, front.Prev)
+	})
+
+	t.Run("sorts descending and filters by status", func(t *testing.T) {
+		query := domain.WishListQuery{Status: domain.WishListActive, Page: domain.PageQuery{Sort: domain.SortCreated, Desc: true, Count: true}}
+		page, err := wishListService.GetByUserID(user.ID, query)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Garden", "Kitchen", "Books", "Camping"}, listNames(page))
+		assert.EqualValues(t, 4, *page.Total)
+	})
+
+	t.Run("items", func(t *testing.T) {
+		wishlist := &domain.WishList{UserID: user.ID, Name: "Toys", Status: domain.WishListActive}
+		require.NoError(t, wishListService.Create(wishlist))
+		for i := 1; i <= 3; i++ {
+			item := &domain.WishItem{WishListID: wishlist.ID, Name: fmt.Sprintf("Toy %d", i), Priority: i % 2, Quantity: 1}
+			require.NoError(t, wishListService.AddItem(item, user.ID))
+		}
+
+		query := domain.ItemQuery{Page: domain.PageQuery{Sort: domain.SortPriority, Desc: true, Limit: 2}}
+		first, err := wishListService.ListItems(wishlist.ID, query, user.ID)
+		require.NoError(t, err)
+		require.Len(t, first.Items, 2)
+		assert.Equal(t, "Toy 1", first.Items[0].Name)
+		assert.Equal(t, "Toy 3", first.Items[1].Name)
+
+		query.Page.Cursor = first.Next
+		second, err := wishListService.ListItems(wishlist.ID, query, user.ID)
+		require.NoError(t, err)
+		require.Len(t, second.Items, 1)
+		assert.Equal(t, "Toy 2", second.Items[0].Name)
+		assert.Nil(t, second.Next)
+	})
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+	"wishlist/internal/domain"
+	"wishlist/internal/mail"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+const (
+	DefaultPasswordResetTTL = time.Hour
+
+	resetTokenBytes = 32
+
+	// Email bodies, formatted with the link validity and the link
+	resetRequestedBody = "Someone asked to reset the password of your Wishlist account.\n\n" +
+		"Open the link below to choose a new password. It is valid for %s and can be used once:\n\n%s\n\n" +
+		"If it wasn't you, ignore this email; your password stays the same.\n"
+	resetForcedBody = "An administrator has reset the password of your Wishlist account to protect it.\n\n" +
+		"Open the link below to choose a new password. It is valid for %s and can be used once:\n\n%s\n\n" +
+		"If the link expires, request a new one from the login page.\n"
+)
+
+type PasswordResetRepository interface {
+	Create(token *domain.PasswordResetToken) error
+	FindByHash(hash string) (*domain.PasswordResetToken, error)
+	MarkUsed(id uint) (bool, error)
+	InvalidateForUser(userID uint) error
+}
+
+// PasswordResetService lets users who forgot their password set a new one
+// through a single-use link sent by email.
+type PasswordResetService struct {
+	resets         PasswordResetRepository
+	users          UserRepository
+	tokens         *TokenService
+	personalTokens PersonalAccessTokenRevoker
+	mailer         mail.Mailer
+	appURL         string
+	ttl            time.Duration
+}
+
+func NewPasswordResetService(
+	resets PasswordResetRepository,
+	users UserRepository,
+	tokens *TokenService,
+	personalTokens PersonalAccessTokenRevoker,
+	mailer mail.Mailer,
+	appURL string,
+	ttl time.Duration,
+) *PasswordResetService {
+	return &PasswordResetService{
+		resets:         resets,
+		users:          users,
+		tokens:         tokens,
+		personalTokens: personalTokens,
+		mailer:         mailer,
+		appURL:         appURL,
+		ttl:            ttl,
+	}
+}
+
+// RequestReset emails a reset link to the user. Unknown addresses are
+// silently ignored so the endpoint cannot be used to probe for accounts.
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return nil
+	}
+
+	return s.sendResetLink(ctx, user, resetRequestedBody)
+}
+
+// ForceReset invalidates the password of the user, ends all sessions,
+// revokes all personal access tokens and emails a link to choose a new
+// password. Administrators use it when an
+// account may be compromised.
+func (s *PasswordResetService) ForceReset(ctx context.Context, user *domain.User) error {
+	passwordHash, err := unusablePasswordHash()
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = passwordHash
+	user.UpdatedAt = time.Now()
+	if err := s.users.Update(user); err != nil {
+		return err
+	}
+
+	if err := s.tokens.RevokeAll(user.ID); err != nil {
+		return err
+	}
+
+	if err := s.personalTokens.RevokeAllForUser(user.ID); err != nil {
+		return err
+	}
+
+	return s.sendResetLink(ctx, user, resetForcedBody)
+}
+
+// ResetPassword sets a new password using a token from a reset email, ends
+// all existing sessions of the user and revokes their personal access
+// tokens.
+func (s *PasswordResetService) ResetPassword(token, newPassword string) error {
+	reset, err := s.resets.FindByHash(hashToken(token))
+	if err != nil {
+		return err
+	}
+
+	if reset == nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	marked, err := s.resets.MarkUsed(reset.ID)
+	if err != nil {
+		return err
+	}
+
+	if !marked {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.users.FindByID(reset.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return ErrInvalidResetToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.PasswordHash = string(hashedPassword)
+	user.UpdatedAt = now
+	// Following the emailed link proves ownership of the address as well
+	if user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.users.Update(user); err != nil {
+		return err
+	}
+
+	// Whoever knew the old password must not stay logged in
+	if err := s.tokens.RevokeAll(user.ID); err != nil {
+		return err
+	}
+	return s.personalTokens.RevokeAllForUser(user.ID)
+}
+
+func (s *PasswordResetService) sendResetLink(ctx context.Context, user *domain.User, body string) error {
+	// Only the most recent link works
+	if err := s.resets.InvalidateForUser(user.ID); err != nil {
+		return err
+	}
+
+	token, err := randomToken(resetTokenBytes)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.resets.Create(&domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.appURL, url.QueryEscape(token))
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Wishlist password",
+		Body:    fmt.Sprintf(body, s.ttl, link),
+	})
+}
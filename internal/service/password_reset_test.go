@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"wishlist/internal/auth"
+	"wishlist/internal/mail"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var resetLinkPattern = regexp.MustCompile(`reset-password\?token=(\S+)`)
+
+func resetTokenFrom(t *testing.T, mailer *mail.MemoryMailer, to string) string {
+	t.Helper()
+	msg, ok := mailer.Last(to)
+	require.True(t, ok, "no email sent to %s", to)
+	match := resetLinkPattern.FindStringSubmatch(msg.Body)
+	require.Len(t, match, 2)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+func TestPasswordResetService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	tokenService := NewTokenService(repository.NewTokenRepository(db),
+		auth.NewJWTManager("test-secret", time.Hour), DefaultRefreshTokenTTL)
+	mailer := mail.NewMemoryMailer()
+	resetService := NewPasswordResetService(repository.NewPasswordResetRepository(db), userRepo,
+		tokenService, repository.NewPersonalAccessTokenRepository(db), mailer, "https://wishlist.example.com", DefaultPasswordResetTTL)
+	userService := NewUserService(userRepo, nil)
+	ctx := context.Background()
+
+	user, err := userService.Register("forgetful@example.com", "password123")
+	require.NoError(t, err)
+
+	t.Run("unknown email is ignored", func(t *testing.T) {
+		require.NoError(t, resetService.RequestReset(ctx, "nobody@example.com"))
+		assert.Empty(t, mailer.Messages())
+	})
+
+	t.Run("reset password and revoke sessions", func(t *testing.T) {
+		pair, err := tokenService.Issue(user.ID, ClientInfo{})
+		require.NoError(t, err)
+
+		require.NoError(t, resetService.RequestReset(ctx, user.Email))
+		token := resetTokenFrom(t, mailer, user.Email)
+
+		require.NoError(t, resetService.ResetPassword(token, "new-password"))
+
+		updated, err := userRepo.FindByID(user.ID)
+		require.NoError(t, err)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(updated.PasswordHash), []byte("new-password")))
+
+		_, err = tokenService.Refresh(pair.RefreshToken, ClientInfo{})
+		assert.Error(t, err)
+
+		err = resetService.ResetPassword(token, "another-password")
+		assert.ErrorIs(t, err, ErrInvalidResetToken)
+	})
+
+	t.Run("only the latest link works", func(t *testing.T) {
+		require.NoError(t, resetService.RequestReset(ctx, user.Email))
+		first := resetTokenFrom(t, mailer, user.Email)
+		require.NoError(t, resetService.RequestReset(ctx, user.Email))
+		second := resetTokenFrom(t, mailer, user.Email)
+
+		assert.ErrorIs(t, resetService.ResetPassword(first, "password456"), ErrInvalidResetToken)
+		assert.NoError(t, resetService.ResetPassword(second, "password456"))
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		assert.ErrorIs(t, resetService.ResetPassword("bogus", "password789"), ErrInvalidResetToken)
+	})
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"wishlist/internal/domain"
+)
+
+var (
+	ErrInvalidAccessToken  = errors.New("invalid or expired access token")
+	ErrAccessTokenNotFound = errors.New("access token not found")
+	ErrInvalidScope        = errors.New("invalid scope")
+	ErrInvalidExpiry       = errors.New("expiry must be in the future")
+)
+
+const (
+	personalTokenBytes = 32
+	// personalTokenPrefixLength is how much of a token is kept in clear
+	// text to identify it, including the wl_pat_ marker.
+	personalTokenPrefixLength = len(domain.PersonalAccessTokenPrefix) + 6
+	// lastUsedResolution limits how often last_used_at is written, so that
+	// busy scripts do not cause a write on every request.
+	lastUsedResolution = time.Minute
+)
+
+type PersonalAccessTokenRepository interface {
+	Create(token *domain.PersonalAccessToken) error
+	FindByHash(hash string) (*domain.PersonalAccessToken, error)
+	FindByUserID(userID uint) ([]*domain.PersonalAccessToken, error)
+	Revoke(id, userID uint) (bool, error)
+	RevokeAllForUser(userID uint) error
+	TouchLastUsed(id uint, at time.Time) error
+}
+
+// NewPersonalAccessToken holds the settings of a token to create.
+type NewPersonalAccessToken struct {
+	Name       string
+	Scope      string
+	WishListID *uint
+	ExpiresAt  *time.Time
+}
+
+// PersonalAccessTokenService manages long-lived tokens that users create for
+// scripts and integrations.
+type PersonalAccessTokenService struct {
+	repo      PersonalAccessTokenRepository
+	wishlists WishListRepository
+	policy    *AccessPolicy
+}
+
+func NewPersonalAccessTokenService(repo PersonalAccessTokenRepository, wishlists WishListRepository, policy *AccessPolicy) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{repo: repo, wishlists: wishlists, policy: policy}
+}
+
+// Create issues a token and returns it together with its secret, which is
+// not stored and cannot be shown again.
+func (s *PersonalAccessTokenService) Create(userID uint, req NewPersonalAccessToken) (*domain.PersonalAccessToken, string, error) {
+	if req.Scope != domain.ScopeRead && req.Scope != domain.ScopeWrite {
+		return nil, "", ErrInvalidScope
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidExpiry
+	}
+
+	// A token scoped to a wishlist only makes sense for lists the user can
+	// access; what it may do there is still decided by the user's role.
+	if req.WishListID != nil {
+		wishlist, err := s.wishlists.FindByID(*req.WishListID)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := s.policy.Authorize(wishlist, userID, ActionView); err != nil {
+			return nil, "", err
+		}
+	}
+
+	secret, err := randomToken(personalTokenBytes)
+	if err != nil {
+		return nil, "", err
+	}
+	plain := domain.PersonalAccessTokenPrefix + secret
+
+	token := &domain.PersonalAccessToken{
+		UserID:     userID,
+		Name:       strings.TrimSpace(req.Name),
+		Prefix:     plain[:personalTokenPrefixLength],
+		TokenHash:  hashToken(plain),
+		Scope:      req.Scope,
+		WishListID: req.WishListID,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.Create(token); err != nil {
+		return nil, "", err
+	}
+
+	return token, plain, nil
+}
+
+func (s *PersonalAccessTokenService) List(userID uint) ([]*domain.PersonalAccessToken, error) {
+	return s.repo.FindByUserID(userID)
+}
+
+func (s *PersonalAccessTokenService) Revoke(userID, tokenID uint) error {
+	revoked, err := s.repo.Revoke(tokenID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !revoked {
+		return ErrAccessTokenNotFound
+	}
+
+	return nil
+}
+
+// RevokeAll revokes every active token of the user.
+func (s *PersonalAccessTokenService) RevokeAll(userID uint) error {
+	return s.repo.RevokeAllForUser(userID)
+}
+
+// Authenticate returns the active token matching the secret and records
+// that it was used.
+func (s *PersonalAccessTokenService) Authenticate(plain string) (*domain.PersonalAccessToken, error) {
+	if !strings.HasPrefix(plain, domain.PersonalAccessTokenPrefix) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	token, err := s.repo.FindByHash(hashToken(plain))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token == nil || !token.Active(now) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchLastUsed(token.ID, now); err != nil {
+			return nil, err
+		}
+		token.LastUsedAt = &now
+	}
+
+	return token, nil
+}
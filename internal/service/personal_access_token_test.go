@@ -0,0 +1,75 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalAccessTokenService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	wishListRepo := repository.NewWishListRepository(db)
+	policy := NewAccessPolicy(repository.NewCollaboratorRepository(db), nil)
+	tokenService := NewPersonalAccessTokenService(repository.NewPersonalAccessTokenRepository(db), wishListRepo, policy)
+	userService := NewUserService(userRepo, nil)
+
+	owner, err := userService.Register("scripts@example.com", "password123")
+	require.NoError(t, err)
+	stranger, err := userService.Register("stranger@example.com", "password123")
+	require.NoError(t, err)
+
+	wishList := &domain.WishList{UserID: owner.ID, Name: "Automated", Status: "active"}
+	require.NoError(t, wishListRepo.Create(wishList))
+
+	t.Run("create and authenticate", func(t *testing.T) {
+		token, plain, err := tokenService.Create(owner.ID, NewPersonalAccessToken{Name: "backup", Scope: domain.ScopeRead})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(plain, domain.PersonalAccessTokenPrefix))
+		assert.True(t, strings.HasPrefix(plain, token.Prefix))
+		assert.Equal(t, hashToken(plain), token.TokenHash)
+
+		authenticated, err := tokenService.Authenticate(plain)
+		require.NoError(t, err)
+		assert.Equal(t, owner.ID, authenticated.UserID)
+		assert.NotNil(t, authenticated.LastUsedAt)
+
+		_, err = tokenService.Authenticate(plain + "x")
+		assert.ErrorIs(t, err, ErrInvalidAccessToken)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		token, plain, err := tokenService.Create(owner.ID, NewPersonalAccessToken{Name: "ci", Scope: domain.ScopeWrite})
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, tokenService.Revoke(stranger.ID, token.ID), ErrAccessTokenNotFound)
+		require.NoError(t, tokenService.Revoke(owner.ID, token.ID))
+
+		_, err = tokenService.Authenticate(plain)
+		assert.ErrorIs(t, err, ErrInvalidAccessToken)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		_, _, err := tokenService.Create(owner.ID, NewPersonalAccessToken{Name: "old", Scope: domain.ScopeRead, ExpiresAt: &past})
+		assert.ErrorIs(t, err, ErrInvalidExpiry)
+	})
+
+	t.Run("wishlist scope requires access", func(t *testing.T) {
+		_, _, err := tokenService.Create(stranger.ID, NewPersonalAccessToken{Name: "x", Scope: domain.ScopeRead, WishListID: &wishList.ID})
+		assert.ErrorIs(t, err, ErrAccessDenied)
+
+		token, _, err := tokenService.Create(owner.ID, NewPersonalAccessToken{Name: "x", Scope: domain.ScopeRead, WishListID: &wishList.ID})
+		require.NoError(t, err)
+		assert.Equal(t, wishList.ID, *token.WishListID)
+	})
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"wishlist/internal/domain"
+)
+
+// Action is something a user may attempt on a wishlist.
+type Action int
+
+const (
+	// ActionView allows reading the wishlist and its items.
+	ActionView Action = iota
+	// ActionEdit allows changing the wishlist contents and its items.
+	ActionEdit
+	// ActionManage allows deleting the wishlist, changing how it is shared
+	// and managing its collaborators.
+	ActionManage
+)
+
+// roleActions lists the most privileged action each role may perform;
+// actions are ordered so that a role implies everything below it.
+var roleActions = map[string]Action{
+	domain.RoleOwner:  ActionManage,
+	domain.RoleEditor: ActionEdit,
+	domain.RoleViewer: ActionView,
+}
+
+type CollaboratorRepository interface {
+	Find(wishlistID, userID uint) (*domain.Collaborator, error)
+	FindByWishListID(wishlistID uint) ([]*domain.Collaborator, error)
+	Update(collaborator *domain.Collaborator) error
+	Delete(wishlistID, userID uint) error
+}
+
+// AccessPolicy is the single place that decides who may do what with a
+// wishlist. Services must go through it instead of comparing user IDs.
+type AccessPolicy struct {
+	collaborators CollaboratorRepository
+	verification  *VerificationPolicy
+}
+
+// NewAccessPolicy creates the policy. verification may be nil when no action
+// requires a verified email address.
+func NewAccessPolicy(collaborators CollaboratorRepository, verification *VerificationPolicy) *AccessPolicy {
+	return &AccessPolicy{collaborators: collaborators, verification: verification}
+}
+
+// Role returns the role of the user on the wishlist, or an empty string if
+// the user has no access at all.
+func (p *AccessPolicy) Role(wishlist *domain.WishList, userID uint) (string, error) {
+	if userID != 0 && wishlist.UserID == userID {
+		return domain.RoleOwner, nil
+	}
+
+	if p == nil || p.collaborators == nil || userID == 0 {
+		return "", nil
+	}
+
+	collaborator, err := p.collaborators.Find(wishlist.ID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if collaborator == nil {
+		return "", nil
+	}
+
+	return collaborator.Role, nil
+}
+
+// Authorize returns ErrAccessDenied unless the user may perform the action.
+func (p *AccessPolicy) Authorize(wishlist *domain.WishList, userID uint, action Action) error {
+	role, err := p.Role(wishlist, userID)
+	if err != nil {
+		return err
+	}
+
+	allowed, ok := roleActions[role]
+	if !ok || allowed < action {
+		return ErrAccessDenied
+	}
+
+	return nil
+}
+
+// HidesReservations reports whether the user must not learn which items
+// are reserved: the owner and collaborators of a list in surprise mode.
+func (p *AccessPolicy) HidesReservations(wishlist *domain.WishList, userID uint) (bool, error) {
+	if !wishlist.SurpriseMode {
+		return false, nil
+	}
+
+	role, err := p.Role(wishlist, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return role != "", nil
+}
+
+// RequireVerified returns ErrEmailNotVerified if the deployment requires a
+// verified email address for the action and the user has none.
+func (p *AccessPolicy) RequireVerified(userID uint, action VerifiedAction) error {
+	if p == nil {
+		return nil
+	}
+	return p.verification.Require(userID, action)
+}
+
+func validCollaboratorRole(role string) bool {
+	return role == domain.RoleEditor || role == domain.RoleViewer
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubCollaboratorRepository struct {
+	roles map[uint]string
+}
+
+func (r *stubCollaboratorRepository) Find(wishlistID, userID uint) (*domain.Collaborator, error) {
+	role, ok := r.roles[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &domain.Collaborator{WishListID: wishlistID, UserID: userID, Role: role}, nil
+}
+
+func (r *stubCollaboratorRepository) FindByWishListID(wishlistID uint) ([]*domain.Collaborator, error) {
+	return nil, nil
+}
+
+func (r *stubCollaboratorRepository) Update(collaborator *domain.Collaborator) error {
+	return nil
+}
+
+func (r *stubCollaboratorRepository) Delete(wishlistID, userID uint) error {
+	return nil
+}
+
+func TestAccessPolicy_Authorize(t *testing.T) {
+	const (
+		ownerID  = 1
+		editorID = 2
+		viewerID = 3
+		otherID  = 4
+	)
+
+	policy := NewAccessPolicy(&stubCollaboratorRepository{roles: map[uint]string{
+		editorID: domain.RoleEditor,
+		viewerID: domain.RoleViewer,
+	}}, nil)
+	wishList := &domain.WishList{ID: 10, UserID: ownerID}
+
+	tests := []struct {
+		name    string
+		userID  uint
+		action  Action
+		allowed bool
+	}{
+		{"owner manages", ownerID, ActionManage, true},
+		{"editor edits", editorID, ActionEdit, true},
+		{"editor cannot manage", editorID, ActionManage, false},
+		{"viewer views", viewerID, ActionView, true},
+		{"viewer cannot edit", viewerID, ActionEdit, false},
+		{"stranger cannot view", otherID, ActionView, false},
+		{"anonymous cannot view", 0, ActionView, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(wishList, tt.userID, tt.action)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrAccessDenied)
+			}
+		})
+	}
+}
+
+type stubUserFinder struct {
+	users map[uint]*domain.User
+}
+
+func (f *stubUserFinder) FindByID(id uint) (*domain.User, error) {
+	return f.users[id], nil
+}
+
+func (f *stubUserFinder) FindByEmail(email string) (*domain.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestVerificationPolicy_Require(t *testing.T) {
+	verifiedAt := time.Now()
+	users := &stubUserFinder{users: map[uint]*domain.User{
+		1: {ID: 1, Email: "verified@example.com", EmailVerifiedAt: &verifiedAt},
+		2: {ID: 2, Email: "unverified@example.com"},
+	}}
+	policy := NewAccessPolicy(nil, NewVerificationPolicy(users, []string{"publish_wishlist", " invite_collaborator "}))
+
+	assert.NoError(t, policy.RequireVerified(1, VerifiedActionPublishWishList))
+	assert.ErrorIs(t, policy.RequireVerified(2, VerifiedActionPublishWishList), ErrEmailNotVerified)
+	assert.ErrorIs(t, policy.RequireVerified(2, VerifiedActionInviteCollaborator), ErrEmailNotVerified)
+	assert.NoError(t, policy.RequireVerified(2, VerifiedActionShareWishList), "action not configured")
+
+	assert.NoError(t, NewAccessPolicy(nil, nil).RequireVerified(2, VerifiedActionPublishWishList))
+}
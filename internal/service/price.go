@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/linkpreview"
+	"wishlist/internal/mail"
+)
+
+type PriceRepository interface {
+	FindDueForCheck(before time.Time, limit int) ([]*domain.WishItem, error)
+	RecordCheck(item *domain.WishItem, point *domain.PricePoint) error
+	FindHistory(wishlistID, itemID uint) ([]*domain.PricePoint, error)
+}
+
+// ProductFetcher reads the details of a product page, bypassing any cache.
+type ProductFetcher interface {
+	Fetch(ctx context.Context, url string) (*linkpreview.Metadata, error)
+}
+
+// PriceCheckPolicy controls how often item prices are re-read.
+type PriceCheckPolicy struct {
+	// Interval is the time between two checks of the same item.
+	Interval time.Duration
+	// BatchSize is the most items checked by one run of CheckDue.
+	BatchSize int
+}
+
+func DefaultPriceCheckPolicy() PriceCheckPolicy {
+	return PriceCheckPolicy{
+		Interval:  24 * time.Hour,
+		BatchSize: 100,
+	}
+}
+
+// PriceTracker keeps the prices of items with a product URL up to date,
+// records their history and sends alerts when a price drops below the
+// threshold set on the item.
+type PriceTracker struct {
+	prices    PriceRepository
+	wishlists WishListRepository
+	users     UserRepository
+	policy    *AccessPolicy
+	fetcher   ProductFetcher
+	mailer    mail.Mailer
+	appURL    string
+	checks    PriceCheckPolicy
+	now       func() time.Time
+}
+
+func NewPriceTracker(
+	prices PriceRepository,
+	wishlists WishListRepository,
+	users UserRepository,
+	policy *AccessPolicy,
+	fetcher ProductFetcher,
+	mailer mail.Mailer,
+	appURL string,
+	checks PriceCheckPolicy,
+) *PriceTracker {
+	return &PriceTracker{
+		prices:    prices,
+		wishlists: wishlists,
+		users:     users,
+		policy:    policy,
+		fetcher:   fetcher,
+		mailer:    mailer,
+		appURL:    appURL,
+		checks:    checks,
+		now:       time.Now,
+	}
+}
+
+// History returns the recorded prices of an item, oldest first.
+func (t *PriceTracker) History(wishlistID, itemID uint, userID uint) ([]*domain.PricePoint, error) {
+	wishlist, err := t.wishlists.FindByID(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.policy.Authorize(wishlist, userID, ActionView); err != nil {
+		return nil, err
+	}
+
+	return t.prices.FindHistory(wishlistID, itemID)
+}
+
+// CheckDue re-reads the prices of the items that are due for a check and
+// returns how many were checked. Pages that cannot be read are skipped
+// until the next interval; failures to send alerts are returned together
+// once every item was checked.
+func (t *PriceTracker) CheckDue(ctx context.Context) (int, error) {
+	items, err := t.prices.FindDueForCheck(t.now().Add(-t.checks.Interval), t.checks.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var alertErrs []error
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+
+		dropped, err := t.check(ctx, item)
+		if err != nil {
+			return i, fmt.Errorf("check price of item %d: %w", item.ID, err)
+		}
+
+		if dropped {
+			if err := t.sendAlerts(ctx, item); err != nil {
+				alertErrs = append(alertErrs, fmt.Errorf("price alert for item %d: %w", item.ID, err))
+			}
+		}
+	}
+
+	return len(items), errors.Join(alertErrs...)
+}
+
+// check reads the current price of the item and stores it. It reports
+// whether the price fell below the item's alert threshold.
+func (t *PriceTracker) check(ctx context.Context, item *domain.WishItem) (bool, error) {
+	now := t.now()
+	item.PriceCheckedAt = &now
+
+	meta, err := t.fetcher.Fetch(ctx, item.URL)
+	if err != nil || meta.Price == nil || meta.Currency == "" {
+		return false, t.prices.RecordCheck(item, nil)
+	}
+
+	// A price in another currency cannot be compared with the previous
+	// one; the item keeps the currency its owner chose.
+	if item.Currency != "" && item.Currency != meta.Currency {
+		return false, t.prices.RecordCheck(item, nil)
+	}
+
+	previous := item.Price
+	var point *domain.PricePoint
+	if previous == nil || previous.Cmp(*meta.Price) != 0 {
+		point = &domain.PricePoint{
+			WishItemID: item.ID,
+			Price:      *meta.Price,
+			Currency:   meta.Currency,
+			CheckedAt:  now,
+		}
+	}
+
+	item.Price = meta.Price
+	item.Currency = meta.Currency
+	if err := t.prices.RecordCheck(item, point); err != nil {
+		return false, err
+	}
+
+	return crossedBelow(previous, *meta.Price, item.PriceAlertBelow), nil
+}
+
+// crossedBelow reports whether the price went under the threshold, so that
+// an alert is sent once per drop rather than on every check.
+func crossedBelow(previous *domain.Decimal, current domain.Decimal, threshold *domain.Decimal) bool {
+	if threshold == nil || current.Cmp(*threshold) >= 0 {
+		return false
+	}
+	return previous == nil || previous.Cmp(*threshold) >= 0
+}
+
+// sendAlerts emails the owner of the item and, if the owner asked for it,
+// whoever reserved the item.
+func (t *PriceTracker) sendAlerts(ctx context.Context, item *domain.WishItem) error {
+	wishlist, err := t.wishlists.FindByID(item.WishListID)
+	if err != nil {
+		return err
+	}
+
+	owner, err := t.users.FindByID(wishlist.UserID)
+	if err != nil {
+		return err
+	}
+	if owner == nil {
+		return nil
+	}
+
+	subject := fmt.Sprintf("Price drop: %s", item.Name)
+	price := fmt.Sprintf("%s %s", item.Price, item.Currency)
+
+	err = t.mailer.Send(ctx, mail.Message{
+		To:      owner.Email,
+		Subject: subject,
+		Body: fmt.Sprintf("%s on your wishlist %q now costs %s, below the %s %s you were waiting for.\n\n%s\n\n"+
+			"Manage the price alert on your wishlist: %s/wishlists/%d\n",
+			item.Name, wishlist.Name, price, item.PriceAlertBelow, item.Currency, item.URL, t.appURL, wishlist.ID),
+	})
+	if err != nil {
+		return err
+	}
+
+	if !item.PriceAlertReservers || item.Reservation == nil {
+		return nil
+	}
+
+	reserver, err := t.reserverEmail(item.Reservation)
+	if err != nil || reserver == "" {
+		return err
+	}
+
+	return t.mailer.Send(ctx, mail.Message{
+		To:      reserver,
+		Subject: subject,
+		Body: fmt.Sprintf("%s, which you reserved, now costs %s.\n\n%s\n",
+			item.Name, price, item.URL),
+	})
+}
+
+func (t *PriceTracker) reserverEmail(reservation *domain.Reservation) (string, error) {
+	if reservation.UserID == nil {
+		return reservation.ClaimantEmail, nil
+	}
+
+	user, err := t.users.FindByID(*reservation.UserID)
+	if err != nil || user == nil {
+		return "", err
+	}
+	return user.Email, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/linkpreview"
+	"wishlist/internal/mail"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFetcher serves product details from a map of URL to price.
+type fakeFetcher map[string]string
+
+func (f fakeFetcher) Fetch(ctx context.Context, url string) (*linkpreview.Metadata, error) {
+	price, ok := f[url]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	d := domain.Decimal(price)
+	return &linkpreview.Metadata{URL: url, Price: &d, Currency: "EUR"}, nil
+}
+
+func decimalPtr(s string) *domain.Decimal {
+	d := domain.Decimal(s)
+	return &d
+}
+
+func TestCrossedBelow(t *testing.T) {
+	tests := []struct {
+		name      string
+		previous  *domain.Decimal
+		current   string
+		threshold *domain.Decimal
+		want      bool
+	}{
+		{"no threshold", decimalPtr("100"), "50", nil, false},
+		{"drops below", decimalPtr("100"), "79.99", decimalPtr("80"), true},
+		{"first price below", nil, "79.99", decimalPtr("80"), true},
+		{"at threshold", decimalPtr("100"), "80.00", decimalPtr("80"), false},
+		{"already below", decimalPtr("75"), "70", decimalPtr("80"), false},
+		{"rises", decimalPtr("75"), "90", decimalPtr("80"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, crossedBelow(tt.previous, domain.Decimal(tt.current), tt.threshold))
+		})
+	}
+}
+
+func TestPriceTracker(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	wishListRepo := repository.NewWishListRepository(db)
+	userService := NewUserService(userRepo, nil)
+	policy := NewAccessPolicy(repository.NewCollaboratorRepository(db), nil)
+	wishListService := NewWishListService(wishListRepo, policy)
+	fetcher := fakeFetcher{}
+	mailer := mail.NewMemoryMailer()
+	tracker := NewPriceTracker(repository.NewPriceRepository(db), wishListRepo, userRepo, policy, fetcher, mailer,
+		"https://wishlist.example.com", DefaultPriceCheckPolicy())
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	owner, err := userService.Register("owner@example.com", "password123")
+	require.NoError(t, err)
+	stranger, err := userService.Register("stranger@example.com", "password123")
+	require.NoError(t, err)
+
+	wishList := &domain.WishList{UserID: owner.ID, Name: "Birthday", Status: "active"}
+	require.NoError(t, wishListService.Create(wishList))
+
+	tracked := &domain.WishItem{
+		WishListID:          wishList.ID,
+		Name:                "Espresso machine",
+		URL:                 "https://shop.example.com/espresso",
+		Price:               decimalPtr("1299.00"),
+		Currency:            "EUR",
+		Quantity:            1,
+		PriceAlertBelow:     decimalPtr("1000"),
+		PriceAlertReservers: true,
+	}
+	require.NoError(t, wishListService.AddItem(tracked, owner.ID))
+	untracked := &domain.WishItem{WishListID: wishList.ID, Name: "Socks", Quantity: 1}
+	require.NoError(t, wishListService.AddItem(untracked, owner.ID))
+	require.NoError(t, db.Create(&domain.Reservation{
+		WishItemID: tracked.ID, ClaimantName: "Friend", ClaimantEmail: "friend@example.com", TokenHash: "hash",
+	}).Error)
+
+	t.Run("records price changes", func(t *testing.T) {
+		fetcher[tracked.URL] = "1199.00"
+		checked, err := tracker.CheckDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, checked)
+		assert.Empty(t, mailer.Messages())
+
+		item, err := wishListRepo.GetItem(wishList.ID, tracked.ID)
+		require.NoError(t, err)
+		require.NotNil(t, item.Price)
+		assert.Zero(t, item.Price.Cmp("1199"))
+		require.NotNil(t, item.PriceCheckedAt)
+
+		// Not due again until the interval has passed
+		checked, err = tracker.CheckDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, checked)
+	})
+
+	t.Run("alerts owner and reserver on a drop below the threshold", func(t *testing.T) {
+		now = now.Add(25 * time.Hour)
+		fetcher[tracked.URL] = "949.00"
+		_, err := tracker.CheckDue(ctx)
+		require.NoError(t, err)
+
+		msg, ok := mailer.Last(owner.Email)
+		require.True(t, ok)
+		assert.Contains(t, msg.Subject, "Espresso machine")
+		assert.Contains(t, msg.Body, "949.00 EUR")
+		_, ok = mailer.Last("friend@example.com")
+		assert.True(t, ok)
+
+		// Staying below the threshold does not alert again
+		now = now.Add(25 * time.Hour)
+		fetcher[tracked.URL] = "899.00"
+		_, err = tracker.CheckDue(ctx)
+		require.NoError(t, err)
+		assert.Len(t, mailer.Messages(), 2)
+	})
+
+	t.Run("unchanged and unreadable prices add no history", func(t *testing.T) {
+		now = now.Add(25 * time.Hour)
+		_, err := tracker.CheckDue(ctx)
+		require.NoError(t, err)
+
+		now = now.Add(25 * time.Hour)
+		delete(fetcher, tracked.URL)
+		checked, err := tracker.CheckDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, checked)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		points, err := tracker.History(wishList.ID, tracked.ID, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, points, 3)
+		assert.Zero(t, points[0].Price.Cmp("1199"))
+		assert.Zero(t, points[2].Price.Cmp("899"))
+		assert.Equal(t, "EUR", points[2].Currency)
+
+		points, err = tracker.History(wishList.ID, untracked.ID, owner.ID)
+		require.NoError(t, err)
+		assert.Empty(t, points)
+
+		_, err = tracker.History(wishList.ID, tracked.ID, stranger.ID)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+}
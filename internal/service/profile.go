@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"wishlist/internal/domain"
+)
+
+var (
+	ErrInvalidProfile = errors.New("invalid profile")
+	ErrEmailTaken     = errors.New("email address is already in use")
+	ErrEmailUnchanged = errors.New("new email address is the same as the current one")
+)
+
+// BirthdayLayout is the format birthdays are exchanged in.
+const BirthdayLayout = "2006-01-02"
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// ProfileUpdate holds the profile fields to change. Nil fields are left
+// alone; empty strings clear optional fields.
+type ProfileUpdate struct {
+	DisplayName *string
+	AvatarURL   *string
+	Birthday    *string
+	Locale      *string
+	Timezone    *string
+}
+
+// GetByID returns the user or ErrUserNotFound.
+func (s *UserService) GetByID(id uint) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// IsAdmin reports whether the user may use the admin API.
+func (s *UserService) IsAdmin(userID uint) (bool, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return user != nil && user.IsAdmin() && !user.Disabled(), nil
+}
+
+// UpdateProfile applies the given changes to the profile of the user.
+func (s *UserService) UpdateProfile(userID uint, update ProfileUpdate) (*domain.User, error) {
+	user, err := s.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(*update.DisplayName)
+	}
+
+	if update.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*update.AvatarURL)
+		if avatarURL != "" && !isWebURL(avatarURL) {
+			return nil, fmt.Errorf("%w: avatar_url must be an http or https URL", ErrInvalidProfile)
+		}
+		user.AvatarURL = avatarURL
+	}
+
+	if update.Birthday != nil {
+		birthday, err := parseBirthday(*update.Birthday)
+		if err != nil {
+			return nil, err
+		}
+		user.Birthday = birthday
+	}
+
+	if update.Locale != nil {
+		if !localePattern.MatchString(*update.Locale) {
+			return nil, fmt.Errorf("%w: locale must look like en or en-US", ErrInvalidProfile)
+		}
+		user.Locale = *update.Locale
+	}
+
+	if update.Timezone != nil {
+		if _, err := time.LoadLocation(*update.Timezone); err != nil || *update.Timezone == "" || *update.Timezone == "Local" {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidProfile, *update.Timezone)
+		}
+		user.Timezone = *update.Timezone
+	}
+
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ChangePassword replaces the password after checking the current one.
+// Callers end the other sessions and tokens of the user.
+func (s *UserService) ChangePassword(userID uint, currentPassword, newPassword string) error {
+	user, err := s.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = string(hash)
+	user.UpdatedAt = time.Now()
+	return s.userRepo.Update(user)
+}
+
+// RequestEmailChange records newEmail as the pending address of the user.
+// The current address stays in use until the new one is confirmed with the
+// link sent by EmailVerificationService.SendEmailChange. The user confirms
+// with the password or a recent login.
+func (s *UserService) RequestEmailChange(userID uint, proof Reauthentication, newEmail string) (*domain.User, error) {
+	user, err := s.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reauth.Confirm(user, proof); err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(newEmail, user.Email) {
+		return nil, ErrEmailUnchanged
+	}
+
+	existing, err := s.userRepo.FindByEmail(newEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+
+	user.PendingEmail = &newEmail
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func parseBirthday(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	birthday, err := time.Parse(BirthdayLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: birthday must be formatted as YYYY-MM-DD", ErrInvalidProfile)
+	}
+
+	if birthday.After(time.Now()) {
+		return nil, fmt.Errorf("%w: birthday is in the future", ErrInvalidProfile)
+	}
+
+	return &birthday, nil
+}
+
+func isWebURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
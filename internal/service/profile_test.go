@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"wishlist/internal/mail"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userService := NewUserService(repository.NewUserRepository(db), nil)
+
+	user, err := userService.Register("profile@example.com", "password123")
+	require.NoError(t, err)
+
+	t.Run("defaults", func(t *testing.T) {
+		stored, err := userService.GetByID(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "en", stored.Locale)
+		assert.Equal(t, "UTC", stored.Timezone)
+	})
+
+	t.Run("update fields", func(t *testing.T) {
+		name, avatar, birthday, locale, timezone := " Ada ", "https://cdn.example.com/ada.png", "1990-12-10", "en-GB", "Europe/London"
+		updated, err := userService.UpdateProfile(user.ID, ProfileUpdate{
+			DisplayName: &name,
+			AvatarURL:   &avatar,
+			Birthday:    &birthday,
+			Locale:      &locale,
+			Timezone:    &timezone,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", updated.DisplayName)
+
+		stored, err := userService.GetByID(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, avatar, stored.AvatarURL)
+		require.NotNil(t, stored.Birthday)
+		assert.Equal(t, birthday, stored.Birthday.Format(BirthdayLayout))
+		assert.Equal(t, locale, stored.Locale)
+		assert.Equal(t, timezone, stored.Timezone)
+	})
+
+	t.Run("partial update keeps other fields", func(t *testing.T) {
+		empty := ""
+		updated, err := userService.UpdateProfile(user.ID, ProfileUpdate{Birthday: &empty})
+		require.NoError(t, err)
+		assert.Nil(t, updated.Birthday)
+		assert.Equal(t, "Ada", updated.DisplayName)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		future := time.Now().AddDate(1, 0, 0).Format(BirthdayLayout)
+		for name, update := range map[string]ProfileUpdate{
+			"avatar":         {AvatarURL: strPtr("javascript:alert(1)")},
+			"birthday":       {Birthday: strPtr("10.12.1990")},
+			"future":         {Birthday: &future},
+			"locale":         {Locale: strPtr("english")},
+			"timezone":       {Timezone: strPtr("Mars/Olympus")},
+			"local timezone": {Timezone: strPtr("Local")},
+		} {
+			_, err := userService.UpdateProfile(user.ID, update)
+			assert.ErrorIs(t, err, ErrInvalidProfile, name)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := userService.UpdateProfile(user.ID+1000, ProfileUpdate{})
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userService := NewUserService(repository.NewUserRepository(db), nil)
+
+	user, err := userService.Register("password@example.com", "password123")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, userService.ChangePassword(user.ID, "wrong", "new-password"), ErrInvalidCredentials)
+
+	require.NoError(t, userService.ChangePassword(user.ID, "password123", "new-password"))
+	_, err = userService.Login(user.Email, "new-password")
+	assert.NoError(t, err)
+}
+
+func TestUserService_ChangeEmail(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	userService := NewUserService(userRepo, nil)
+	mailer := mail.NewMemoryMailer()
+	verificationService := NewEmailVerificationService(repository.NewEmailVerificationRepository(db), userRepo,
+		mailer, "https://wishlist.example.com", DefaultEmailVerificationTTL, time.Hour)
+	ctx := context.Background()
+
+	user, err := userService.Register("old@example.com", "password123")
+	require.NoError(t, err)
+	_, err = userService.Register("taken@example.com", "password123")
+	require.NoError(t, err)
+
+	t.Run("requires password and a new free address", func(t *testing.T) {
+		_, err := userService.RequestEmailChange(user.ID, Reauthentication{Password: "wrong"}, "new@example.com")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		_, err = userService.RequestEmailChange(user.ID, Reauthentication{Password: "password123"}, "OLD@example.com")
+		assert.ErrorIs(t, err, ErrEmailUnchanged)
+
+		_, err = userService.RequestEmailChange(user.ID, Reauthentication{Password: "password123"}, "taken@example.com")
+		assert.ErrorIs(t, err, ErrEmailTaken)
+	})
+
+	t.Run("address changes once confirmed", func(t *testing.T) {
+		pending, err := userService.RequestEmailChange(user.ID, Reauthentication{Password: "password123"}, "new@example.com")
+		require.NoError(t, err)
+		require.NoError(t, verificationService.SendEmailChange(ctx, pending))
+
+		stored, err := userService.GetByID(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "old@example.com", stored.Email)
+		require.NotNil(t, stored.PendingEmail)
+
+		msg, ok := mailer.Last("new@example.com")
+		require.True(t, ok)
+		assert.Regexp(t, regexp.MustCompile(`verify-email\?token=`), msg.Body)
+
+		verified, err := verificationService.Verify(verificationTokenFrom(t, mailer, "new@example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", verified.Email)
+		assert.Nil(t, verified.PendingEmail)
+		assert.True(t, verified.EmailVerified())
+
+		_, err = userService.Login("new@example.com", "password123")
+		assert.NoError(t, err)
+	})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"wishlist/internal/domain"
+)
+
+var ErrRecentLoginRequired = errors.New("log in again to confirm")
+
+// recentLoginWindow is how recently users who sign in through an identity
+// provider must have logged in to confirm a sensitive request without a
+// password.
+const recentLoginWindow = 10 * time.Minute
+
+// Reauthentication is what a user presents to confirm a sensitive request
+// such as deleting the account: the password, or the current session if it
+// started with a recent login.
+type Reauthentication struct {
+	SessionID string
+	Password  string
+}
+
+// Reauthenticator checks that a sensitive request comes from the user and
+// not just from a stolen session. Accounts created through an identity
+// provider have no password, so users with a linked identity may omit it
+// right after logging in.
+type Reauthenticator struct {
+	identities IdentityRepository
+	tokens     *TokenService
+}
+
+func NewReauthenticator(identities IdentityRepository, tokens *TokenService) *Reauthenticator {
+	return &Reauthenticator{identities: identities, tokens: tokens}
+}
+
+// Confirm returns nil if the proof is good enough for the user. A nil
+// Reauthenticator only accepts the password.
+func (r *Reauthenticator) Confirm(user *domain.User, proof Reauthentication) error {
+	if proof.Password != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(proof.Password)); err != nil {
+			return ErrInvalidCredentials
+		}
+		return nil
+	}
+
+	if r == nil {
+		return ErrInvalidCredentials
+	}
+
+	identities, err := r.identities.FindByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(identities) == 0 {
+		return ErrInvalidCredentials
+	}
+
+	fresh, err := r.tokens.LoggedInSince(user.ID, proof.SessionID, time.Now().Add(-recentLoginWindow))
+	if err != nil {
+		return err
+	}
+
+	if !fresh {
+		return ErrRecentLoginRequired
+	}
+
+	return nil
+}
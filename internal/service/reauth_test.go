@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"wishlist/internal/auth"
+	"wishlist/internal/domain"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReauthenticator(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	tokenService := NewTokenService(repository.NewTokenRepository(db),
+		auth.NewJWTManager("test-secret", time.Hour), DefaultRefreshTokenTTL)
+	reauth := NewReauthenticator(identityRepo, tokenService)
+
+	user, err := NewUserService(userRepo, reauth).Register("user@example.com", "password123")
+	require.NoError(t, err)
+
+	t.Run("password", func(t *testing.T) {
+		assert.NoError(t, reauth.Confirm(user, Reauthentication{Password: "password123"}))
+		assert.ErrorIs(t, reauth.Confirm(user, Reauthentication{Password: "wrong"}), ErrInvalidCredentials)
+
+		var passwordOnly *Reauthenticator
+		assert.NoError(t, passwordOnly.Confirm(user, Reauthentication{Password: "password123"}))
+		assert.ErrorIs(t, passwordOnly.Confirm(user, Reauthentication{}), ErrInvalidCredentials)
+	})
+
+	t.Run("accounts without a password confirm with a recent login", func(t *testing.T) {
+		passwordHash, err := unusablePasswordHash()
+		require.NoError(t, err)
+		linked := &domain.User{Email: "linked@example.com", PasswordHash: passwordHash}
+		require.NoError(t, userRepo.Create(linked))
+
+		_, err = tokenService.Issue(linked.ID, ClientInfo{})
+		require.NoError(t, err)
+		sessions, err := tokenService.Sessions(linked.ID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		sessionID := sessions[0].ID
+
+		// Without a linked identity the password is required
+		err = reauth.Confirm(linked, Reauthentication{SessionID: sessionID})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		require.NoError(t, identityRepo.Create(&domain.UserIdentity{
+			UserID: linked.ID, Provider: "test", Subject: "linked", Email: linked.Email, CreatedAt: time.Now(),
+		}))
+		assert.NoError(t, reauth.Confirm(linked, Reauthentication{SessionID: sessionID}))
+
+		// A session started too long ago does not count, nor does none
+		loggedInAt := time.Now().Add(-time.Hour)
+		require.NoError(t, db.Model(&domain.TokenFamily{}).Where("id = ?", sessionID).
+			Update("created_at", loggedInAt).Error)
+		err = reauth.Confirm(linked, Reauthentication{SessionID: sessionID})
+		assert.ErrorIs(t, err, ErrRecentLoginRequired)
+		err = reauth.Confirm(linked, Reauthentication{})
+		assert.ErrorIs(t, err, ErrRecentLoginRequired)
+
+		// Nor does the session of another user
+		_, err = tokenService.Issue(user.ID, ClientInfo{})
+		require.NoError(t, err)
+		sessions, err = tokenService.Sessions(user.ID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		err = reauth.Confirm(linked, Reauthentication{SessionID: sessions[0].ID})
+		assert.ErrorIs(t, err, ErrRecentLoginRequired)
+	})
+}
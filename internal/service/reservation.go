@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"wishlist/internal/domain"
+)
+
+var (
+	ErrItemNotFound        = errors.New("item not found")
+	ErrItemAlreadyReserved = errors.New("item already reserved")
+	ErrItemNotReserved     = errors.New("item is not reserved")
+	ErrClaimantRequired    = errors.New("claimant name is required")
+)
+
+const reservationTokenBytes = 32
+
+type ReservationRepository interface {
+	CreateIfAbsent(reservation *domain.Reservation) (bool, error)
+	FindByItemID(itemID uint) (*domain.Reservation, error)
+	Delete(id uint) error
+}
+
+type ReservationService struct {
+	repo      ReservationRepository
+	wishlists WishListRepository
+	policy    *AccessPolicy
+}
+
+func NewReservationService(repo ReservationRepository, wishlists WishListRepository, policy *AccessPolicy) *ReservationService {
+	return &ReservationService{repo: repo, wishlists: wishlists, policy: policy}
+}
+
+// Claimant describes who reserves an item. Either UserID is set for a
+// logged-in friend, or Name (and optionally Email) for an anonymous one.
+type Claimant struct {
+	UserID uint
+	Name   string
+	Email  string
+}
+
+// Claim reserves an item on a shared wishlist. The returned token is the only
+// way for an anonymous claimant to release the reservation later; it is not
+// stored in plain text.
+func (s *ReservationService) Claim(shareCode string, itemID uint, claimant Claimant) (*domain.Reservation, string, error) {
+	wishlist, err := s.sharedWishList(shareCode)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !containsItem(wishlist, itemID) {
+		return nil, "", ErrItemNotFound
+	}
+
+	// Owners reserving their own items would spoil the point of the
+	// feature. In surprise mode collaborators may not reserve either, as a
+	// conflict would tell them the item is taken.
+	if claimant.UserID != 0 && claimant.UserID == wishlist.UserID {
+		return nil, "", ErrAccessDenied
+	}
+
+	hide, err := s.policy.HidesReservations(wishlist, claimant.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if hide {
+		return nil, "", ErrAccessDenied
+	}
+
+	if claimant.UserID == 0 && claimant.Name == "" {
+		return nil, "", ErrClaimantRequired
+	}
+
+	token, err := randomToken(reservationTokenBytes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	reservation := &domain.Reservation{
+		WishItemID:    itemID,
+		ClaimantName:  claimant.Name,
+		ClaimantEmail: claimant.Email,
+		TokenHash:     hashToken(token),
+		CreatedAt:     time.Now(),
+	}
+	if claimant.UserID != 0 {
+		reservation.UserID = &claimant.UserID
+	}
+
+	created, err := s.repo.CreateIfAbsent(reservation)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !created {
+		return nil, "", ErrItemAlreadyReserved
+	}
+
+	return reservation, token, nil
+}
+
+// Unclaim releases a reservation. The caller must present the token issued on
+// claim, or be the logged-in user who made the reservation.
+func (s *ReservationService) Unclaim(shareCode string, itemID uint, token string, userID uint) error {
+	wishlist, err := s.sharedWishList(shareCode)
+	if err != nil {
+		return err
+	}
+
+	if !containsItem(wishlist, itemID) {
+		return ErrItemNotFound
+	}
+
+	reservation, err := s.repo.FindByItemID(itemID)
+	if err != nil {
+		return err
+	}
+
+	if reservation == nil {
+		// Members of a list in surprise mode must not learn that the item
+		// is free either
+		hide, err := s.policy.HidesReservations(wishlist, userID)
+		if err != nil {
+			return err
+		}
+		if hide {
+			return ErrAccessDenied
+		}
+		return ErrItemNotReserved
+	}
+
+	ownsByUser := userID != 0 && reservation.UserID != nil && *reservation.UserID == userID
+	ownsByToken := tokenMatches(token, reservation.TokenHash)
+
+	if !ownsByUser && !ownsByToken {
+		return ErrAccessDenied
+	}
+
+	return s.repo.Delete(reservation.ID)
+}
+
+func (s *ReservationService) sharedWishList(shareCode string) (*domain.WishList, error) {
+	wishlist, err := s.wishlists.FindByShareCode(shareCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if wishlist == nil || !wishlist.IsPublic {
+		return nil, ErrWishListNotFound
+	}
+
+	return wishlist, nil
+}
+
+func containsItem(wishlist *domain.WishList, itemID uint) bool {
+	for _, item := range wishlist.Items {
+		if item.ID == itemID {
+			return true
+		}
+	}
+	return false
+}
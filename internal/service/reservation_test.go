@@ -0,0 +1,175 @@
+package service
+
+import (
+	"sync"
+	"testing"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservationService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	wishListRepo := repository.NewWishListRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
+
+	userService := NewUserService(userRepo, nil)
+	policy := NewAccessPolicy(repository.NewCollaboratorRepository(db), nil)
+	wishListService := NewWishListService(wishListRepo, policy)
+	reservationService := NewReservationService(reservationRepo, wishListRepo, policy)
+
+	owner, err := userService.Register("owner@example.com", "password123")
+	require.NoError(t, err)
+	friend, err := userService.Register("friend@example.com", "password123")
+	require.NoError(t, err)
+
+	wishList := &domain.WishList{
+		UserID: owner.ID,
+		Name:   "Birthday",
+		Status: "active",
+	}
+	require.NoError(t, wishListService.Create(wishList))
+
+	item := &domain.WishItem{
+		WishListID: wishList.ID,
+		Name:       "Book",
+		Status:     "wanted",
+	}
+	require.NoError(t, wishListService.AddItem(item, owner.ID))
+
+	code, err := wishListService.GenerateShareCode(wishList.ID, owner.ID)
+	require.NoError(t, err)
+	_, err = wishListService.UpdateShareSettings(wishList.ID, owner.ID, true, nil)
+	require.NoError(t, err)
+
+	t.Run("anonymous claim and unclaim", func(t *testing.T) {
+		reservation, token, err := reservationService.Claim(code, item.ID, Claimant{Name: "Aunt May"})
+		require.NoError(t, err)
+		assert.NotZero(t, reservation.ID)
+		assert.NotEmpty(t, token)
+
+		shared, err := wishListService.GetByShareCode(code, 0)
+		require.NoError(t, err)
+		require.Len(t, shared.Items, 1)
+		assert.True(t, shared.Items[0].Reserved)
+
+		own, err := wishListService.GetByID(wishList.ID, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, own.Items, 1)
+		assert.True(t, own.Items[0].Reserved)
+
+		err = reservationService.Unclaim(code, item.ID, "wrong-token", 0)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+
+		err = reservationService.Unclaim(code, item.ID, token, 0)
+		require.NoError(t, err)
+
+		err = reservationService.Unclaim(code, item.ID, token, 0)
+		assert.ErrorIs(t, err, ErrItemNotReserved)
+	})
+
+	t.Run("logged-in claim", func(t *testing.T) {
+		_, _, err := reservationService.Claim(code, item.ID, Claimant{UserID: friend.ID})
+		require.NoError(t, err)
+
+		err = reservationService.Unclaim(code, item.ID, "", friend.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("owner cannot claim", func(t *testing.T) {
+		_, _, err := reservationService.Claim(code, item.ID, Claimant{UserID: owner.ID})
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+
+	t.Run("surprise mode hides reservations from members", func(t *testing.T) {
+		partner, err := userService.Register("partner@example.com", "password123")
+		require.NoError(t, err)
+		require.NoError(t, db.Create(&domain.Collaborator{
+			WishListID: wishList.ID, UserID: partner.ID, Role: domain.RoleEditor,
+		}).Error)
+		surprise := true
+		_, err = wishListService.UpdateShareSettings(wishList.ID, owner.ID, true, &surprise)
+		require.NoError(t, err)
+		defer func() {
+			surprise = false
+			_, err := wishListService.UpdateShareSettings(wishList.ID, owner.ID, true, &surprise)
+			require.NoError(t, err)
+		}()
+
+		// A free item does not tell members anything either
+		err = reservationService.Unclaim(code, item.ID, "", partner.ID)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+
+		_, token, err := reservationService.Claim(code, item.ID, Claimant{UserID: friend.ID})
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, reservationService.Unclaim(code, item.ID, token, 0))
+		}()
+
+		for _, viewer := range []uint{owner.ID, partner.ID} {
+			shared, err := wishListService.GetByShareCode(code, viewer)
+			require.NoError(t, err)
+			require.Len(t, shared.Items, 1)
+			assert.False(t, shared.Items[0].Reserved)
+		}
+
+		shared, err := wishListService.GetByShareCode(code, friend.ID)
+		require.NoError(t, err)
+		assert.True(t, shared.Items[0].Reserved)
+
+		_, _, err = reservationService.Claim(code, item.ID, Claimant{UserID: partner.ID})
+		assert.ErrorIs(t, err, ErrAccessDenied)
+		err = reservationService.Unclaim(code, item.ID, "", partner.ID)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+
+	t.Run("concurrent claims", func(t *testing.T) {
+		const claimants = 5
+
+		var wg sync.WaitGroup
+		errs := make([]error, claimants)
+		for i := 0; i < claimants; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, _, errs[i] = reservationService.Claim(code, item.ID, Claimant{Name: "Friend"})
+			}(i)
+		}
+		wg.Wait()
+
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, ErrItemAlreadyReserved)
+		}
+		assert.Equal(t, 1, succeeded)
+	})
+}
+
+func TestMarkReservations(t *testing.T) {
+	wishList := &domain.WishList{
+		Items: []domain.WishItem{
+			{ID: 1, Reservation: &domain.Reservation{ID: 1}},
+			{ID: 2},
+		},
+	}
+
+	markReservations(wishList, false)
+	assert.True(t, wishList.Items[0].Reserved)
+	assert.False(t, wishList.Items[1].Reserved)
+
+	// Surprise mode hides reservations from the owner
+	markReservations(wishList, true)
+	assert.False(t, wishList.Items[0].Reserved)
+	assert.False(t, wishList.Items[1].Reserved)
+}
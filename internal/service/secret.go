@@ -0,0 +1,31 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// randomToken returns n bytes of cryptographically secure randomness encoded
+// as URL-safe base64, suitable for links and headers.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex SHA-256 digest under which secret tokens are
+// persisted. Tokens are high-entropy, so a plain hash is sufficient.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenMatches reports whether token hashes to the stored hash, in constant time.
+func tokenMatches(token, hash string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(hash)) == 1
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"wishlist/internal/domain"
+)
+
+var (
+	ErrTagNotFound     = errors.New("tag not found")
+	ErrTagExists       = errors.New("a tag with this name already exists")
+	ErrInvalidTagName  = errors.New("tag names must be 1 to 50 characters without commas")
+	ErrInvalidTagMerge = errors.New("a tag cannot be merged into itself")
+	ErrTooManyTags     = fmt.Errorf("at most %d tags are allowed", domain.MaxTagsPerTarget)
+)
+
+// maxTagNameLength is the longest tag name, in characters.
+const maxTagNameLength = 50
+
+type TagRepository interface {
+	Create(tag *domain.Tag) error
+	FindByID(id uint) (*domain.Tag, error)
+	FindByNames(userID uint, names []string) ([]*domain.Tag, error)
+	FindUsageByUserID(userID uint) ([]*domain.TagUsage, error)
+	Update(tag *domain.Tag) error
+	Delete(id uint) error
+	Merge(sourceID, targetID uint) error
+	ReplaceWishListTags(wishlistID uint, tags []*domain.Tag) error
+	ReplaceItemTags(itemID uint, tags []*domain.Tag) error
+}
+
+// TagService manages the tags of a user and puts them on wishlists and
+// items.
+type TagService struct {
+	tags      TagRepository
+	wishlists WishListRepository
+	policy    *AccessPolicy
+}
+
+func NewTagService(tags TagRepository, wishlists WishListRepository, policy *AccessPolicy) *TagService {
+	return &TagService{tags: tags, wishlists: wishlists, policy: policy}
+}
+
+// List returns the tags of the user with how often each is used.
+func (s *TagService) List(userID uint) ([]*domain.TagUsage, error) {
+	return s.tags.FindUsageByUserID(userID)
+}
+
+func (s *TagService) Create(name string, userID uint) (*domain.Tag, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.tags.FindByNames(userID, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, ErrTagExists
+	}
+
+	now := time.Now()
+	tag := &domain.Tag{UserID: userID, Name: name, CreatedAt: now, UpdatedAt: now}
+	if err := s.tags.Create(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// Rename changes the name of a tag. Renaming a tag to the name of another
+// one fails with ErrTagExists; such tags are combined with Merge.
+func (s *TagService) Rename(tagID uint, name string, userID uint) (*domain.Tag, error) {
+	tag, err := s.ownTag(tagID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err = normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.tags.FindByNames(userID, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range existing {
+		if other.ID != tag.ID {
+			return nil, ErrTagExists
+		}
+	}
+
+	tag.Name = name
+	tag.UpdatedAt = time.Now()
+	if err := s.tags.Update(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// Merge replaces the tag sourceID with targetID everywhere and deletes the
+// source tag. It returns the target tag.
+func (s *TagService) Merge(sourceID, targetID uint, userID uint) (*domain.Tag, error) {
+	if sourceID == targetID {
+		return nil, ErrInvalidTagMerge
+	}
+
+	if _, err := s.ownTag(sourceID, userID); err != nil {
+		return nil, err
+	}
+	target, err := s.ownTag(targetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tags.Merge(sourceID, targetID); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// Delete removes a tag from everything it is on.
+func (s *TagService) Delete(tagID uint, userID uint) error {
+	if _, err := s.ownTag(tagID, userID); err != nil {
+		return err
+	}
+	return s.tags.Delete(tagID)
+}
+
+// SetWishListTags replaces the tags of a wishlist with the named tags of
+// its owner, creating the ones that do not exist yet.
+func (s *TagService) SetWishListTags(wishlistID uint, names []string, userID uint) ([]*domain.Tag, error) {
+	wishlist, err := s.wishlists.FindByID(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionEdit); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.resolve(wishlist.UserID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tags.ReplaceWishListTags(wishlist.ID, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// SetItemTags replaces the tags of an item with the named tags of the
+// wishlist owner, creating the ones that do not exist yet.
+func (s *TagService) SetItemTags(wishlistID, itemID uint, names []string, userID uint) ([]*domain.Tag, error) {
+	wishlist, err := s.wishlists.FindByID(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionEdit); err != nil {
+		return nil, err
+	}
+
+	item, err := s.wishlists.GetItem(wishlistID, itemID)
+	if err != nil {
+		return nil, ErrItemNotFound
+	}
+
+	tags, err := s.resolve(wishlist.UserID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tags.ReplaceItemTags(item.ID, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// resolve returns the tags of the user with the given names in the given
+// order, creating missing ones. Names differing only in case are one tag.
+func (s *TagService) resolve(userID uint, names []string) ([]*domain.Tag, error) {
+	var normalized []string
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			normalized = append(normalized, name)
+		}
+	}
+	if len(normalized) > domain.MaxTagsPerTarget {
+		return nil, ErrTooManyTags
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+
+	existing, err := s.tags.FindByNames(userID, normalized)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*domain.Tag, len(existing))
+	for _, tag := range existing {
+		byName[strings.ToLower(tag.Name)] = tag
+	}
+
+	tags := make([]*domain.Tag, 0, len(normalized))
+	for _, name := range normalized {
+		tag, ok := byName[strings.ToLower(name)]
+		if !ok {
+			now := time.Now()
+			tag = &domain.Tag{UserID: userID, Name: name, CreatedAt: now, UpdatedAt: now}
+			if err := s.tags.Create(tag); err != nil {
+				return nil, err
+			}
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// ownTag returns the tag if it belongs to the user. Tags of other users
+// are reported as not found.
+func (s *TagService) ownTag(tagID, userID uint) (*domain.Tag, error) {
+	tag, err := s.tags.FindByID(tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil || tag.UserID != userID {
+		return nil, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+// normalizeTagName trims the name and collapses inner whitespace. Commas
+// are not allowed since they separate tags in filters.
+func normalizeTagName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || utf8.RuneCountInString(name) > maxTagNameLength || strings.Contains(name, ",") {
+		return "", ErrInvalidTagName
+	}
+	return name, nil
+}
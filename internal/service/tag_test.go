@@ -0,0 +1,168 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTagName(t *testing.T) {
+	name, err := normalizeTagName("  board   games ")
+	require.NoError(t, err)
+	assert.Equal(t, "board games", name)
+
+	for _, invalid := range []string{"", "   ", "a,b", strings.Repeat("x", maxTagNameLength+1)} {
+		_, err := normalizeTagName(invalid)
+		assert.ErrorIs(t, err, ErrInvalidTagName, invalid)
+	}
+}
+
+func tagNames(tags []domain.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func TestTagService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	wishListRepo := repository.NewWishListRepository(db)
+	userService := NewUserService(userRepo, nil)
+	policy := NewAccessPolicy(repository.NewCollaboratorRepository(db), nil)
+	wishListService := NewWishListService(wishListRepo, policy)
+	tagService := NewTagService(repository.NewTagRepository(db), wishListRepo, policy)
+
+	owner, err := userService.Register("owner@example.com", "password123")
+	require.NoError(t, err)
+	stranger, err := userService.Register("stranger@example.com", "password123")
+	require.NoError(t, err)
+
+	birthday := &domain.WishList{UserID: owner.ID, Name: "Birthday", Status: "active"}
+	require.NoError(t, wishListService.Create(birthday))
+	christmas := &domain.WishList{UserID: owner.ID, Name: "Christmas", Status: "active"}
+	require.NoError(t, wishListService.Create(christmas))
+
+	book := &domain.WishItem{WishListID: birthday.ID, Name: "Book", Quantity: 1}
+	require.NoError(t, wishListService.AddItem(book, owner.ID))
+	kettle := &domain.WishItem{WishListID: birthday.ID, Name: "Kettle", Quantity: 1}
+	require.NoError(t, wishListService.AddItem(kettle, owner.ID))
+
+	t.Run("set tags creates missing ones", func(t *testing.T) {
+		tags, err := tagService.SetWishListTags(birthday.ID, []string{"Family", " gifts ", "family"}, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, tags, 2)
+		assert.Equal(t, "Family", tags[0].Name)
+		assert.Equal(t, "gifts", tags[1].Name)
+
+		_, err = tagService.SetWishListTags(christmas.ID, []string{"FAMILY"}, owner.ID)
+		require.NoError(t, err)
+		_, err = tagService.SetItemTags(birthday.ID, book.ID, []string{"Reading", "gifts"}, owner.ID)
+		require.NoError(t, err)
+		_, err = tagService.SetItemTags(birthday.ID, kettle.ID, []string{"kitchen"}, owner.ID)
+		require.NoError(t, err)
+
+		usages, err := tagService.List(owner.ID)
+		require.NoError(t, err)
+		require.Len(t, usages, 4)
+		assert.Equal(t, "Family", usages[0].Name)
+		assert.EqualValues(t, 2, usages[0].WishListCount)
+		assert.Equal(t, "gifts", usages[1].Name)
+		assert.EqualValues(t, 1, usages[1].WishListCount)
+		assert.EqualValues(t, 1, usages[1].ItemCount)
+
+		_, err = tagService.SetWishListTags(birthday.ID, []string{"other"}, stranger.ID)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+		_, err = tagService.SetItemTags(birthday.ID, book.ID+100, []string{"other"}, owner.ID)
+		assert.ErrorIs(t, err, ErrItemNotFound)
+	})
+
+	t.Run("filters by tags", func(t *testing.T) {
+		lists, err := wishListService.GetByUserID(owner.ID, domain.WishListQuery{Tags: domain.TagFilter{Names: []string{"family"}}})
+		require.NoError(t, err)
+		assert.Len(t, lists.WishLists, 2)
+
+		lists, err = wishListService.GetByUserID(owner.ID, domain.WishListQuery{Tags: domain.TagFilter{Names: []string{"family", "gifts"}}})
+		require.NoError(t, err)
+		require.Len(t, lists.WishLists, 1)
+		assert.Equal(t, birthday.ID, lists.WishLists[0].ID)
+		assert.ElementsMatch(t, []string{"Family", "gifts"}, tagNames(lists.WishLists[0].Tags))
+
+		items, err := wishListService.ListItems(birthday.ID, domain.ItemQuery{Tags: domain.TagFilter{Names: []string{"reading", "kitchen"}, MatchAny: true}}, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, items.Items, 2)
+
+		items, err = wishListService.ListItems(birthday.ID, domain.ItemQuery{Tags: domain.TagFilter{Names: []string{"reading", "kitchen"}}}, owner.ID)
+		require.NoError(t, err)
+		assert.Empty(t, items.Items)
+
+		_, err = wishListService.ListItems(birthday.ID, domain.ItemQuery{}, stranger.ID)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+
+	t.Run("rename and merge", func(t *testing.T) {
+		usages, err := tagService.List(owner.ID)
+		require.NoError(t, err)
+		byName := make(map[string]*domain.Tag)
+		for _, usage := range usages {
+			tag := usage.Tag
+			byName[tag.Name] = &tag
+		}
+
+		_, err = tagService.Rename(byName["kitchen"].ID, "GIFTS", owner.ID)
+		assert.ErrorIs(t, err, ErrTagExists)
+		_, err = tagService.Rename(byName["kitchen"].ID, "home", stranger.ID)
+		assert.ErrorIs(t, err, ErrTagNotFound)
+		renamed, err := tagService.Rename(byName["kitchen"].ID, "Home", owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Home", renamed.Name)
+
+		_, err = tagService.Merge(byName["gifts"].ID, byName["gifts"].ID, owner.ID)
+		assert.ErrorIs(t, err, ErrInvalidTagMerge)
+		target, err := tagService.Merge(byName["gifts"].ID, byName["Family"].ID, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Family", target.Name)
+
+		got, err := wishListService.GetByID(birthday.ID, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Family"}, tagNames(got.Tags))
+		for _, item := range got.Items {
+			if item.ID == book.ID {
+				assert.Equal(t, []string{"Family", "Reading"}, tagNames(item.Tags))
+			}
+		}
+	})
+
+	t.Run("create and delete", func(t *testing.T) {
+		_, err := tagService.Create("family", owner.ID)
+		assert.ErrorIs(t, err, ErrTagExists)
+		tag, err := tagService.Create("Garden", owner.ID)
+		require.NoError(t, err)
+
+		// Tag names are per user
+		_, err = tagService.Create("Garden", stranger.ID)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, tagService.Delete(tag.ID, stranger.ID), ErrTagNotFound)
+		require.NoError(t, tagService.Delete(tag.ID, owner.ID))
+		assert.ErrorIs(t, tagService.Delete(tag.ID, owner.ID), ErrTagNotFound)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		names := make([]string, domain.MaxTagsPerTarget+1)
+		for i := range names {
+			names[i] = strings.Repeat("t", i+1)
+		}
+		_, err := tagService.SetWishListTags(birthday.ID, names, owner.ID)
+		assert.ErrorIs(t, err, ErrTooManyTags)
+	})
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"wishlist/internal/auth"
+	"wishlist/internal/domain"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+const (
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+	refreshTokenBytes = 32
+	familyIDBytes     = 18
+
+	// sessionTouchInterval limits how often using an access token updates
+	// the last-seen time of its session.
+	sessionTouchInterval = time.Minute
+)
+
+type TokenRepository interface {
+	CreateFamily(family *domain.TokenFamily) error
+	FindFamily(id string) (*domain.TokenFamily, error)
+	RevokeFamily(id string) error
+	RevokeUserFamilies(userID uint) error
+	RevokeOtherFamilies(userID uint, keepID string) error
+	FindActiveFamilies(userID uint, now time.Time) ([]*domain.TokenFamily, error)
+	ExtendFamily(id, ip, userAgent string, seenAt, expiresAt time.Time) error
+	TouchFamily(id string, seenAt time.Time) error
+	CreateRefreshToken(token *domain.RefreshToken) error
+	FindRefreshTokenByHash(hash string) (*domain.RefreshToken, error)
+	MarkRefreshTokenUsed(id uint) (bool, error)
+}
+
+// ClientInfo describes the device a session is used from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// TokenPair is what a client receives on login and on every refresh.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// TokenService issues access tokens together with rotating refresh tokens.
+// Refresh tokens are single-use: each refresh consumes the presented token
+// and issues a new one in the same family. Presenting a consumed token again
+// means it leaked, so the whole family is revoked.
+type TokenService struct {
+	repo       TokenRepository
+	jwtManager *auth.JWTManager
+	refreshTTL time.Duration
+}
+
+func NewTokenService(repo TokenRepository, jwtManager *auth.JWTManager, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		repo:       repo,
+		jwtManager: jwtManager,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// Issue starts a new token family for the user, i.e. a new login session.
+func (s *TokenService) Issue(userID uint, client ClientInfo) (*TokenPair, error) {
+	familyID, err := randomToken(familyIDBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	family := &domain.TokenFamily{
+		ID:         familyID,
+		UserID:     userID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.refreshTTL),
+		CreatedAt:  now,
+	}
+	if err := s.repo.CreateFamily(family); err != nil {
+		return nil, err
+	}
+
+	return s.issuePair(userID, familyID)
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+func (s *TokenService) Refresh(refreshToken string, client ClientInfo) (*TokenPair, error) {
+	token, err := s.repo.FindRefreshTokenByHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if token == nil || token.Family == nil || token.Family.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if token.UsedAt != nil {
+		return nil, s.reuseDetected(token.FamilyID)
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	marked, err := s.repo.MarkRefreshTokenUsed(token.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !marked {
+		return nil, s.reuseDetected(token.FamilyID)
+	}
+
+	now := time.Now()
+	if err := s.repo.ExtendFamily(token.FamilyID, client.IP, client.UserAgent, now, now.Add(s.refreshTTL)); err != nil {
+		return nil, err
+	}
+
+	return s.issuePair(token.UserID, token.FamilyID)
+}
+
+// Revoke ends the session identified by the token family.
+func (s *TokenService) Revoke(familyID string) error {
+	return s.repo.RevokeFamily(familyID)
+}
+
+// RevokeAll ends every session of the user, e.g. after a password change.
+func (s *TokenService) RevokeAll(userID uint) error {
+	return s.repo.RevokeUserFamilies(userID)
+}
+
+// Sessions lists the sessions of the user that have not ended.
+func (s *TokenService) Sessions(userID uint) ([]*domain.TokenFamily, error) {
+	return s.repo.FindActiveFamilies(userID, time.Now())
+}
+
+// RevokeSession ends one of the user's sessions.
+func (s *TokenService) RevokeSession(userID uint, familyID string) error {
+	family, err := s.repo.FindFamily(familyID)
+	if err != nil {
+		return err
+	}
+
+	if family == nil || family.UserID != userID || family.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	return s.repo.RevokeFamily(familyID)
+}
+
+// RevokeOtherSessions logs the user out everywhere except the current session.
+func (s *TokenService) RevokeOtherSessions(userID uint, currentFamilyID string) error {
+	return s.repo.RevokeOtherFamilies(userID, currentFamilyID)
+}
+
+// LoggedInSince reports whether the session of the user was started by
+// logging in at or after since. Refreshing does not renew a session.
+func (s *TokenService) LoggedInSince(userID uint, familyID string, since time.Time) (bool, error) {
+	family, err := s.repo.FindFamily(familyID)
+	if err != nil {
+		return false, err
+	}
+
+	if family == nil || family.UserID != userID || family.RevokedAt != nil {
+		return false, nil
+	}
+
+	return !family.CreatedAt.Before(since), nil
+}
+
+// IsRevoked reports whether access tokens of the family must be rejected.
+// As it runs on every authenticated request, it also keeps the last-seen
+// time of the session current.
+func (s *TokenService) IsRevoked(familyID string) (bool, error) {
+	family, err := s.repo.FindFamily(familyID)
+	if err != nil {
+		return false, err
+	}
+
+	if family == nil || family.RevokedAt != nil {
+		return true, nil
+	}
+
+	if now := time.Now(); now.Sub(family.LastSeenAt) > sessionTouchInterval {
+		if err := s.repo.TouchFamily(familyID, now); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+func (s *TokenService) reuseDetected(familyID string) error {
+	if err := s.repo.RevokeFamily(familyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func (s *TokenService) issuePair(userID uint, familyID string) (*TokenPair, error) {
+	now := time.Now()
+
+	jti, err := randomToken(familyIDBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := s.jwtManager.Issue(userID, familyID, jti)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken(refreshTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.CreateRefreshToken(&domain.RefreshToken{
+		FamilyID:  familyID,
+		UserID:    userID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"wishlist/internal/auth"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenService_Sessions(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	jwtManager := auth.NewJWTManager("test-secret", time.Hour)
+	tokenService := NewTokenService(repository.NewTokenRepository(db), jwtManager, DefaultRefreshTokenTTL)
+	userService := NewUserService(userRepo, nil)
+
+	user, err := userService.Register("traveller@example.com", "password123")
+	require.NoError(t, err)
+	other, err := userService.Register("other@example.com", "password123")
+	require.NoError(t, err)
+
+	laptop := ClientInfo{IP: "10.0.0.1", UserAgent: "Laptop"}
+	phone := ClientInfo{IP: "10.0.0.2", UserAgent: "Phone"}
+
+	sessionOf := func(pair *TokenPair) string {
+		claims, err := jwtManager.Parse(pair.AccessToken)
+		require.NoError(t, err)
+		return claims.SessionID
+	}
+
+	laptopPair, err := tokenService.Issue(user.ID, laptop)
+	require.NoError(t, err)
+	phonePair, err := tokenService.Issue(user.ID, phone)
+	require.NoError(t, err)
+	tabletPair, err := tokenService.Issue(user.ID, ClientInfo{UserAgent: "Tablet"})
+	require.NoError(t, err)
+
+	t.Run("list sessions with their device", func(t *testing.T) {
+		sessions, err := tokenService.Sessions(user.ID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 3)
+
+		agents := make(map[string]string)
+		for _, session := range sessions {
+			agents[session.UserAgent] = session.IP
+		}
+		assert.Equal(t, "10.0.0.1", agents["Laptop"])
+		assert.Equal(t, "10.0.0.2", agents["Phone"])
+	})
+
+	t.Run("refresh records the client", func(t *testing.T) {
+		pair, err := tokenService.Refresh(phonePair.RefreshToken, ClientInfo{IP: "10.0.0.9", UserAgent: "Phone"})
+		require.NoError(t, err)
+		phonePair = pair
+
+		sessions, err := tokenService.Sessions(user.ID)
+		require.NoError(t, err)
+		for _, session := range sessions {
+			if session.ID == sessionOf(phonePair) {
+				assert.Equal(t, "10.0.0.9", session.IP)
+			}
+		}
+	})
+
+	t.Run("users cannot revoke sessions of others", func(t *testing.T) {
+		err := tokenService.RevokeSession(other.ID, sessionOf(laptopPair))
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("revoke one session", func(t *testing.T) {
+		require.NoError(t, tokenService.RevokeSession(user.ID, sessionOf(tabletPair)))
+
+		revoked, err := tokenService.IsRevoked(sessionOf(tabletPair))
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		assert.ErrorIs(t, tokenService.RevokeSession(user.ID, sessionOf(tabletPair)), ErrSessionNotFound)
+	})
+
+	t.Run("log out everywhere else", func(t *testing.T) {
+		require.NoError(t, tokenService.RevokeOtherSessions(user.ID, sessionOf(laptopPair)))
+
+		sessions, err := tokenService.Sessions(user.ID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, sessionOf(laptopPair), sessions[0].ID)
+
+		_, err = tokenService.Refresh(phonePair.RefreshToken, phone)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+}
@@ -9,13 +9,31 @@ import (
 	"wishlist/internal/repository"
 )
 
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// UserRepository is the subset of user storage needed by services other
+// than UserService.
+type UserRepository interface {
+	Create(user *domain.User) error
+	FindByID(id uint) (*domain.User, error)
+	FindByEmail(email string) (*domain.User, error)
+	Update(user *domain.User) error
+}
+
 type UserService struct {
 	userRepo *repository.UserRepository
+	reauth   *Reauthenticator
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+// NewUserService creates the service. reauth may be nil, in which case only
+// the password confirms sensitive requests.
+func NewUserService(userRepo *repository.UserRepository, reauth *Reauthenticator) *UserService {
 	return &UserService{
 		userRepo: userRepo,
+		reauth:   reauth,
 	}
 }
 
@@ -57,11 +75,11 @@ func (s *UserService) Login(email, password string) (*domain.User, error) {
 	}
 
 	if user == nil {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	return user, nil
@@ -91,7 +109,7 @@ func (s *UserService) GetByEmail(email string) (*domain.User, error) {
 	}
 
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 
 	return user, nil
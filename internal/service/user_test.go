@@ -15,7 +15,7 @@ func TestUserService_Register(t *testing.T) {
 	defer testutil.CleanupDB(t, db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil)
 
 	t.Run("successful registration", func(t *testing.T) {
 		user, err := userService.Register("test@example.com", "password123")
@@ -42,7 +42,7 @@ func TestUserService_Login(t *testing.T) {
 	defer testutil.CleanupDB(t, db)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil)
 
 	// Register a test user
 	_, err := userService.Register("test@example.com", "password123")
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"wishlist/internal/domain"
+	"wishlist/internal/mail"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+	ErrVerificationThrottled    = errors.New("verification email was sent recently, try again later")
+	ErrEmailNotVerified         = errors.New("email address must be verified first")
+)
+
+const (
+	DefaultEmailVerificationTTL       = 48 * time.Hour
+	DefaultVerificationResendInterval = time.Minute
+
+	verificationTokenBytes = 32
+)
+
+// VerifiedAction names something only users with a verified email address
+// may do, when the deployment requires it.
+type VerifiedAction string
+
+const (
+	// VerifiedActionPublishWishList covers making a wishlist public.
+	VerifiedActionPublishWishList VerifiedAction = "publish_wishlist"
+	// VerifiedActionShareWishList covers generating share links.
+	VerifiedActionShareWishList VerifiedAction = "share_wishlist"
+	// VerifiedActionInviteCollaborator covers inviting collaborators.
+	VerifiedActionInviteCollaborator VerifiedAction = "invite_collaborator"
+)
+
+// VerificationPolicy decides which actions require a verified email address.
+// A nil policy requires nothing.
+type VerificationPolicy struct {
+	users    UserFinder
+	required map[VerifiedAction]bool
+}
+
+func NewVerificationPolicy(users UserFinder, actions []string) *VerificationPolicy {
+	required := make(map[VerifiedAction]bool, len(actions))
+	for _, action := range actions {
+		if action = strings.TrimSpace(action); action != "" {
+			required[VerifiedAction(action)] = true
+		}
+	}
+	return &VerificationPolicy{users: users, required: required}
+}
+
+// Require returns ErrEmailNotVerified if the action requires a verified email
+// address and the user has none.
+func (p *VerificationPolicy) Require(userID uint, action VerifiedAction) error {
+	if p == nil || !p.required[action] {
+		return nil
+	}
+
+	user, err := p.users.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil || !user.EmailVerified() {
+		return ErrEmailNotVerified
+	}
+
+	return nil
+}
+
+type EmailVerificationRepository interface {
+	Create(token *domain.EmailVerificationToken) error
+	FindByHash(hash string) (*domain.EmailVerificationToken, error)
+	FindLatest(userID uint) (*domain.EmailVerificationToken, error)
+	MarkUsed(id uint) (bool, error)
+	InvalidateForUser(userID uint) error
+}
+
+// EmailVerificationService confirms that users own their email address by
+// sending them a single-use link.
+type EmailVerificationService struct {
+	tokens         EmailVerificationRepository
+	users          UserRepository
+	mailer         mail.Mailer
+	appURL         string
+	ttl            time.Duration
+	resendInterval time.Duration
+}
+
+func NewEmailVerificationService(
+	tokens EmailVerificationRepository,
+	users UserRepository,
+	mailer mail.Mailer,
+	appURL string,
+	ttl time.Duration,
+	resendInterval time.Duration,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		tokens:         tokens,
+		users:          users,
+		mailer:         mailer,
+		appURL:         appURL,
+		ttl:            ttl,
+		resendInterval: resendInterval,
+	}
+}
+
+// SendVerification emails a verification link for the current address of the
+// user. Earlier links stop working.
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *domain.User) error {
+	return s.send(ctx, user, user.Email, "Confirm your Wishlist email address",
+		"Welcome to Wishlist!\n\nOpen the link below to confirm your email address. It is valid for %s:\n\n%s\n\n"+
+			"If you did not create an account, ignore this email.\n")
+}
+
+// SendEmailChange emails a confirmation link to the pending address of the
+// user. Following it makes the pending address the account's email.
+func (s *EmailVerificationService) SendEmailChange(ctx context.Context, user *domain.User) error {
+	if user.PendingEmail == nil {
+		return nil
+	}
+
+	return s.send(ctx, user, *user.PendingEmail, "Confirm your new Wishlist email address",
+		"Open the link below to use this address for your Wishlist account. It is valid for %s:\n\n%s\n\n"+
+			"If you did not ask for this change, ignore this email.\n")
+}
+
+// send emails a verification link for address. body receives the validity
+// and the link.
+func (s *EmailVerificationService) send(ctx context.Context, user *domain.User, address, subject, body string) error {
+	if err := s.tokens.InvalidateForUser(user.ID); err != nil {
+		return err
+	}
+
+	token, err := randomToken(verificationTokenBytes)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.tokens.Create(&domain.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     address,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.appURL, url.QueryEscape(token))
+	return s.mailer.Send(ctx, mail.Message{
+		To:      address,
+		Subject: subject,
+		Body:    fmt.Sprintf(body, s.ttl, link),
+	})
+}
+
+// Resend sends a new verification link, at most once per resend interval.
+// While an email change is pending the link goes to the new address.
+func (s *EmailVerificationService) Resend(ctx context.Context, userID uint) error {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if user.PendingEmail == nil && user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	latest, err := s.tokens.FindLatest(user.ID)
+	if err != nil {
+		return err
+	}
+
+	if latest != nil && time.Since(latest.CreatedAt) < s.resendInterval {
+		return ErrVerificationThrottled
+	}
+
+	if user.PendingEmail != nil {
+		return s.SendEmailChange(ctx, user)
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// Verify marks the email address the token was sent to as verified. For a
+// pending email change it also switches the account to the new address.
+func (s *EmailVerificationService) Verify(token string) (*domain.User, error) {
+	verification, err := s.tokens.FindByHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if verification == nil || verification.UsedAt != nil || time.Now().After(verification.ExpiresAt) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.users.FindByID(verification.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	// The link only proves ownership of the address it was sent to
+	changing := user.PendingEmail != nil && *user.PendingEmail == verification.Email
+	if user.Email != verification.Email && !changing {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	if changing {
+		existing, err := s.users.FindByEmail(verification.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		// Someone registered the address while the change was pending
+		if existing != nil && existing.ID != user.ID {
+			return nil, ErrEmailTaken
+		}
+	}
+
+	marked, err := s.tokens.MarkUsed(verification.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !marked {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	now := time.Now()
+	if changing {
+		user.Email = verification.Email
+		user.PendingEmail = nil
+	}
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"wishlist/internal/domain"
+	"wishlist/internal/mail"
+	"wishlist/internal/repository"
+	"wishlist/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var verifyLinkPattern = regexp.MustCompile(`verify-email\?token=(\S+)`)
+
+func verificationTokenFrom(t *testing.T, mailer *mail.MemoryMailer, to string) string {
+	t.Helper()
+	msg, ok := mailer.Last(to)
+	require.True(t, ok, "no email sent to %s", to)
+	match := verifyLinkPattern.FindStringSubmatch(msg.Body)
+	require.Len(t, match, 2)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+func TestEmailVerificationService(t *testing.T) {
+	db := testutil.TestDB(t)
+	defer testutil.CleanupDB(t, db)
+
+	userRepo := repository.NewUserRepository(db)
+	mailer := mail.NewMemoryMailer()
+	verificationService := NewEmailVerificationService(repository.NewEmailVerificationRepository(db), userRepo,
+		mailer, "https://wishlist.example.com", DefaultEmailVerificationTTL, time.Hour)
+	policy := NewAccessPolicy(repository.NewCollaboratorRepository(db),
+		NewVerificationPolicy(userRepo, []string{string(VerifiedActionPublishWishList)}))
+	wishListService := NewWishListService(repository.NewWishListRepository(db), policy)
+	userService := NewUserService(userRepo, nil)
+	ctx := context.Background()
+
+	user, err := userService.Register("new@example.com", "password123")
+	require.NoError(t, err)
+	require.NoError(t, verificationService.SendVerification(ctx, user))
+
+	wishList := &domain.WishList{UserID: user.ID, Name: "Birthday", Status: "active"}
+	require.NoError(t, wishListService.Create(wishList))
+
+	t.Run("unverified user cannot publish", func(t *testing.T) {
+		_, err := wishListService.UpdateShareSettings(wishList.ID, user.ID, true, nil)
+		assert.ErrorIs(t, err, ErrEmailNotVerified)
+	})
+
+	t.Run("resend is throttled", func(t *testing.T) {
+		assert.ErrorIs(t, verificationService.Resend(ctx, user.ID), ErrVerificationThrottled)
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		token := verificationTokenFrom(t, mailer, user.Email)
+
+		verified, err := verificationService.Verify(token)
+		require.NoError(t, err)
+		assert.True(t, verified.EmailVerified())
+
+		_, err = verificationService.Verify(token)
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+
+		assert.ErrorIs(t, verificationService.Resend(ctx, user.ID), ErrEmailAlreadyVerified)
+	})
+
+	t.Run("verified user can publish", func(t *testing.T) {
+		updated, err := wishListService.UpdateShareSettings(wishList.ID, user.ID, true, nil)
+		require.NoError(t, err)
+		assert.True(t, updated.IsPublic)
+	})
+
+	t.Run("link for a previous address is rejected", func(t *testing.T) {
+		other, err := userService.Register("old@example.com", "password123")
+		require.NoError(t, err)
+		require.NoError(t, verificationService.SendVerification(ctx, other))
+		token := verificationTokenFrom(t, mailer, other.Email)
+
+		other.Email = "changed@example.com"
+		require.NoError(t, userRepo.Update(other))
+
+		_, err = verificationService.Verify(token)
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+	})
+}
@@ -2,31 +2,60 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"time"
 	"wishlist/internal/domain"
 )
 
+var (
+	ErrAccessDenied     = errors.New("access denied")
+	ErrWishListNotFound = errors.New("wishlist not found")
+	ErrInvalidItemOrder = errors.New("invalid item order")
+)
+
+// shareCodeBytes is the amount of randomness behind a share code; 16 bytes
+// make codes practically impossible to enumerate.
+const shareCodeBytes = 16
+
 type WishListService struct {
-	repo WishListRepository
+	repo   WishListRepository
+	policy *AccessPolicy
 }
 
 type WishListRepository interface {
 	Create(wishlist *domain.WishList) error
 	FindByID(id uint) (*domain.WishList, error)
+	FindByIDWithItems(id uint) (*domain.WishList, error)
 	FindByUserID(userID uint) ([]*domain.WishList, error)
+	FindAccessibleByUserID(userID uint, query domain.WishListQuery) (*domain.WishListPage, error)
+	FindByShareCode(code string) (*domain.WishList, error)
 	Update(wishlist *domain.WishList) error
 	Delete(id uint) error
 	AddItem(item *domain.WishItem) error
-	UpdateItem(item *domain.WishItem) error
+	UpdateItem(item *domain.WishItem) (bool, error)
 	DeleteItem(wishlistID, itemID uint) error
 	GetItem(wishlistID, itemID uint) (*domain.WishItem, error)
+	FindItems(wishlistID uint, query domain.ItemQuery) (*domain.ItemPage, error)
+	ReorderItems(wishlistID uint, plan func(items []*domain.WishItem) (map[uint]int64, error)) ([]*domain.WishItem, error)
 }
 
-func NewWishListService(repo WishListRepository) *WishListService {
-	return &WishListService{repo: repo}
+func NewWishListService(repo WishListRepository, policy *AccessPolicy) *WishListService {
+	return &WishListService{repo: repo, policy: policy}
 }
 
 func (s *WishListService) Create(wishlist *domain.WishList) error {
+	if err := normalizeEvent(wishlist); err != nil {
+		return err
+	}
+	if wishlist.Status == "" {
+		wishlist.Status = domain.WishListActive
+	}
+	if wishlist.IsPublic {
+		if err := s.policy.RequireVerified(wishlist.UserID, VerifiedActionPublishWishList); err != nil {
+			return err
+		}
+	}
+
 	now := time.Now()
 	wishlist.CreatedAt = now
 	wishlist.UpdatedAt = now
@@ -34,20 +63,30 @@ func (s *WishListService) Create(wishlist *domain.WishList) error {
 }
 
 func (s *WishListService) GetByID(id uint, userID uint) (*domain.WishList, error) {
-	wishlist, err := s.repo.FindByID(id)
+	wishlist, err := s.repo.FindByIDWithItems(id)
 	if err != nil {
 		return nil, err
 	}
 
-	if wishlist.UserID != userID {
-		return nil, errors.New("access denied")
+	if err := s.policy.Authorize(wishlist, userID, ActionView); err != nil {
+		return nil, err
 	}
 
+	// Members see that an item is taken, but never by whom, and nothing at
+	// all in surprise mode.
+	markReservations(wishlist, wishlist.SurpriseMode)
+
 	return wishlist, nil
 }
 
-func (s *WishListService) GetByUserID(userID uint) ([]*domain.WishList, error) {
-	return s.repo.FindByUserID(userID)
+// GetByUserID returns a page of the wishlists the user owns or
+// collaborates on that match the query, oldest first unless sorted
+// otherwise.
+func (s *WishListService) GetByUserID(userID uint, query domain.WishListQuery) (*domain.WishListPage, error) {
+	if err := normalizePage(&query.Page, domain.WishListSorts, domain.SortCreated); err != nil {
+		return nil, err
+	}
+	return s.repo.FindAccessibleByUserID(userID, query)
 }
 
 func (s *WishListService) Update(wishlist *domain.WishList, userID uint) error {
@@ -56,11 +95,35 @@ func (s *WishListService) Update(wishlist *domain.WishList, userID uint) error {
 		return err
 	}
 
-	if existing.UserID != userID {
-		return errors.New("access denied")
+	if err := s.policy.Authorize(existing, userID, ActionEdit); err != nil {
+		return err
 	}
 
-	wishlist.UserID = userID // Ensure UserID is set correctly
+	if err := normalizeEvent(wishlist); err != nil {
+		return err
+	}
+
+	// Ownership, share codes and the creation time cannot be changed
+	// through a regular update
+	wishlist.UserID = existing.UserID
+	wishlist.ShareCode = existing.ShareCode
+	wishlist.CreatedAt = existing.CreatedAt
+	if wishlist.Status == "" {
+		wishlist.Status = existing.Status
+	}
+	// Only those who manage the list decide how it is shared
+	if err := s.policy.Authorize(existing, userID, ActionManage); err != nil {
+		if !errors.Is(err, ErrAccessDenied) {
+			return err
+		}
+		wishlist.IsPublic = existing.IsPublic
+		wishlist.SurpriseMode = existing.SurpriseMode
+	}
+	if wishlist.IsPublic && !existing.IsPublic {
+		if err := s.policy.RequireVerified(userID, VerifiedActionPublishWishList); err != nil {
+			return err
+		}
+	}
 	wishlist.UpdatedAt = time.Now()
 	return s.repo.Update(wishlist)
 }
@@ -71,8 +134,8 @@ func (s *WishListService) Delete(id uint, userID uint) error {
 		return err
 	}
 
-	if existing.UserID != userID {
-		return errors.New("access denied")
+	if err := s.policy.Authorize(existing, userID, ActionManage); err != nil {
+		return err
 	}
 
 	return s.repo.Delete(id)
@@ -84,8 +147,8 @@ func (s *WishListService) AddItem(item *domain.WishItem, userID uint) error {
 		return err
 	}
 
-	if wishlist.UserID != userID {
-		return errors.New("access denied")
+	if err := s.policy.Authorize(wishlist, userID, ActionEdit); err != nil {
+		return err
 	}
 
 	now := time.Now()
@@ -100,12 +163,19 @@ func (s *WishListService) UpdateItem(item *domain.WishItem, userID uint) error {
 		return err
 	}
 
-	if wishlist.UserID != userID {
-		return errors.New("access denied")
+	if err := s.policy.Authorize(wishlist, userID, ActionEdit); err != nil {
+		return err
 	}
 
 	item.UpdatedAt = time.Now()
-	return s.repo.UpdateItem(item)
+	found, err := s.repo.UpdateItem(item)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrItemNotFound
+	}
+	return nil
 }
 
 func (s *WishListService) DeleteItem(wishlistID, itemID uint, userID uint) error {
@@ -114,8 +184,8 @@ func (s *WishListService) DeleteItem(wishlistID, itemID uint, userID uint) error
 		return err
 	}
 
-	if wishlist.UserID != userID {
-		return errors.New("access denied")
+	if err := s.policy.Authorize(wishlist, userID, ActionEdit); err != nil {
+		return err
 	}
 
 	return s.repo.DeleteItem(wishlistID, itemID)
@@ -127,9 +197,245 @@ func (s *WishListService) GetItem(wishlistID, itemID uint, userID uint) (*domain
 		return nil, err
 	}
 
-	if wishlist.UserID != userID {
-		return nil, errors.New("access denied")
+	if err := s.policy.Authorize(wishlist, userID, ActionView); err != nil {
+		return nil, err
 	}
 
 	return s.repo.GetItem(wishlistID, itemID)
-} 
\ No newline at end of file
+}
+
+// ListItems returns a page of the items of the wishlist that match the
+// query, in position order unless sorted otherwise.
+func (s *WishListService) ListItems(wishlistID uint, query domain.ItemQuery, userID uint) (*domain.ItemPage, error) {
+	wishlist, err := s.repo.FindByID(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionView); err != nil {
+		return nil, err
+	}
+
+	if err := normalizePage(&query.Page, domain.ItemSorts, domain.SortPosition); err != nil {
+		return nil, err
+	}
+
+	page, err := s.repo.FindItems(wishlistID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range page.Items {
+		item.Reserved = !wishlist.SurpriseMode && item.Reservation != nil
+	}
+	return page, nil
+}
+
+// ReorderItems moves the given items, in the given order, right after the
+// item afterID, or to the top of the list when afterID is 0. Sending every
+// item of the list sets its complete order. It returns the items in their
+// new order.
+func (s *WishListService) ReorderItems(wishlistID uint, itemIDs []uint, afterID uint, userID uint) ([]*domain.WishItem, error) {
+	wishlist, err := s.repo.FindByID(wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionEdit); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReorderItems(wishlistID, func(items []*domain.WishItem) (map[uint]int64, error) {
+		return planItemOrder(items, itemIDs, afterID)
+	})
+}
+
+// planItemOrder returns the new positions for moving the items with the
+// given IDs after afterID. items must be sorted by position. Usually only
+// the moved items get a position, spread evenly in the gap they are moved
+// into; when that gap is too small, the whole list is renumbered.
+func planItemOrder(items []*domain.WishItem, moved []uint, afterID uint) (map[uint]int64, error) {
+	if len(moved) == 0 {
+		return nil, fmt.Errorf("%w: no items given", ErrInvalidItemOrder)
+	}
+
+	inList := make(map[uint]bool, len(items))
+	for _, item := range items {
+		inList[item.ID] = true
+	}
+	isMoved := make(map[uint]bool, len(moved))
+	for _, id := range moved {
+		if !inList[id] {
+			return nil, fmt.Errorf("%w: item %d is not on the list", ErrInvalidItemOrder, id)
+		}
+		if isMoved[id] {
+			return nil, fmt.Errorf("%w: item %d is listed twice", ErrInvalidItemOrder, id)
+		}
+		isMoved[id] = true
+	}
+	if afterID != 0 && (!inList[afterID] || isMoved[afterID]) {
+		return nil, fmt.Errorf("%w: after_id must be an item on the list that is not moved", ErrInvalidItemOrder)
+	}
+
+	// The items that stay, and the index among them where the moved ones go
+	var rest []*domain.WishItem
+	insertAt := 0
+	for _, item := range items {
+		if isMoved[item.ID] {
+			continue
+		}
+		rest = append(rest, item)
+		if item.ID == afterID {
+			insertAt = len(rest)
+		}
+	}
+
+	slots := int64(len(moved) + 1)
+	var lower, upper int64
+	switch {
+	case len(rest) == 0:
+		lower, upper = 0, slots*domain.ItemPositionGap
+	case insertAt == 0:
+		upper = rest[0].Position
+		lower = upper - slots*domain.ItemPositionGap
+	case insertAt == len(rest):
+		lower = rest[len(rest)-1].Position
+		upper = lower + slots*domain.ItemPositionGap
+	default:
+		lower, upper = rest[insertAt-1].Position, rest[insertAt].Position
+	}
+
+	positions := make(map[uint]int64, len(moved))
+	if step := (upper - lower) / slots; step > 0 {
+		for i, id := range moved {
+			positions[id] = lower + step*int64(i+1)
+		}
+		return positions, nil
+	}
+
+	// No room left between the neighbours: renumber the whole list
+	order := make([]uint, 0, len(items))
+	for _, item := range rest[:insertAt] {
+		order = append(order, item.ID)
+	}
+	order = append(order, moved...)
+	for _, item := range rest[insertAt:] {
+		order = append(order, item.ID)
+	}
+	current := make(map[uint]int64, len(items))
+	for _, item := range items {
+		current[item.ID] = item.Position
+	}
+	for i, id := range order {
+		if position := int64(i+1) * domain.ItemPositionGap; current[id] != position {
+			positions[id] = position
+		}
+	}
+	return positions, nil
+}
+
+// GenerateShareCode creates a new share code for the wishlist, invalidating
+// any previously issued one.
+func (s *WishListService) GenerateShareCode(id uint, userID uint) (string, error) {
+	wishlist, err := s.repo.FindByID(id)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionManage); err != nil {
+		return "", err
+	}
+
+	if err := s.policy.RequireVerified(userID, VerifiedActionShareWishList); err != nil {
+		return "", err
+	}
+
+	code, err := randomToken(shareCodeBytes)
+	if err != nil {
+		return "", err
+	}
+
+	wishlist.ShareCode = &code
+	wishlist.UpdatedAt = time.Now()
+	if err := s.repo.Update(wishlist); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// RevokeShareCode removes the share code so that previously shared links stop working.
+func (s *WishListService) RevokeShareCode(id uint, userID uint) error {
+	wishlist, err := s.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionManage); err != nil {
+		return err
+	}
+
+	wishlist.ShareCode = nil
+	wishlist.UpdatedAt = time.Now()
+	return s.repo.Update(wishlist)
+}
+
+// UpdateShareSettings changes the visibility of the wishlist. A nil
+// surpriseMode leaves the current setting untouched.
+func (s *WishListService) UpdateShareSettings(id uint, userID uint, isPublic bool, surpriseMode *bool) (*domain.WishList, error) {
+	wishlist, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.Authorize(wishlist, userID, ActionManage); err != nil {
+		return nil, err
+	}
+
+	if isPublic && !wishlist.IsPublic {
+		if err := s.policy.RequireVerified(userID, VerifiedActionPublishWishList); err != nil {
+			return nil, err
+		}
+	}
+
+	wishlist.IsPublic = isPublic
+	if surpriseMode != nil {
+		wishlist.SurpriseMode = *surpriseMode
+	}
+	wishlist.UpdatedAt = time.Now()
+	if err := s.repo.Update(wishlist); err != nil {
+		return nil, err
+	}
+
+	return wishlist, nil
+}
+
+// GetByShareCode returns a public wishlist by its share code. Private lists
+// are reported as not found so that a code does not reveal their existence.
+// userID is the logged-in viewer, or 0; members of a list in surprise mode
+// do not see reservations through the share link either.
+func (s *WishListService) GetByShareCode(code string, userID uint) (*domain.WishList, error) {
+	wishlist, err := s.repo.FindByShareCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if wishlist == nil || !wishlist.IsPublic {
+		return nil, ErrWishListNotFound
+	}
+
+	hide, err := s.policy.HidesReservations(wishlist, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	markReservations(wishlist, hide)
+
+	return wishlist, nil
+}
+
+func markReservations(wishlist *domain.WishList, hide bool) {
+	for i := range wishlist.Items {
+		wishlist.Items[i].Reserved = !hide && wishlist.Items[i].Reservation != nil
+	}
+}
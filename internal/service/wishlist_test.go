@@ -18,8 +18,8 @@ func TestWishListService(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	wishListRepo := repository.NewWishListRepository(db)
 
-	userService := NewUserService(userRepo)
-	wishListService := NewWishListService(wishListRepo)
+	userService := NewUserService(userRepo, nil)
+	wishListService := NewWishListService(wishListRepo, NewAccessPolicy(repository.NewCollaboratorRepository(db), nil))
 
 	// Create a test user
 	user, err := userService.Register("test@example.com", "password123")
@@ -81,9 +81,9 @@ func TestWishListService(t *testing.T) {
 		err = wishListService.Create(wishList2)
 		require.NoError(t, err)
 
-		wishLists, err := wishListService.GetByUserID(user.ID)
+		page, err := wishListService.GetByUserID(user.ID, domain.WishListQuery{})
 		require.NoError(t, err)
-		assert.GreaterOrEqual(t, len(wishLists), 2)
+		assert.GreaterOrEqual(t, len(page.WishLists), 2)
 	})
 
 	t.Run("update wishlist", func(t *testing.T) {
@@ -158,4 +158,218 @@ func TestWishListService(t *testing.T) {
 		assert.Equal(t, "Description", item.Description)
 		assert.Equal(t, wishList.ID, item.WishListID)
 	})
+
+	t.Run("update item through another wishlist", func(t *testing.T) {
+		listA := &domain.WishList{UserID: user.ID, Name: "A", Status: "active"}
+		require.NoError(t, wishListService.Create(listA))
+		listB := &domain.WishList{UserID: user.ID, Name: "B", Status: "active"}
+		require.NoError(t, wishListService.Create(listB))
+		item := &domain.WishItem{WishListID: listB.ID, Name: "Lamp", Status: "wanted"}
+		require.NoError(t, wishListService.AddItem(item, user.ID))
+
+		moved := &domain.WishItem{ID: item.ID, WishListID: listA.ID, Name: "Stolen", Status: "wanted"}
+		assert.ErrorIs(t, wishListService.UpdateItem(moved, user.ID), ErrItemNotFound)
+
+		stored, err := wishListService.GetItem(listB.ID, item.ID, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Lamp", stored.Name)
+
+		missing := &domain.WishItem{ID: 999999, WishListID: listA.ID, Name: "Ghost", Status: "wanted"}
+		assert.ErrorIs(t, wishListService.UpdateItem(missing, user.ID), ErrItemNotFound)
+		_, err = wishListService.GetItem(listA.ID, 999999, user.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("update wishlist ignores items in the body", func(t *testing.T) {
+		other, err := userService.Register("victim@example.com", "password123")
+		require.NoError(t, err)
+		foreign := &domain.WishList{UserID: other.ID, Name: "Theirs", Status: "active"}
+		require.NoError(t, wishListService.Create(foreign))
+		item := &domain.WishItem{WishListID: foreign.ID, Name: "Camera", Status: "wanted"}
+		require.NoError(t, wishListService.AddItem(item, other.ID))
+
+		own := &domain.WishList{UserID: user.ID, Name: "Mine", Status: domain.WishListArchived}
+		require.NoError(t, wishListService.Create(own))
+		created, err := wishListService.GetByID(own.ID, user.ID)
+		require.NoError(t, err)
+
+		update := &domain.WishList{
+			ID:    own.ID,
+			Name:  "Mine, renamed",
+			Items: []domain.WishItem{{ID: item.ID, WishListID: own.ID, Name: "Taken"}},
+		}
+		require.NoError(t, wishListService.Update(update, user.ID))
+
+		stored, err := wishListService.GetItem(foreign.ID, item.ID, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, foreign.ID, stored.WishListID)
+		assert.Equal(t, "Camera", stored.Name)
+
+		updated, err := wishListService.GetByID(own.ID, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Mine, renamed", updated.Name)
+		assert.Empty(t, updated.Items)
+		assert.Equal(t, domain.WishListArchived, updated.Status)
+		assert.True(t, created.CreatedAt.Equal(updated.CreatedAt))
+	})
+
+	t.Run("share wishlist", func(t *testing.T) {
+		wishList := &domain.WishList{
+			UserID: user.ID,
+			Name:   "Shared Wishlist",
+			Status: "active",
+		}
+
+		err := wishListService.Create(wishList)
+		require.NoError(t, err)
+
+		code, err := wishListService.GenerateShareCode(wishList.ID, user.ID)
+		require.NoError(t, err)
+		assert.NotEmpty(t, code)
+
+		// Private lists are not reachable by share code
+		_, err = wishListService.GetByShareCode(code, 0)
+		assert.ErrorIs(t, err, ErrWishListNotFound)
+
+		_, err = wishListService.UpdateShareSettings(wishList.ID, user.ID, true, nil)
+		require.NoError(t, err)
+
+		shared, err := wishListService.GetByShareCode(code, 0)
+		require.NoError(t, err)
+		assert.Equal(t, wishList.ID, shared.ID)
+
+		// Regenerating invalidates the old code
+		newCode, err := wishListService.GenerateShareCode(wishList.ID, user.ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, code, newCode)
+
+		_, err = wishListService.GetByShareCode(code, 0)
+		assert.ErrorIs(t, err, ErrWishListNotFound)
+
+		err = wishListService.RevokeShareCode(wishList.ID, user.ID)
+		require.NoError(t, err)
+
+		_, err = wishListService.GetByShareCode(newCode, 0)
+		assert.ErrorIs(t, err, ErrWishListNotFound)
+	})
+
+	t.Run("share wishlist of another user", func(t *testing.T) {
+		other, err := userService.Register("other@example.com", "password123")
+		require.NoError(t, err)
+
+		wishList := &domain.WishList{
+			UserID: user.ID,
+			Name:   "Not Yours",
+			Status: "active",
+		}
+
+		err = wishListService.Create(wishList)
+		require.NoError(t, err)
+
+		_, err = wishListService.GenerateShareCode(wishList.ID, other.ID)
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+
+	t.Run("reorder items", func(t *testing.T) {
+		wishList := &domain.WishList{UserID: user.ID, Name: "Ordered", Status: "active"}
+		require.NoError(t, wishListService.Create(wishList))
+
+		var ids []uint
+		for _, name := range []string{"A", "B", "C", "D"} {
+			item := &domain.WishItem{WishListID: wishList.ID, Name: name, Quantity: 1}
+			require.NoError(t, wishListService.AddItem(item, user.ID))
+			ids = append(ids, item.ID)
+		}
+
+		names := func() []string {
+			found, err := wishListService.GetByID(wishList.ID, user.ID)
+			require.NoError(t, err)
+			var names []string
+			for _, item := range found.Items {
+				names = append(names, item.Name)
+			}
+			return names
+		}
+		assert.Equal(t, []string{"A", "B", "C", "D"}, names())
+
+		// Move D between A and B
+		_, err := wishListService.ReorderItems(wishList.ID, []uint{ids[3]}, ids[0], user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A", "D", "B", "C"}, names())
+
+		// Full order
+		items, err := wishListService.ReorderItems(wishList.ID, []uint{ids[2], ids[1], ids[0], ids[3]}, 0, user.ID)
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+		assert.Equal(t, ids[2], items[0].ID)
+		assert.Equal(t, []string{"C", "B", "A", "D"}, names())
+
+		// Updating an item keeps its place
+		item, err := wishListService.GetItem(wishList.ID, ids[2], user.ID)
+		require.NoError(t, err)
+		item.Position = 0
+		item.Name = "C2"
+		require.NoError(t, wishListService.UpdateItem(item, user.ID))
+		assert.Equal(t, []string{"C2", "B", "A", "D"}, names())
+
+		_, err = wishListService.ReorderItems(wishList.ID, []uint{ids[0], 999999}, 0, user.ID)
+		assert.ErrorIs(t, err, ErrInvalidItemOrder)
+	})
+}
+
+func TestPlanItemOrder(t *testing.T) {
+	list := func(positions ...int64) []*domain.WishItem {
+		items := make([]*domain.WishItem, len(positions))
+		for i, position := range positions {
+			items[i] = &domain.WishItem{ID: uint(i + 1), Position: position}
+		}
+		return items
+	}
+	gap := domain.ItemPositionGap
+
+	t.Run("moves only the given item", func(t *testing.T) {
+		positions, err := planItemOrder(list(gap, 2*gap, 3*gap), []uint{3}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[uint]int64{3: gap + gap/2}, positions)
+	})
+
+	t.Run("to the top and to the end", func(t *testing.T) {
+		positions, err := planItemOrder(list(gap, 2*gap, 3*gap), []uint{3}, 0)
+		require.NoError(t, err)
+		assert.Less(t, positions[3], gap)
+
+		positions, err = planItemOrder(list(gap, 2*gap, 3*gap), []uint{1}, 3)
+		require.NoError(t, err)
+		assert.Greater(t, positions[1], 3*gap)
+	})
+
+	t.Run("several items keep their order", func(t *testing.T) {
+		positions, err := planItemOrder(list(gap, 2*gap, 3*gap, 4*gap), []uint{4, 3}, 1)
+		require.NoError(t, err)
+		assert.Len(t, positions, 2)
+		assert.True(t, gap < positions[4] && positions[4] < positions[3] && positions[3] < 2*gap)
+	})
+
+	t.Run("renumbers when the gap is used up", func(t *testing.T) {
+		positions, err := planItemOrder(list(1, 2, 3), []uint{3}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[uint]int64{1: gap, 3: 2 * gap, 2: 3 * gap}, positions)
+	})
+
+	t.Run("rejects invalid orders", func(t *testing.T) {
+		items := list(gap, 2*gap)
+		for name, tc := range map[string]struct {
+			moved []uint
+			after uint
+		}{
+			"empty":        {nil, 0},
+			"unknown item": {[]uint{7}, 0},
+			"duplicate":    {[]uint{1, 1}, 0},
+			"after moved":  {[]uint{1}, 1},
+			"after absent": {[]uint{1}, 9},
+		} {
+			_, err := planItemOrder(items, tc.moved, tc.after)
+			assert.ErrorIs(t, err, ErrInvalidItemOrder, name)
+		}
+	})
 }
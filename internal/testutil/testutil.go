@@ -26,10 +26,9 @@ func TestDB(t *testing.T) *gorm.DB {
 	require.NoError(t, err)
 
 	// Clean up and migrate
-	err = db.Migrator().DropTable(&domain.WishItem{}, &domain.WishList{}, &domain.User{})
-	require.NoError(t, err)
+	dropTables(t, db)
 
-	err = db.AutoMigrate(&domain.User{}, &domain.WishList{}, &domain.WishItem{})
+	err = db.AutoMigrate(domain.Models()...)
 	require.NoError(t, err)
 
 	return db
@@ -37,8 +36,19 @@ func TestDB(t *testing.T) *gorm.DB {
 
 // CleanupDB cleans up the test database
 func CleanupDB(t *testing.T, db *gorm.DB) {
-	err := db.Migrator().DropTable(&domain.WishItem{}, &domain.WishList{}, &domain.User{})
-	require.NoError(t, err)
+	dropTables(t, db)
+}
+
+// dropTables drops all model tables, dependent tables first
+func dropTables(t *testing.T, db *gorm.DB) {
+	// Join tables of many-to-many associations are not models of their own
+	require.NoError(t, db.Migrator().DropTable("wishlist_item_tags", "wishlist_tags"))
+
+	models := domain.Models()
+	for i := len(models) - 1; i >= 0; i-- {
+		err := db.Migrator().DropTable(models[i])
+		require.NoError(t, err)
+	}
 }
 
 func getEnvOrDefault(key, defaultValue string) string {
@@ -1,7 +1,12 @@
 package validation
 
 import (
+	"fmt"
+	"net/url"
 	"regexp"
+
+	"golang.org/x/text/currency"
+	"wishlist/internal/domain"
 	"wishlist/internal/errors"
 )
 
@@ -43,20 +48,79 @@ func ValidateWishListName(name string) error {
 }
 
 // ValidateWishItem validates a wish item
-func ValidateWishItem(title, description, url string) error {
-	if title == "" {
-		return errors.NewValidationError("title", "Title is required")
+func ValidateWishItem(item *domain.WishItem) error {
+	if item.Name == "" {
+		return errors.NewValidationError("name", "Name is required")
 	}
-	if len(title) > 200 {
-		return errors.NewValidationError("title", "Title must be less than 200 characters")
+	if len(item.Name) > 200 {
+		return errors.NewValidationError("name", "Name must be less than 200 characters")
 	}
-	if len(description) > 1000 {
+	if len(item.Description) > 1000 {
 		return errors.NewValidationError("description", "Description must be less than 1000 characters")
 	}
-	if url != "" {
-		if len(url) > 500 {
-			return errors.NewValidationError("url", "URL must be less than 500 characters")
+	if item.URL != "" {
+		if err := validateHTTPURL("url", item.URL); err != nil {
+			return err
+		}
+	}
+	if item.Price != nil {
+		if err := validatePrice(*item.Price, item.Currency); err != nil {
+			return err
+		}
+	} else if item.Currency != "" {
+		if _, err := currency.ParseISO(item.Currency); err != nil {
+			return errors.NewValidationError("currency", "Currency must be an ISO 4217 code")
+		}
+	}
+	if item.PriceAlertBelow != nil && (item.PriceAlertBelow.Negative() || item.PriceAlertBelow.IntegerDigits() > 12) {
+		return errors.NewValidationError("price_alert_below", "Price alert must be a non-negative amount")
+	}
+	if item.Quantity < 1 {
+		return errors.NewValidationError("quantity", "Quantity must be at least 1")
+	}
+	if item.ReceivedQuantity < 0 {
+		return errors.NewValidationError("received_quantity", "Received quantity must not be negative")
+	}
+	if len(item.Images) > domain.MaxItemImages {
+		return errors.NewValidationError("images", fmt.Sprintf("At most %d images are allowed", domain.MaxItemImages))
+	}
+	for _, image := range item.Images {
+		if err := validateHTTPURL("images", image.URL); err != nil {
+			return err
 		}
 	}
 	return nil
-} 
\ No newline at end of file
+}
+
+// validatePrice checks that price is a non-negative amount in an ISO 4217
+// currency, with no more decimal places than the currency uses.
+func validatePrice(price domain.Decimal, code string) error {
+	if code == "" {
+		return errors.NewValidationError("currency", "Currency is required when a price is set")
+	}
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return errors.NewValidationError("currency", "Currency must be an ISO 4217 code")
+	}
+	if price.Negative() {
+		return errors.NewValidationError("price", "Price must not be negative")
+	}
+	if price.IntegerDigits() > 12 {
+		return errors.NewValidationError("price", "Price is too large")
+	}
+	if scale, _ := currency.Standard.Rounding(unit); price.Scale() > scale {
+		return errors.NewValidationError("price", fmt.Sprintf("Price must have at most %d decimal places in %s", scale, unit))
+	}
+	return nil
+}
+
+func validateHTTPURL(field, raw string) error {
+	if len(raw) > 500 {
+		return errors.NewValidationError(field, "URL must be less than 500 characters")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.NewValidationError(field, "URL must be an http or https URL")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"testing"
+
+	"wishlist/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWishItem(t *testing.T) {
+	price := func(s string) *domain.Decimal {
+		d, err := domain.ParseDecimal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &d
+	}
+
+	valid := func() *domain.WishItem {
+		return &domain.WishItem{
+			Name:     "Coffee grinder",
+			URL:      "https://shop.example.com/grinder",
+			Price:    price("89.99"),
+			Currency: "EUR",
+			Quantity: 1,
+			Images:   []domain.ItemImage{{URL: "https://shop.example.com/grinder.jpg"}},
+		}
+	}
+
+	assert.NoError(t, ValidateWishItem(valid()))
+
+	tests := map[string]func(item *domain.WishItem){
+		"missing name":         func(item *domain.WishItem) { item.Name = "" },
+		"relative url":         func(item *domain.WishItem) { item.URL = "/grinder" },
+		"unsupported scheme":   func(item *domain.WishItem) { item.URL = "ftp://shop.example.com/grinder" },
+		"price needs currency": func(item *domain.WishItem) { item.Currency = "" },
+		"unknown currency":     func(item *domain.WishItem) { item.Currency = "ABC" },
+		"negative price":       func(item *domain.WishItem) { item.Price = price("-1") },
+		"too many decimals":    func(item *domain.WishItem) { item.Price = price("89.999") },
+		"negative alert":       func(item *domain.WishItem) { item.PriceAlertBelow = price("-5") },
+		"zero quantity":        func(item *domain.WishItem) { item.Quantity = 0 },
+		"negative received":    func(item *domain.WishItem) { item.ReceivedQuantity = -1 },
+		"invalid image url":    func(item *domain.WishItem) { item.Images[0].URL = "grinder.jpg" },
+		"too many images": func(item *domain.WishItem) {
+			item.Images = make([]domain.ItemImage, domain.MaxItemImages+1)
+			for i := range item.Images {
+				item.Images[i].URL = "https://shop.example.com/grinder.jpg"
+			}
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			item := valid()
+			mutate(item)
+			assert.Error(t, ValidateWishItem(item))
+		})
+	}
+
+	t.Run("currency decimal places", func(t *testing.T) {
+		item := valid()
+		item.Price, item.Currency = price("1500"), "JPY"
+		assert.NoError(t, ValidateWishItem(item))
+		item.Price = price("1500.5")
+		assert.Error(t, ValidateWishItem(item))
+
+		item.Price, item.Currency = price("2.125"), "KWD"
+		assert.NoError(t, ValidateWishItem(item))
+	})
+
+	t.Run("currency without price", func(t *testing.T) {
+		item := valid()
+		item.Price = nil
+		assert.NoError(t, ValidateWishItem(item))
+	})
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_wishlists_share_code;
+
+ALTER TABLE wishlists DROP COLUMN IF EXISTS share_code;
+ALTER TABLE wishlists DROP COLUMN IF EXISTS is_public;
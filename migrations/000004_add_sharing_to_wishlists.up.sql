@@ -0,0 +1,4 @@
+ALTER TABLE wishlists ADD COLUMN is_public BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE wishlists ADD COLUMN share_code VARCHAR(64);
+
+CREATE UNIQUE INDEX idx_wishlists_share_code ON wishlists(share_code);
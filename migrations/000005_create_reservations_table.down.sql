@@ -0,0 +1,3 @@
+ALTER TABLE wishlists DROP COLUMN IF EXISTS surprise_mode;
+
+DROP TABLE IF EXISTS reservations;
@@ -0,0 +1,11 @@
+CREATE TABLE reservations (
+    id SERIAL PRIMARY KEY,
+    wish_item_id INTEGER NOT NULL UNIQUE REFERENCES wishlist_items(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    claimant_name VARCHAR(255) NOT NULL DEFAULT '',
+    claimant_email VARCHAR(255) NOT NULL DEFAULT '',
+    token_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE wishlists ADD COLUMN surprise_mode BOOLEAN NOT NULL DEFAULT FALSE;
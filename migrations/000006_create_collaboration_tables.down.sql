@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS wishlist_invitations;
+DROP TABLE IF EXISTS wishlist_collaborators;
@@ -0,0 +1,24 @@
+CREATE TABLE wishlist_collaborators (
+    id SERIAL PRIMARY KEY,
+    wish_list_id INTEGER NOT NULL REFERENCES wishlists(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_collaborators_wishlist_user ON wishlist_collaborators(wish_list_id, user_id);
+
+CREATE TABLE wishlist_invitations (
+    id SERIAL PRIMARY KEY,
+    wish_list_id INTEGER NOT NULL REFERENCES wishlists(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    invited_by_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_wishlist_invitations_wish_list_id ON wishlist_invitations(wish_list_id);
+CREATE INDEX idx_wishlist_invitations_email ON wishlist_invitations(email);
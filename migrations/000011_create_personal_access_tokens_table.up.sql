@@ -0,0 +1,17 @@
+CREATE TABLE personal_access_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    scope VARCHAR(10) NOT NULL DEFAULT 'read',
+    wish_list_id INTEGER REFERENCES wishlists(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_personal_access_tokens_token_hash ON personal_access_tokens(token_hash);
+CREATE INDEX idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
+CREATE INDEX idx_personal_access_tokens_wish_list_id ON personal_access_tokens(wish_list_id);
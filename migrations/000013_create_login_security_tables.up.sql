@@ -0,0 +1,25 @@
+CREATE TABLE security_events (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    type VARCHAR(50) NOT NULL,
+    email VARCHAR(255),
+    ip VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_security_events_user_id ON security_events(user_id);
+CREATE INDEX idx_security_events_type ON security_events(type);
+CREATE INDEX idx_security_events_email ON security_events(email);
+CREATE INDEX idx_security_events_ip ON security_events(ip);
+CREATE INDEX idx_security_events_created_at ON security_events(created_at);
+
+CREATE TABLE login_throttles (
+    throttle_key VARCHAR(300) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    unlock_token_hash VARCHAR(64)
+);
+
+CREATE UNIQUE INDEX idx_login_throttles_unlock_token_hash ON login_throttles(unlock_token_hash);
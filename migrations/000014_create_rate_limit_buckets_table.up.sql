@@ -0,0 +1,6 @@
+CREATE TABLE rate_limit_buckets (
+    bucket_key VARCHAR(300) PRIMARY KEY,
+    tat TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_tat ON rate_limit_buckets(tat);
@@ -0,0 +1,5 @@
+ALTER TABLE token_families
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS last_seen_at,
+    DROP COLUMN IF EXISTS ip,
+    DROP COLUMN IF EXISTS user_agent;
@@ -0,0 +1,14 @@
+ALTER TABLE token_families
+    ADD COLUMN user_agent TEXT NOT NULL DEFAULT '',
+    ADD COLUMN ip VARCHAR(45) NOT NULL DEFAULT '',
+    ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+
+-- A session lives as long as its newest refresh token.
+UPDATE token_families f SET
+    last_seen_at = COALESCE((SELECT MAX(created_at) FROM refresh_tokens WHERE family_id = f.id), f.created_at),
+    expires_at = COALESCE((SELECT MAX(expires_at) FROM refresh_tokens WHERE family_id = f.id), f.created_at);
+
+ALTER TABLE token_families
+    ALTER COLUMN last_seen_at SET NOT NULL,
+    ALTER COLUMN expires_at SET NOT NULL;
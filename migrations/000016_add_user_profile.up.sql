@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN pending_email VARCHAR(255),
+    ADD COLUMN display_name VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN avatar_url VARCHAR(500) NOT NULL DEFAULT '',
+    ADD COLUMN birthday DATE,
+    ADD COLUMN locale VARCHAR(16) NOT NULL DEFAULT 'en',
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_deletion_due_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_due_at;
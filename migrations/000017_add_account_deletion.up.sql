@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN deletion_due_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_deletion_due_at ON users(deletion_due_at);
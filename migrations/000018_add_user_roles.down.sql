@@ -0,0 +1,7 @@
+ALTER TABLE security_events DROP COLUMN IF EXISTS actor_id;
+
+DROP INDEX IF EXISTS idx_users_role;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS disabled_at,
+    DROP COLUMN IF EXISTS role;
@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user',
+    ADD COLUMN disabled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_role ON users(role);
+
+ALTER TABLE security_events ADD COLUMN actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS wishlist_item_images;
+
+ALTER TABLE wishlist_items
+    DROP COLUMN IF EXISTS received_quantity,
+    DROP COLUMN IF EXISTS quantity,
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS price,
+    DROP COLUMN IF EXISTS url;
@@ -0,0 +1,16 @@
+ALTER TABLE wishlist_items
+    ADD COLUMN url VARCHAR(500) NOT NULL DEFAULT '',
+    ADD COLUMN price NUMERIC(15, 3),
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT '',
+    ADD COLUMN quantity INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN received_quantity INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE wishlist_item_images (
+    id SERIAL PRIMARY KEY,
+    wish_item_id INTEGER NOT NULL REFERENCES wishlist_items(id) ON DELETE CASCADE,
+    url VARCHAR(500) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_wishlist_item_images_wish_item_id ON wishlist_item_images(wish_item_id);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS wishlist_item_prices;
+
+DROP INDEX IF EXISTS idx_wishlist_items_price_checked_at;
+
+ALTER TABLE wishlist_items
+    DROP COLUMN IF EXISTS price_checked_at,
+    DROP COLUMN IF EXISTS price_alert_reservers,
+    DROP COLUMN IF EXISTS price_alert_below;
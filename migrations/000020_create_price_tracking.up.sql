@@ -0,0 +1,16 @@
+ALTER TABLE wishlist_items
+    ADD COLUMN price_alert_below NUMERIC(15, 3),
+    ADD COLUMN price_alert_reservers BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN price_checked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_wishlist_items_price_checked_at ON wishlist_items(price_checked_at);
+
+CREATE TABLE wishlist_item_prices (
+    id SERIAL PRIMARY KEY,
+    wish_item_id INTEGER NOT NULL REFERENCES wishlist_items(id) ON DELETE CASCADE,
+    price NUMERIC(15, 3) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_wishlist_item_prices_wish_item_id ON wishlist_item_prices(wish_item_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_wishlist_items_wishlist_position;
+
+ALTER TABLE wishlist_items DROP COLUMN IF EXISTS position;
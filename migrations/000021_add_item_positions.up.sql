@@ -0,0 +1,12 @@
+ALTER TABLE wishlist_items ADD COLUMN position BIGINT NOT NULL DEFAULT 0;
+
+-- Number existing items in the order they were added
+UPDATE wishlist_items
+SET position = ranked.rank * 1024
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY wishlist_id ORDER BY id) AS rank
+    FROM wishlist_items
+) AS ranked
+WHERE wishlist_items.id = ranked.id;
+
+CREATE INDEX idx_wishlist_items_wishlist_position ON wishlist_items(wishlist_id, position);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS orphaned_blobs;
+
+ALTER TABLE wishlist_item_images
+    DROP COLUMN IF EXISTS thumbnails,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS content_type,
+    DROP COLUMN IF EXISTS blob_key;
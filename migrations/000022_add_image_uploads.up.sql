@@ -0,0 +1,12 @@
+ALTER TABLE wishlist_item_images
+    ADD COLUMN blob_key VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN content_type VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN width INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN height INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN thumbnails JSONB;
+
+CREATE TABLE orphaned_blobs (
+    id SERIAL PRIMARY KEY,
+    blob_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
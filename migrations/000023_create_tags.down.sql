@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS wishlist_item_tags;
+DROP TABLE IF EXISTS wishlist_tags;
+DROP TABLE IF EXISTS tags;
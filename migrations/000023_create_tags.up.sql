@@ -0,0 +1,26 @@
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Names are unique per user regardless of case
+CREATE UNIQUE INDEX idx_tags_user_name ON tags(user_id, LOWER(name));
+
+CREATE TABLE wishlist_tags (
+    wish_list_id INTEGER NOT NULL REFERENCES wishlists(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (wish_list_id, tag_id)
+);
+
+CREATE INDEX idx_wishlist_tags_tag_id ON wishlist_tags(tag_id);
+
+CREATE TABLE wishlist_item_tags (
+    wish_item_id INTEGER NOT NULL REFERENCES wishlist_items(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (wish_item_id, tag_id)
+);
+
+CREATE INDEX idx_wishlist_item_tags_tag_id ON wishlist_item_tags(tag_id);
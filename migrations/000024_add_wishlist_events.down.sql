@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_wishlists_event_date;
+
+ALTER TABLE wishlists
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS recurrence,
+    DROP COLUMN IF EXISTS event_date,
+    DROP COLUMN IF EXISTS occasion;
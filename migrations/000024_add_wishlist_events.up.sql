@@ -0,0 +1,7 @@
+ALTER TABLE wishlists
+    ADD COLUMN occasion VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN event_date DATE,
+    ADD COLUMN recurrence VARCHAR(10) NOT NULL DEFAULT '',
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+CREATE INDEX idx_wishlists_event_date ON wishlists(event_date);
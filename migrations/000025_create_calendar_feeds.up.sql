@@ -0,0 +1,12 @@
+CREATE TABLE calendar_feeds (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    content_hash VARCHAR(64) NOT NULL DEFAULT '',
+    changed_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_calendar_feeds_user_id ON calendar_feeds(user_id);
+CREATE UNIQUE INDEX idx_calendar_feeds_token_hash ON calendar_feeds(token_hash);